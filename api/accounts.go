@@ -0,0 +1,117 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+)
+
+func (s *Server) listAccounts(w http.ResponseWriter, r *http.Request, p params) error {
+	b, err := s.load(r, p)
+	if err != nil {
+		return err
+	}
+
+	views := []accountView{}
+	for _, a := range b.Accounts() {
+		views = append(views, newAccountView(a))
+	}
+
+	return writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) createAccount(w http.ResponseWriter, r *http.Request, p params) error {
+	var req struct {
+		Name    string          `json:"name"`
+		Balance decimal.Decimal `json:"balance"`
+		Date    string          `json:"date"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return invalid("name is required")
+	}
+	date, err := parseDate("date", req.Date)
+	if err != nil {
+		return err
+	}
+
+	var account *budgeting.Account
+	action := fmt.Sprintf("added the account %q", req.Name)
+	_, err = s.update(r, p, action, func(b *budgeting.Budget) error {
+		var err error
+		account, err = b.AddAccount(req.Name, req.Balance, date)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusCreated, newAccountView(account))
+}
+
+func (s *Server) getAccount(w http.ResponseWriter, r *http.Request, p params) error {
+	b, err := s.load(r, p)
+	if err != nil {
+		return err
+	}
+
+	a, err := b.Account(p["account"])
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, newAccountView(a))
+}
+
+func (s *Server) updateAccount(w http.ResponseWriter, r *http.Request, p params) error {
+	var req struct {
+		Closed *bool `json:"closed"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+
+	var account *budgeting.Account
+	action := fmt.Sprintf("updated the account %s", p["account"])
+	_, err := s.update(r, p, action, func(b *budgeting.Budget) error {
+		a, err := b.Account(p["account"])
+		if err != nil {
+			return err
+		}
+
+		if req.Closed != nil {
+			if *req.Closed {
+				a.Close()
+			} else {
+				a.Reopen()
+			}
+		}
+
+		account = a
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, newAccountView(account))
+}
+
+func (s *Server) listAccountTransactions(w http.ResponseWriter, r *http.Request, p params) error {
+	b, err := s.load(r, p)
+	if err != nil {
+		return err
+	}
+
+	a, err := b.Account(p["account"])
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, transactionViews(a.Transactions()))
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/hasyimibhar/budget-app/users"
+)
+
+func (s *Server) listBudgets(w http.ResponseWriter, r *http.Request, p params) error {
+	u := currentUser(r)
+	budgets, err := s.app.Budgets(u.ID)
+	if err != nil {
+		return err
+	}
+
+	t, limited := currentAPIToken(r)
+	views := []budgetView{}
+	for _, b := range budgets {
+		if limited && !t.AllowsBudget(b.ID()) {
+			continue
+		}
+		views = append(views, newBudgetView(b, u.Role(b.ID())))
+	}
+
+	return writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) createBudget(w http.ResponseWriter, r *http.Request, p params) error {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return invalid("name is required")
+	}
+
+	b, err := s.app.CreateBudget(currentUser(r).ID, req.Name)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusCreated, newBudgetView(b, users.RoleOwner))
+}
+
+func (s *Server) getBudget(w http.ResponseWriter, r *http.Request, p params) error {
+	b, role, err := s.app.Budget(currentUser(r).ID, p["budget"])
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, newBudgetView(b, role))
+}
+
+func (s *Server) deleteBudget(w http.ResponseWriter, r *http.Request, p params) error {
+	if err := s.app.DeleteBudget(currentUser(r).ID, p["budget"]); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/hasyimibhar/budget-app/users"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// createBudget creates a budget with a savings account and a category.
+func createBudget(c *client) (budget budgetView, savings accountView, food categoryView) {
+	t := c.t
+
+	if w := c.do(http.MethodPost, "/budgets", map[string]string{"name": "My Budget"}, &budget); w.Code != http.StatusCreated {
+		t.Fatalf("creating budget: %d %s", w.Code, w.Body)
+	}
+
+	path := "/budgets/" + budget.ID
+	if w := c.do(http.MethodPost, path+"/accounts", map[string]string{
+		"name":    "Savings",
+		"balance": "100.00",
+		"date":    "2018-01-01",
+	}, &savings); w.Code != http.StatusCreated {
+		t.Fatalf("creating account: %d %s", w.Code, w.Body)
+	}
+
+	if w := c.do(http.MethodPost, path+"/categories", map[string]string{"name": "Food", "group": "Everyday"}, &food); w.Code != http.StatusCreated {
+		t.Fatalf("creating category: %d %s", w.Code, w.Body)
+	}
+
+	return budget, savings, food
+}
+
+func TestServer_Budgets(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestClient(t)
+
+	budget, savings, food := createBudget(c)
+	assert.Equal("My Budget", budget.Name)
+	assert.NotEmpty(budget.TBBCategory)
+	assert.Equal("100.00", savings.Balance)
+	assert.Equal("Food", food.Name)
+	assert.Equal("Everyday", food.Group)
+
+	var budgets []budgetView
+	c.do(http.MethodGet, "/budgets", nil, &budgets)
+	assert.Len(budgets, 1)
+	assert.Equal(budget.ID, budgets[0].ID)
+	assert.True(budgets[0].Version > budget.Version)
+
+	path := "/budgets/" + budget.ID
+
+	var accounts []accountView
+	c.do(http.MethodGet, path+"/accounts", nil, &accounts)
+	assert.Equal([]accountView{savings}, accounts)
+
+	var categories []categoryView
+	c.do(http.MethodGet, path+"/categories", nil, &categories)
+	assert.Len(categories, 2)
+	assert.Equal(budget.TBBCategory, categories[0].ID)
+	assert.Equal(food, categories[1])
+
+	var category categoryView
+	assert.Equal(http.StatusOK, c.do(http.MethodGet, path+"/categories/"+food.ID, nil, &category).Code)
+	assert.Equal(food, category)
+	assert.Equal(http.StatusNotFound, c.do(http.MethodGet, path+"/categories/missing", nil, nil).Code)
+
+	assert.Equal(http.StatusNoContent, c.do(http.MethodDelete, path, nil, nil).Code)
+	assert.Equal(http.StatusNotFound, c.do(http.MethodGet, path, nil, nil).Code)
+	assert.Equal(http.StatusNotFound, c.do(http.MethodDelete, path, nil, nil).Code)
+}
+
+func TestServer_Accounts(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestClient(t)
+
+	budget, savings, _ := createBudget(c)
+	path := "/budgets/" + budget.ID + "/accounts/" + savings.ID
+
+	var e errorJSON
+	w := c.do(http.MethodPost, "/budgets/"+budget.ID+"/accounts", map[string]string{"name": "Wallet", "date": "yesterday"}, &e)
+	assert.Equal(http.StatusUnprocessableEntity, w.Code)
+	assert.Equal("date must be a date like 2006-01-02", e.Error.Message)
+
+	var account accountView
+	assert.Equal(http.StatusOK, c.do(http.MethodPatch, path, map[string]bool{"closed": true}, &account).Code)
+	assert.True(account.Closed)
+
+	w = c.do(http.MethodPost, "/budgets/"+budget.ID+"/transactions", map[string]string{
+		"account": savings.ID,
+		"date":    "2018-01-02",
+		"amount":  "-5.00",
+	}, &e)
+	assert.Equal(http.StatusConflict, w.Code)
+	assert.Equal(codeConflict, e.Error.Code)
+
+	c.do(http.MethodPatch, path, map[string]bool{"closed": false}, &account)
+	assert.False(account.Closed)
+
+	var transactions []transactionView
+	c.do(http.MethodGet, path+"/transactions", nil, &transactions)
+	assert.Len(transactions, 1)
+	assert.Equal("Starting balance", transactions[0].Description)
+	assert.Equal(budget.TBBCategory, transactions[0].Category)
+
+	assert.Equal(http.StatusNotFound, c.do(http.MethodGet, "/budgets/"+budget.ID+"/accounts/missing", nil, nil).Code)
+}
+
+func TestServer_UnreadableBudget(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "budget-app-api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	store, err := storage.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	us := users.NewService(users.NewMemoryStore())
+	us.HashCost = bcrypt.MinCost
+	c := login(t, NewServer(store, us), "alice@example.com")
+
+	budget, _, _ := createBudget(c)
+	file := filepath.Join(dir, budget.ID+".json")
+
+	for _, doc := range []string{
+		`{"schema_version":999,"budget":{}}`,
+		`{"budget":{}}`,
+		`{"schema_version":1,"budget":{"accounts":"broken"}}`,
+	} {
+		if err := ioutil.WriteFile(file, []byte(doc), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		var e errorJSON
+		w := c.do(http.MethodGet, "/budgets/"+budget.ID, nil, &e)
+		assert.Equal(http.StatusInternalServerError, w.Code, doc)
+		assert.Equal(codeStorage, e.Error.Code, doc)
+		assert.Contains(e.Error.Message, "budget cannot be loaded: ", doc)
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+func (s *Server) listCategories(w http.ResponseWriter, r *http.Request, p params) error {
+	b, err := s.load(r, p)
+	if err != nil {
+		return err
+	}
+
+	views := []categoryView{}
+	for _, c := range b.Categories() {
+		views = append(views, newCategoryView(c))
+	}
+
+	return writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) createCategory(w http.ResponseWriter, r *http.Request, p params) error {
+	var req struct {
+		Name  string `json:"name"`
+		Group string `json:"group"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return invalid("name is required")
+	}
+
+	var category *budgeting.Category
+	action := fmt.Sprintf("added the category %q", req.Name)
+	_, err := s.update(r, p, action, func(b *budgeting.Budget) error {
+		var err error
+		category, err = b.AddCategory(req.Name, budgeting.WithGroup(req.Group))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusCreated, newCategoryView(category))
+}
+
+func (s *Server) getCategory(w http.ResponseWriter, r *http.Request, p params) error {
+	b, err := s.load(r, p)
+	if err != nil {
+		return err
+	}
+
+	c, err := b.Category(p["category"])
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, newCategoryView(c))
+}
+
+// referencedCategory looks up a category given in a request body. Unlike a
+// category in the path, an unknown one makes the request invalid rather than
+// the resource missing.
+func referencedCategory(b *budgeting.Budget, field, id string) (*budgeting.Category, error) {
+	c, err := b.Category(id)
+	if err != nil {
+		return nil, invalid("%s: unknown category %q", field, id)
+	}
+	return c, nil
+}
+
+// referencedAccount is like referencedCategory, for accounts.
+func referencedAccount(b *budgeting.Budget, field, id string) (*budgeting.Account, error) {
+	a, err := b.Account(id)
+	if err != nil {
+		return nil, invalid("%s: unknown account %q", field, id)
+	}
+	return a, nil
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hasyimibhar/budget-app/app"
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/hasyimibhar/budget-app/users"
+)
+
+// Error codes reported in error responses.
+const (
+	codeBadRequest       = "bad_request"
+	codeNotFound         = "not_found"
+	codeUnauthorized     = "unauthorized"
+	codeForbidden        = "forbidden"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeConflict         = "conflict"
+	codeInvalid          = "invalid"
+	codeStorage          = "storage"
+	codeInternal         = "internal"
+)
+
+// Error is an error with the HTTP status and code it is reported with.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func errorf(status int, code string, format string, args ...interface{}) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// invalid reports a request which is well-formed but breaks a rule of the domain.
+func invalid(format string, args ...interface{}) *Error {
+	return errorf(http.StatusUnprocessableEntity, codeInvalid, format, args...)
+}
+
+// toError maps the errors of the domain, storage and app packages to API errors.
+func toError(err error) *Error {
+	switch err {
+	case storage.ErrNotFound,
+		budgeting.ErrAccountNotFound,
+		budgeting.ErrCategoryNotFound,
+		budgeting.ErrTransactionNotFound,
+		users.ErrUserNotFound,
+		users.ErrInvitationNotFound:
+		return errorf(http.StatusNotFound, codeNotFound, "%v", err)
+
+	case users.ErrInvalidCredentials,
+		users.ErrSessionNotFound,
+		users.ErrAPITokenNotFound:
+		return errorf(http.StatusUnauthorized, codeUnauthorized, "%v", err)
+
+	case app.ErrForbidden:
+		return errorf(http.StatusForbidden, codeForbidden, "%v", err)
+
+	case storage.ErrConcurrentModification,
+		budgeting.ErrDuplicateID,
+		budgeting.ErrAccountClosed,
+		users.ErrEmailTaken,
+		app.ErrLastOwner,
+		app.ErrAlreadyMember:
+		return errorf(http.StatusConflict, codeConflict, "%v", err)
+
+	case budgeting.ErrCannotAssignCategoryToTransfer,
+		budgeting.ErrCannotBudgetTBB,
+		budgeting.ErrForeignCategory,
+		budgeting.ErrForeignAccount,
+		users.ErrInvalidEmail,
+		users.ErrWeakPassword,
+		users.ErrInvalidScope,
+		app.ErrInvalidRole:
+		return invalid("%v", err)
+
+	case storage.ErrMissingSchemaVersion,
+		storage.ErrUnsupportedSchemaVersion:
+		return storageError(err)
+	}
+
+	switch e := err.(type) {
+	case *Error:
+		return e
+	case *storage.MigrationError:
+		return storageError(e)
+	}
+
+	log.Printf("api: %v", err)
+	return errorf(http.StatusInternalServerError, codeInternal, "internal error")
+}
+
+// storageError reports a stored budget which this version of the server
+// cannot read. It is logged, since it needs an operator to fix.
+func storageError(err error) *Error {
+	log.Printf("api: %v", err)
+	return errorf(http.StatusInternalServerError, codeStorage, "budget cannot be loaded: %v", err)
+}
+
+type errorJSON struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e := toError(err)
+
+	var body errorJSON
+	body.Error.Code = e.Code
+	body.Error.Message = e.Message
+
+	writeJSON(w, e.Status, body)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+)
+
+func parseMonth(s string) (budgeting.YearMonth, error) {
+	m, err := budgeting.ParseYearMonth(s)
+	if err != nil {
+		return budgeting.YearMonth{}, errorf(http.StatusNotFound, codeNotFound, "month must look like 2006-01")
+	}
+	return m, nil
+}
+
+func (s *Server) getMonth(w http.ResponseWriter, r *http.Request, p params) error {
+	month, err := parseMonth(p["month"])
+	if err != nil {
+		return err
+	}
+
+	b, err := s.load(r, p)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, newMonthView(b, month))
+}
+
+func (s *Server) setBudgeted(w http.ResponseWriter, r *http.Request, p params) error {
+	month, err := parseMonth(p["month"])
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		Budgeted *decimal.Decimal `json:"budgeted"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	if req.Budgeted == nil {
+		return invalid("budgeted is required")
+	}
+
+	action := fmt.Sprintf("budgeted %s for category %s in %s", req.Budgeted, p["category"], month)
+	b, err := s.update(r, p, action, func(b *budgeting.Budget) error {
+		c, err := b.Category(p["category"])
+		if err != nil {
+			return err
+		}
+
+		return b.SetBudgeted(month, c, *req.Budgeted)
+	})
+	if err != nil {
+		return err
+	}
+
+	c, err := b.Category(p["category"])
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, newMonthCategoryView(b, month, c))
+}
+
+func (s *Server) moveBudgeted(w http.ResponseWriter, r *http.Request, p params) error {
+	month, err := parseMonth(p["month"])
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		From   string           `json:"from"`
+		To     string           `json:"to"`
+		Amount *decimal.Decimal `json:"amount"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	if req.Amount == nil {
+		return invalid("amount is required")
+	}
+
+	action := fmt.Sprintf("moved %s from category %s to %s in %s", req.Amount, req.From, req.To, month)
+	b, err := s.update(r, p, action, func(b *budgeting.Budget) error {
+		from, err := referencedCategory(b, "from", req.From)
+		if err != nil {
+			return err
+		}
+		to, err := referencedCategory(b, "to", req.To)
+		if err != nil {
+			return err
+		}
+
+		return b.MoveBudgeted(month, from, to, *req.Amount)
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, newMonthView(b, month))
+}
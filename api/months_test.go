@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Months(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestClient(t)
+
+	budget, savings, food := createBudget(c)
+	path := "/budgets/" + budget.ID
+
+	var bills categoryView
+	c.do(http.MethodPost, path+"/categories", map[string]string{"name": "Bills"}, &bills)
+
+	c.do(http.MethodPost, path+"/transactions", map[string]string{
+		"account":  savings.ID,
+		"date":     "2018-01-02",
+		"amount":   "-5.00",
+		"category": food.ID,
+	}, nil)
+
+	var category monthCategoryView
+	w := c.do(http.MethodPut, path+"/months/2018-01/categories/"+food.ID, map[string]string{"budgeted": "30"}, &category)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal(monthCategoryView{
+		ID:         food.ID,
+		Name:       "Food",
+		Group:      "Everyday",
+		Budgeted:   "30.00",
+		Activities: "-5.00",
+		Available:  "25.00",
+	}, category)
+
+	var month monthView
+	w = c.do(http.MethodPost, path+"/months/2018-01/moves", map[string]string{
+		"from":   food.ID,
+		"to":     bills.ID,
+		"amount": "10",
+	}, &month)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("2018-01", month.Month)
+	assert.Equal("70.00", month.TBB)
+	assert.Len(month.Categories, 2)
+	assert.Equal("20.00", month.Categories[0].Budgeted)
+	assert.Equal("10.00", month.Categories[1].Budgeted)
+
+	c.do(http.MethodPost, path+"/months/2018-01/moves", map[string]string{
+		"from":   budget.TBBCategory,
+		"to":     bills.ID,
+		"amount": "5",
+	}, &month)
+	assert.Equal("65.00", month.TBB)
+
+	c.do(http.MethodGet, path+"/months/2018-02", nil, &month)
+	assert.Equal("65.00", month.TBB)
+	assert.Equal("15.00", month.Categories[0].Available)
+	assert.Equal("15.00", month.Categories[1].Available)
+}
+
+func TestServer_MonthErrors(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestClient(t)
+
+	budget, _, food := createBudget(c)
+	path := "/budgets/" + budget.ID + "/months"
+
+	var e errorJSON
+	w := c.do(http.MethodGet, path+"/January", nil, &e)
+	assert.Equal(http.StatusNotFound, w.Code)
+
+	w = c.do(http.MethodPut, path+"/2018-01/categories/"+budget.TBBCategory, map[string]string{"budgeted": "1"}, &e)
+	assert.Equal(http.StatusUnprocessableEntity, w.Code)
+	assert.Equal("cannot budget To Be Budgeted", e.Error.Message)
+
+	w = c.do(http.MethodPut, path+"/2018-01/categories/missing", map[string]string{"budgeted": "1"}, &e)
+	assert.Equal(http.StatusNotFound, w.Code)
+
+	w = c.do(http.MethodPost, path+"/2018-01/moves", map[string]string{"from": food.ID, "to": "missing", "amount": "1"}, &e)
+	assert.Equal(http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(`to: unknown category "missing"`, e.Error.Message)
+}
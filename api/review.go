@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+func (s *Server) listReview(w http.ResponseWriter, r *http.Request, p params) error {
+	b, err := s.load(r, p)
+	if err != nil {
+		return err
+	}
+
+	unapproved := []*budgeting.Transaction{}
+	for _, t := range b.Transactions() {
+		if !t.Approved() {
+			unapproved = append(unapproved, t)
+		}
+	}
+
+	return writeJSON(w, http.StatusOK, transactionViews(unapproved))
+}
+
+// reviewed is what became of the transactions of a review.
+type reviewed struct {
+	Approved []transactionView `json:"approved"`
+
+	// Added are the imported transactions added back to the accounts by
+	// rejecting their matches, which are still to be reviewed.
+	Added []transactionView `json:"added"`
+}
+
+// review approves transactions in bulk. Each can be given a new category,
+// or have its match rejected instead of accepted. Either all of them are
+// reviewed or none are.
+func (s *Server) review(w http.ResponseWriter, r *http.Request, p params) error {
+	var req struct {
+		Transactions []struct {
+			ID string `json:"id"`
+
+			// Category is the ID of the new category, or "" to remove it.
+			Category *string `json:"category"`
+
+			// Match is "accept", the default, or "reject".
+			Match string `json:"match"`
+		} `json:"transactions"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	if len(req.Transactions) == 0 {
+		return invalid("transactions are required")
+	}
+	for _, rt := range req.Transactions {
+		if rt.Match != "" && rt.Match != "accept" && rt.Match != "reject" {
+			return invalid("match must be accept or reject, not %q", rt.Match)
+		}
+	}
+
+	var approved, added []*budgeting.Transaction
+	action := fmt.Sprintf("reviewed %d transactions", len(req.Transactions))
+	_, err := s.update(r, p, action, func(b *budgeting.Budget) error {
+		approved, added = nil, nil
+
+		for _, rt := range req.Transactions {
+			t, err := b.Transaction(rt.ID)
+			if err != nil {
+				return err
+			}
+
+			if rt.Match == "reject" {
+				imported, err := t.RejectMatch()
+				if err == budgeting.ErrNotMatched {
+					return invalid("transaction %s isn't matched with an imported one", rt.ID)
+				}
+				if err != nil {
+					return err
+				}
+				added = append(added, imported)
+			}
+
+			if rt.Category != nil {
+				var category *budgeting.Category
+				if *rt.Category != "" {
+					if category, err = referencedCategory(b, "category", *rt.Category); err != nil {
+						return err
+					}
+				}
+				if err := t.SetCategory(category); err != nil {
+					return err
+				}
+			}
+
+			if err := t.Approve(); err != nil {
+				return err
+			}
+			approved = append(approved, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, reviewed{
+		Approved: transactionViews(approved),
+		Added:    transactionViews(added),
+	})
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Review(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestClient(t)
+
+	budget, savings, food := createBudget(c)
+	path := "/budgets/" + budget.ID
+
+	var lunch transactionView
+	c.do(http.MethodPost, path+"/transactions", map[string]string{
+		"account":     savings.ID,
+		"date":        "2018-01-02",
+		"amount":      "-5.00",
+		"description": "lunch",
+		"category":    food.ID,
+	}, &lunch)
+	assert.True(lunch.Approved)
+
+	// The API doesn't import statements, so the budget is changed the way
+	// importing one would.
+	var user userView
+	c.do(http.MethodGet, "/user", nil, &user)
+	var imported *budgeting.Transaction
+	_, err := c.s.app.Update(user.ID, budget.ID, "imported a statement", func(b *budgeting.Budget) error {
+		a, _ := b.Account(savings.ID)
+		imported, _ = a.AddTransaction(time.Date(2018, 1, 5, 0, 0, 0, 0, time.UTC), decimal.New(-20, 0), "SUPERMARKET", nil, nil,
+			budgeting.WithImportID("1"), budgeting.WithUnapproved())
+
+		t, _ := b.Transaction(lunch.ID)
+		return t.MatchImport(budgeting.Match{ImportID: "2", Date: time.Date(2018, 1, 3, 0, 0, 0, 0, time.UTC), Description: "CAFE"})
+	})
+	assert.Nil(err)
+
+	var queue []transactionView
+	w := c.do(http.MethodGet, path+"/review", nil, &queue)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Len(queue, 2)
+	assert.Equal(lunch.ID, queue[0].ID)
+	assert.Equal(&matchView{ImportID: "2", Date: "2018-01-03", Description: "CAFE"}, queue[0].Match)
+	assert.False(queue[1].Approved)
+
+	w = c.do(http.MethodPost, path+"/review", map[string]interface{}{
+		"transactions": []map[string]string{{"id": imported.ID(), "match": "reject"}},
+	}, nil)
+	assert.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	var result reviewed
+	w = c.do(http.MethodPost, path+"/review", map[string]interface{}{
+		"transactions": []map[string]string{
+			{"id": imported.ID(), "category": food.ID},
+			{"id": lunch.ID, "match": "reject"},
+		},
+	}, &result)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Len(result.Approved, 2)
+	assert.Equal(food.ID, result.Approved[0].Category)
+	assert.Nil(result.Approved[1].Match)
+	assert.Equal("", result.Approved[1].ImportID)
+	assert.Equal("CAFE", result.Added[0].Description)
+	assert.Equal("2", result.Added[0].ImportID)
+
+	c.do(http.MethodGet, path+"/review", nil, &queue)
+	assert.Len(queue, 1)
+	assert.Equal(result.Added[0].ID, queue[0].ID)
+}
@@ -0,0 +1,449 @@
+// Package api exposes budgets over a REST/JSON HTTP API.
+//
+// Users register and log in with:
+//
+//	POST   /users
+//	POST   /session
+//	DELETE /session
+//	GET    /user
+//
+// Logging in returns a token. Every other request must carry it in an
+// "Authorization: Bearer <token>" header, and only sees the budgets the
+// user is a member of. Instead of logging in, scripts can use API tokens,
+// which the user manages with:
+//
+//	GET    /user/tokens
+//	POST   /user/tokens
+//	DELETE /user/tokens/{token}
+//
+// API tokens can only GET with the read scope, change transactions with the
+// transactions:write scope and change accounts, categories and months with
+// the budget:write scope. Everything else needs logging in, and tokens can
+// be limited to some of the user's budgets.
+//
+// Every resource lives under a budget:
+//
+//	GET    /budgets
+//	POST   /budgets
+//	GET    /budgets/{budget}
+//	DELETE /budgets/{budget}
+//	GET    /budgets/{budget}/members
+//	PUT    /budgets/{budget}/members/{user}
+//	DELETE /budgets/{budget}/members/{user}
+//	GET    /budgets/{budget}/invitations
+//	POST   /budgets/{budget}/invitations
+//	DELETE /budgets/{budget}/invitations/{invitation}
+//	GET    /budgets/{budget}/audit
+//	GET    /budgets/{budget}/accounts
+//	POST   /budgets/{budget}/accounts
+//	GET    /budgets/{budget}/accounts/{account}
+//	PATCH  /budgets/{budget}/accounts/{account}
+//	GET    /budgets/{budget}/accounts/{account}/transactions
+//	GET    /budgets/{budget}/categories
+//	POST   /budgets/{budget}/categories
+//	GET    /budgets/{budget}/categories/{category}
+//	GET    /budgets/{budget}/transactions
+//	POST   /budgets/{budget}/transactions
+//	GET    /budgets/{budget}/transactions/{transaction}
+//	PATCH  /budgets/{budget}/transactions/{transaction}
+//	DELETE /budgets/{budget}/transactions/{transaction}
+//	GET    /budgets/{budget}/review
+//	POST   /budgets/{budget}/review
+//	GET    /budgets/{budget}/months/{month}
+//	PUT    /budgets/{budget}/months/{month}/categories/{category}
+//	POST   /budgets/{budget}/months/{month}/moves
+//
+// Imported transactions are unapproved until they are reviewed. The review
+// queue lists them, and posting to it approves them in bulk, changing
+// their categories or rejecting the transactions entered by hand which
+// they were matched with. Unapproved transactions count towards balances
+// and activities like any other.
+//
+// Members are owners, editors or viewers of a budget. Viewers can only read
+// it, editors can also change it, and owners can also share and delete it.
+// Invitations are accepted by the invited user with:
+//
+//	POST   /invitations/accept
+//
+// Amounts are decimal strings, dates are "2006-01-02" and months are
+// "2006-01". Errors are reported as {"error":{"code":...,"message":...}}.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hasyimibhar/budget-app/app"
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/hasyimibhar/budget-app/users"
+)
+
+// maxBodySize limits the size of request bodies.
+const maxBodySize = 1 << 20
+
+// params holds the values of the {placeholders} of a matched route.
+type params map[string]string
+
+type handlerFunc func(w http.ResponseWriter, r *http.Request, p params) error
+
+type route struct {
+	segments []string
+	handlers map[string]handlerFunc
+
+	// public routes can be used without logging in.
+	public bool
+
+	// writeScope is the scope an API token needs for methods other than
+	// GET, which need users.ScopeRead. Without one, only a session will do.
+	writeScope users.Scope
+
+	// sessionOnly routes can't be used with API tokens at all.
+	sessionOnly bool
+}
+
+type contextKey int
+
+const (
+	userKey contextKey = iota
+	apiTokenKey
+)
+
+// Server serves the API from a store. It implements http.Handler.
+type Server struct {
+	app    *app.Service
+	users  *users.Service
+	routes []route
+}
+
+// NewServer creates a server for the budgets in the store, which are shared
+// among the users of the user service.
+func NewServer(store storage.Store, userService *users.Service) *Server {
+	s := &Server{
+		app:   app.NewService(store, userService),
+		users: userService,
+	}
+
+	s.handlePublic("users", map[string]handlerFunc{
+		http.MethodPost: s.register,
+	})
+	s.handlePublic("session", map[string]handlerFunc{
+		http.MethodPost: s.login,
+	})
+	s.handle("session", map[string]handlerFunc{
+		http.MethodDelete: s.logout,
+	})
+	s.handle("user", map[string]handlerFunc{
+		http.MethodGet: s.getUser,
+	})
+	s.handleSession("user/tokens", map[string]handlerFunc{
+		http.MethodGet:  s.listAPITokens,
+		http.MethodPost: s.createAPIToken,
+	})
+	s.handleSession("user/tokens/{token}", map[string]handlerFunc{
+		http.MethodDelete: s.revokeAPIToken,
+	})
+
+	s.handle("budgets", map[string]handlerFunc{
+		http.MethodGet:  s.listBudgets,
+		http.MethodPost: s.createBudget,
+	})
+	s.handle("budgets/{budget}", map[string]handlerFunc{
+		http.MethodGet:    s.getBudget,
+		http.MethodDelete: s.deleteBudget,
+	})
+	s.handle("budgets/{budget}/members", map[string]handlerFunc{
+		http.MethodGet: s.listMembers,
+	})
+	s.handle("budgets/{budget}/members/{user}", map[string]handlerFunc{
+		http.MethodPut:    s.setMember,
+		http.MethodDelete: s.removeMember,
+	})
+	s.handle("budgets/{budget}/invitations", map[string]handlerFunc{
+		http.MethodGet:  s.listInvitations,
+		http.MethodPost: s.createInvitation,
+	})
+	s.handle("budgets/{budget}/invitations/{invitation}", map[string]handlerFunc{
+		http.MethodDelete: s.deleteInvitation,
+	})
+	s.handle("invitations/accept", map[string]handlerFunc{
+		http.MethodPost: s.acceptInvitation,
+	})
+	s.handle("budgets/{budget}/audit", map[string]handlerFunc{
+		http.MethodGet: s.listAudit,
+	})
+
+	s.handleScoped("budgets/{budget}/accounts", users.ScopeBudgetWrite, map[string]handlerFunc{
+		http.MethodGet:  s.listAccounts,
+		http.MethodPost: s.createAccount,
+	})
+	s.handleScoped("budgets/{budget}/accounts/{account}", users.ScopeBudgetWrite, map[string]handlerFunc{
+		http.MethodGet:   s.getAccount,
+		http.MethodPatch: s.updateAccount,
+	})
+	s.handle("budgets/{budget}/accounts/{account}/transactions", map[string]handlerFunc{
+		http.MethodGet: s.listAccountTransactions,
+	})
+
+	s.handleScoped("budgets/{budget}/categories", users.ScopeBudgetWrite, map[string]handlerFunc{
+		http.MethodGet:  s.listCategories,
+		http.MethodPost: s.createCategory,
+	})
+	s.handle("budgets/{budget}/categories/{category}", map[string]handlerFunc{
+		http.MethodGet: s.getCategory,
+	})
+
+	s.handleScoped("budgets/{budget}/transactions", users.ScopeTransactionsWrite, map[string]handlerFunc{
+		http.MethodGet:  s.listTransactions,
+		http.MethodPost: s.createTransaction,
+	})
+	s.handleScoped("budgets/{budget}/transactions/{transaction}", users.ScopeTransactionsWrite, map[string]handlerFunc{
+		http.MethodGet:    s.getTransaction,
+		http.MethodPatch:  s.updateTransaction,
+		http.MethodDelete: s.deleteTransaction,
+	})
+	s.handleScoped("budgets/{budget}/review", users.ScopeTransactionsWrite, map[string]handlerFunc{
+		http.MethodGet:  s.listReview,
+		http.MethodPost: s.review,
+	})
+
+	s.handle("budgets/{budget}/months/{month}", map[string]handlerFunc{
+		http.MethodGet: s.getMonth,
+	})
+	s.handleScoped("budgets/{budget}/months/{month}/categories/{category}", users.ScopeBudgetWrite, map[string]handlerFunc{
+		http.MethodPut: s.setBudgeted,
+	})
+	s.handleScoped("budgets/{budget}/months/{month}/moves", users.ScopeBudgetWrite, map[string]handlerFunc{
+		http.MethodPost: s.moveBudgeted,
+	})
+
+	return s
+}
+
+// handle adds a route which requires logging in. API tokens with the read
+// scope may GET it, but anything else needs a session.
+func (s *Server) handle(pattern string, handlers map[string]handlerFunc) {
+	s.routes = append(s.routes, route{
+		segments: strings.Split(pattern, "/"),
+		handlers: handlers,
+	})
+}
+
+// handleScoped is like handle, but API tokens with writeScope may also use
+// the methods other than GET.
+func (s *Server) handleScoped(pattern string, writeScope users.Scope, handlers map[string]handlerFunc) {
+	s.routes = append(s.routes, route{
+		segments:   strings.Split(pattern, "/"),
+		handlers:   handlers,
+		writeScope: writeScope,
+	})
+}
+
+// handleSession adds a route which can only be used after logging in with
+// a password, and not with API tokens.
+func (s *Server) handleSession(pattern string, handlers map[string]handlerFunc) {
+	s.routes = append(s.routes, route{
+		segments:    strings.Split(pattern, "/"),
+		handlers:    handlers,
+		sessionOnly: true,
+	})
+}
+
+// handlePublic adds a route which can be used without logging in.
+func (s *Server) handlePublic(pattern string, handlers map[string]handlerFunc) {
+	s.routes = append(s.routes, route{
+		segments: strings.Split(pattern, "/"),
+		handlers: handlers,
+		public:   true,
+	})
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	// Several routes may share a path, public for some methods only.
+	allowed := map[string]bool{}
+	for _, rt := range s.routes {
+		p, ok := rt.match(segments)
+		if !ok {
+			continue
+		}
+
+		h, ok := rt.handlers[r.Method]
+		if !ok {
+			for m := range rt.handlers {
+				allowed[m] = true
+			}
+			continue
+		}
+
+		if !rt.public {
+			var err error
+			if r, err = s.authenticate(r); err != nil {
+				if e := toError(err); e.Status == http.StatusUnauthorized {
+					w.Header().Set("WWW-Authenticate", "Bearer")
+				}
+				writeError(w, err)
+				return
+			}
+			if err := checkScope(r, rt, p); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+		if err := h(w, r, p); err != nil {
+			writeError(w, err)
+		}
+		return
+	}
+
+	if len(allowed) > 0 {
+		methods := []string{}
+		for m := range allowed {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		writeError(w, errorf(http.StatusMethodNotAllowed, codeMethodNotAllowed, "method %s not allowed", r.Method))
+		return
+	}
+
+	writeError(w, errorf(http.StatusNotFound, codeNotFound, "no such resource %s", r.URL.Path))
+}
+
+// authenticate resolves the session or API token of the request to its
+// user. What the user may do with a budget is up to the app service.
+func (s *Server) authenticate(r *http.Request) (*http.Request, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, errorf(http.StatusUnauthorized, codeUnauthorized, "missing bearer token")
+	}
+	token := strings.TrimPrefix(header, "Bearer ")
+
+	if strings.HasPrefix(token, users.APITokenPrefix) {
+		u, t, err := s.users.AuthenticateAPIToken(token)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx := context.WithValue(r.Context(), userKey, u)
+		return r.WithContext(context.WithValue(ctx, apiTokenKey, t)), nil
+	}
+
+	u, err := s.users.Authenticate(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), userKey, u)), nil
+}
+
+// checkScope checks that the API token the request was made with, if any,
+// allows using the route with the budget in the path. Budgets the token is
+// not for are reported as missing, like those of other users.
+func checkScope(r *http.Request, rt route, p params) error {
+	t, ok := currentAPIToken(r)
+	if !ok {
+		return nil
+	}
+
+	if rt.sessionOnly {
+		return errorf(http.StatusForbidden, codeForbidden, "API tokens can't be used here, log in instead")
+	}
+
+	scope := rt.writeScope
+	if r.Method == http.MethodGet {
+		scope = users.ScopeRead
+	}
+	if scope == "" {
+		return errorf(http.StatusForbidden, codeForbidden, "API tokens can't %s here, log in instead", r.Method)
+	}
+	if !t.HasScope(scope) {
+		return errorf(http.StatusForbidden, codeForbidden, "API token lacks the %s scope", scope)
+	}
+
+	if id, ok := p["budget"]; ok && !t.AllowsBudget(id) {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// currentUser returns the user authenticated to make the request.
+func currentUser(r *http.Request) users.User {
+	return r.Context().Value(userKey).(users.User)
+}
+
+// load loads the budget in the path on behalf of the current user.
+func (s *Server) load(r *http.Request, p params) (*budgeting.Budget, error) {
+	b, _, err := s.app.Budget(currentUser(r).ID, p["budget"])
+	return b, err
+}
+
+// update changes the budget in the path on behalf of the current user, like
+// storage.Update. action describes the change for the audit log.
+func (s *Server) update(r *http.Request, p params, action string, fn func(b *budgeting.Budget) error) (*budgeting.Budget, error) {
+	return s.app.Update(currentUser(r).ID, p["budget"], action, fn)
+}
+
+// currentAPIToken returns the API token the request was made with, if it
+// wasn't made with a session.
+func currentAPIToken(r *http.Request) (users.APIToken, bool) {
+	t, ok := r.Context().Value(apiTokenKey).(users.APIToken)
+	return t, ok
+}
+
+// bearerToken returns the session token the request was made with.
+func bearerToken(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+func (rt route) match(segments []string) (params, bool) {
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+
+	p := params{}
+	for i, seg := range rt.segments {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if segments[i] == "" {
+				return nil, false
+			}
+			p[seg[1:len(seg)-1]] = segments[i]
+			continue
+		}
+
+		if seg != segments[i] {
+			return nil, false
+		}
+	}
+
+	return p, true
+}
+
+// readJSON decodes the request body into v, rejecting unknown fields.
+func readJSON(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errorf(http.StatusBadRequest, codeBadRequest, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		// The status line is already out, so all we can do is log it.
+		log.Printf("api: writing response: %v", err)
+	}
+	return nil
+}
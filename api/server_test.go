@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/hasyimibhar/budget-app/users"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// client makes requests to a test server as a logged in user.
+type client struct {
+	t     *testing.T
+	s     *Server
+	token string
+}
+
+func newTestServer() *Server {
+	us := users.NewService(users.NewMemoryStore())
+	us.HashCost = bcrypt.MinCost
+	return NewServer(storage.NewMemoryStore(), us)
+}
+
+// newTestClient creates a server with a single user and logs in as that user.
+func newTestClient(t *testing.T) *client {
+	return login(t, newTestServer(), "alice@example.com")
+}
+
+// login registers a user on the server and logs in as that user.
+func login(t *testing.T, s *Server, email string) *client {
+	if _, err := s.users.Register(email, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err := s.users.Login(email, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &client{t: t, s: s, token: token}
+}
+
+// do sends a request with body encoded as JSON (unless it is a string, which
+// is sent as is) and decodes the response into out, if given.
+func (c *client) do(method, path string, body interface{}, out interface{}) *httptest.ResponseRecorder {
+	var data []byte
+	switch b := body.(type) {
+	case nil:
+	case string:
+		data = []byte(b)
+	default:
+		var err error
+		if data, err = json.Marshal(b); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+
+	r := httptest.NewRequest(method, path, bytes.NewReader(data))
+	if c.token != "" {
+		r.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	w := httptest.NewRecorder()
+	c.s.ServeHTTP(w, r)
+
+	if out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			c.t.Fatalf("%s %s: decoding %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w
+}
+
+func TestServer_Routing(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestClient(t)
+
+	var e errorJSON
+	w := c.do(http.MethodGet, "/nothing/here", nil, &e)
+	assert.Equal(http.StatusNotFound, w.Code)
+	assert.Equal("application/json", w.Header().Get("Content-Type"))
+	assert.Equal(codeNotFound, e.Error.Code)
+
+	w = c.do(http.MethodPut, "/budgets", nil, &e)
+	assert.Equal(http.StatusMethodNotAllowed, w.Code)
+	assert.Equal("GET, POST", w.Header().Get("Allow"))
+	assert.Equal(codeMethodNotAllowed, e.Error.Code)
+
+	w = c.do(http.MethodGet, "/budgets//accounts", nil, &e)
+	assert.Equal(http.StatusNotFound, w.Code)
+
+	w = c.do(http.MethodGet, "/budgets/", nil, nil)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("[]\n", w.Body.String())
+}
+
+func TestServer_Errors(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestClient(t)
+
+	var e errorJSON
+	w := c.do(http.MethodGet, "/budgets/missing", nil, &e)
+	assert.Equal(http.StatusNotFound, w.Code)
+	assert.Equal(codeNotFound, e.Error.Code)
+	assert.Equal(storage.ErrNotFound.Error(), e.Error.Message)
+
+	w = c.do(http.MethodPost, "/budgets", `{"name":`, &e)
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Equal(codeBadRequest, e.Error.Code)
+
+	w = c.do(http.MethodPost, "/budgets", `{"name":"x","currency":"MYR"}`, &e)
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Contains(e.Error.Message, "currency")
+
+	w = c.do(http.MethodPost, "/budgets", `{"name":" "}`, &e)
+	assert.Equal(http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(codeInvalid, e.Error.Code)
+	assert.Equal("name is required", e.Error.Message)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/users"
+)
+
+type memberView struct {
+	ID    string     `json:"id"`
+	Email string     `json:"email"`
+	Role  users.Role `json:"role"`
+}
+
+type invitationView struct {
+	ID      string     `json:"id"`
+	Email   string     `json:"email"`
+	Role    users.Role `json:"role"`
+	Expires time.Time  `json:"expires"`
+
+	// Token is only returned when the invitation is created.
+	Token string `json:"token,omitempty"`
+}
+
+func newInvitationView(inv users.Invitation) invitationView {
+	return invitationView{
+		ID:      inv.ID,
+		Email:   inv.Email,
+		Role:    inv.Role,
+		Expires: inv.Expires,
+	}
+}
+
+type auditEntryView struct {
+	Time   time.Time `json:"time"`
+	User   string    `json:"user"`
+	Action string    `json:"action"`
+}
+
+func (s *Server) listMembers(w http.ResponseWriter, r *http.Request, p params) error {
+	members, err := s.app.Members(currentUser(r).ID, p["budget"])
+	if err != nil {
+		return err
+	}
+
+	views := []memberView{}
+	for _, m := range members {
+		views = append(views, memberView{
+			ID:    m.ID,
+			Email: m.Email,
+			Role:  m.Role(p["budget"]),
+		})
+	}
+
+	return writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) setMember(w http.ResponseWriter, r *http.Request, p params) error {
+	var req struct {
+		Role users.Role `json:"role"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+
+	if err := s.app.SetRole(currentUser(r).ID, p["budget"], p["user"], req.Role); err != nil {
+		return err
+	}
+
+	return s.listMembers(w, r, p)
+}
+
+func (s *Server) removeMember(w http.ResponseWriter, r *http.Request, p params) error {
+	if err := s.app.Revoke(currentUser(r).ID, p["budget"], p["user"]); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) listInvitations(w http.ResponseWriter, r *http.Request, p params) error {
+	invitations, err := s.app.Invitations(currentUser(r).ID, p["budget"])
+	if err != nil {
+		return err
+	}
+
+	views := []invitationView{}
+	for _, inv := range invitations {
+		views = append(views, newInvitationView(inv))
+	}
+
+	return writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) createInvitation(w http.ResponseWriter, r *http.Request, p params) error {
+	var req struct {
+		Email string     `json:"email"`
+		Role  users.Role `json:"role"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+
+	token, inv, err := s.app.Invite(currentUser(r).ID, p["budget"], req.Email, req.Role)
+	if err != nil {
+		return err
+	}
+
+	view := newInvitationView(inv)
+	view.Token = token
+	return writeJSON(w, http.StatusCreated, view)
+}
+
+func (s *Server) deleteInvitation(w http.ResponseWriter, r *http.Request, p params) error {
+	if err := s.app.CancelInvitation(currentUser(r).ID, p["budget"], p["invitation"]); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) acceptInvitation(w http.ResponseWriter, r *http.Request, p params) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+
+	u := currentUser(r)
+	id, err := s.app.AcceptInvitation(u.ID, req.Token)
+	if err != nil {
+		return err
+	}
+
+	b, role, err := s.app.Budget(u.ID, id)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, newBudgetView(b, role))
+}
+
+func (s *Server) listAudit(w http.ResponseWriter, r *http.Request, p params) error {
+	entries, err := s.app.Audit(currentUser(r).ID, p["budget"])
+	if err != nil {
+		return err
+	}
+
+	emails := map[string]string{}
+	views := []auditEntryView{}
+	for _, e := range entries {
+		if _, ok := emails[e.UserID]; !ok {
+			// Users are never deleted, but an entry is no reason to fail.
+			if u, err := s.users.Store().User(e.UserID); err == nil {
+				emails[e.UserID] = u.Email
+			}
+		}
+
+		views = append(views, auditEntryView{
+			Time:   e.Time,
+			User:   emails[e.UserID],
+			Action: e.Action,
+		})
+	}
+
+	return writeJSON(w, http.StatusOK, views)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Sharing(t *testing.T) {
+	assert := assert.New(t)
+
+	alice := newTestClient(t)
+	bob := login(t, alice.s, "bob@example.com")
+
+	budget, _, _ := createBudget(alice)
+	assert.Equal(users.RoleOwner, budget.Role)
+	path := "/budgets/" + budget.ID
+
+	var e errorJSON
+	w := alice.do(http.MethodPost, path+"/invitations", map[string]string{"email": "bob@example.com", "role": "admin"}, &e)
+	assert.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	var inv invitationView
+	w = alice.do(http.MethodPost, path+"/invitations", map[string]string{"email": "bob@example.com", "role": "viewer"}, &inv)
+	assert.Equal(http.StatusCreated, w.Code)
+	assert.NotEmpty(inv.Token)
+
+	var invitations []invitationView
+	alice.do(http.MethodGet, path+"/invitations", nil, &invitations)
+	assert.Len(invitations, 1)
+	assert.Empty(invitations[0].Token)
+
+	var shared budgetView
+	w = bob.do(http.MethodPost, "/invitations/accept", map[string]string{"token": inv.Token}, &shared)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal(budget.ID, shared.ID)
+	assert.Equal(users.RoleViewer, shared.Role)
+
+	// Viewers can read, but not change.
+	assert.Equal(http.StatusOK, bob.do(http.MethodGet, path+"/months/2018-01", nil, nil).Code)
+	w = bob.do(http.MethodPost, path+"/categories", map[string]string{"name": "Rent"}, &e)
+	assert.Equal(http.StatusForbidden, w.Code)
+	assert.Equal(codeForbidden, e.Error.Code)
+
+	var members []memberView
+	alice.do(http.MethodGet, path+"/members", nil, &members)
+	assert.Len(members, 2)
+	assert.Equal("bob@example.com", members[1].Email)
+	bobID := members[1].ID
+
+	w = alice.do(http.MethodPut, path+"/members/"+bobID, map[string]string{"role": "editor"}, &members)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal(users.RoleEditor, members[1].Role)
+	assert.Equal(http.StatusCreated, bob.do(http.MethodPost, path+"/categories", map[string]string{"name": "Rent"}, nil).Code)
+
+	// Editors can't share or delete.
+	assert.Equal(http.StatusForbidden, bob.do(http.MethodGet, path+"/invitations", nil, nil).Code)
+	assert.Equal(http.StatusForbidden, bob.do(http.MethodDelete, path, nil, nil).Code)
+
+	w = alice.do(http.MethodDelete, path+"/members/"+members[0].ID, nil, &e)
+	assert.Equal(http.StatusConflict, w.Code)
+	assert.Equal("a budget must keep at least one owner", e.Error.Message)
+
+	var audit []auditEntryView
+	alice.do(http.MethodGet, path+"/audit", nil, &audit)
+	assert.Equal("bob@example.com", audit[len(audit)-1].User)
+	assert.Equal(`added the category "Rent"`, audit[len(audit)-1].Action)
+
+	assert.Equal(http.StatusNoContent, bob.do(http.MethodDelete, path+"/members/"+bobID, nil, nil).Code)
+	assert.Equal(http.StatusNotFound, bob.do(http.MethodGet, path, nil, nil).Code)
+}
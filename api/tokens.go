@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/users"
+)
+
+type apiTokenView struct {
+	ID       string        `json:"id"`
+	Name     string        `json:"name"`
+	Scopes   []users.Scope `json:"scopes"`
+	Budgets  []string      `json:"budgets"`
+	Created  time.Time     `json:"created"`
+	LastUsed *time.Time    `json:"last_used"`
+
+	// Token is only returned when the token is created.
+	Token string `json:"token,omitempty"`
+}
+
+func newAPITokenView(t users.APIToken) apiTokenView {
+	view := apiTokenView{
+		ID:      t.ID,
+		Name:    t.Name,
+		Scopes:  t.Scopes,
+		Budgets: t.Budgets,
+		Created: t.Created,
+	}
+	if !t.LastUsed.IsZero() {
+		view.LastUsed = &t.LastUsed
+	}
+	return view
+}
+
+func (s *Server) listAPITokens(w http.ResponseWriter, r *http.Request, p params) error {
+	tokens, err := s.users.APITokens(currentUser(r).ID)
+	if err != nil {
+		return err
+	}
+
+	views := []apiTokenView{}
+	for _, t := range tokens {
+		views = append(views, newAPITokenView(t))
+	}
+
+	return writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) createAPIToken(w http.ResponseWriter, r *http.Request, p params) error {
+	var req struct {
+		Name    string        `json:"name"`
+		Scopes  []users.Scope `json:"scopes"`
+		Budgets []string      `json:"budgets"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+
+	u := currentUser(r)
+	for _, id := range req.Budgets {
+		if _, _, err := s.app.Budget(u.ID, id); err != nil {
+			return invalid("budgets: unknown budget %q", id)
+		}
+	}
+
+	token, t, err := s.users.CreateAPIToken(u.ID, req.Name, req.Scopes, req.Budgets)
+	if err != nil {
+		return err
+	}
+
+	view := newAPITokenView(t)
+	view.Token = token
+	return writeJSON(w, http.StatusCreated, view)
+}
+
+func (s *Server) revokeAPIToken(w http.ResponseWriter, r *http.Request, p params) error {
+	err := s.users.RevokeAPIToken(currentUser(r).ID, p["token"])
+	if err == users.ErrAPITokenNotFound {
+		// Not a failure to authenticate, unlike elsewhere.
+		return errorf(http.StatusNotFound, codeNotFound, "%v", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_APITokens(t *testing.T) {
+	assert := assert.New(t)
+	alice := newTestClient(t)
+
+	budget, savings, _ := createBudget(alice)
+	other, _, _ := createBudget(alice)
+	path := "/budgets/" + budget.ID
+
+	var e errorJSON
+	w := alice.do(http.MethodPost, "/user/tokens", map[string]interface{}{"name": "Home", "scopes": []string{"admin"}}, &e)
+	assert.Equal(http.StatusUnprocessableEntity, w.Code)
+	w = alice.do(http.MethodPost, "/user/tokens", map[string]interface{}{"name": "Home", "scopes": []string{"read"}, "budgets": []string{"missing"}}, &e)
+	assert.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	var created apiTokenView
+	w = alice.do(http.MethodPost, "/user/tokens", map[string]interface{}{
+		"name":    "Home",
+		"scopes":  []string{"read", "transactions:write"},
+		"budgets": []string{budget.ID},
+	}, &created)
+	assert.Equal(http.StatusCreated, w.Code)
+	assert.NotEmpty(created.Token)
+	assert.Nil(created.LastUsed)
+
+	script := &client{t: t, s: alice.s, token: created.Token}
+
+	var budgets []budgetView
+	assert.Equal(http.StatusOK, script.do(http.MethodGet, "/budgets", nil, &budgets).Code)
+	assert.Len(budgets, 1)
+	assert.Equal(budget.ID, budgets[0].ID)
+	assert.Equal(http.StatusNotFound, script.do(http.MethodGet, "/budgets/"+other.ID, nil, nil).Code)
+
+	w = script.do(http.MethodPost, path+"/transactions", map[string]string{
+		"account": savings.ID,
+		"date":    "2018-01-02",
+		"amount":  "-5.00",
+	}, nil)
+	assert.Equal(http.StatusCreated, w.Code)
+
+	w = script.do(http.MethodPost, path+"/categories", map[string]string{"name": "Rent"}, &e)
+	assert.Equal(http.StatusForbidden, w.Code)
+	assert.Equal("API token lacks the budget:write scope", e.Error.Message)
+	assert.Equal(http.StatusForbidden, script.do(http.MethodDelete, path, nil, nil).Code)
+	assert.Equal(http.StatusForbidden, script.do(http.MethodGet, "/user/tokens", nil, nil).Code)
+	assert.Equal(http.StatusForbidden, script.do(http.MethodPost, "/budgets", map[string]string{"name": "Sneaky"}, nil).Code)
+
+	var tokens []apiTokenView
+	alice.do(http.MethodGet, "/user/tokens", nil, &tokens)
+	assert.Len(tokens, 1)
+	assert.Empty(tokens[0].Token)
+	assert.NotNil(tokens[0].LastUsed)
+
+	assert.Equal(http.StatusNotFound, alice.do(http.MethodDelete, "/user/tokens/missing", nil, nil).Code)
+	assert.Equal(http.StatusNoContent, alice.do(http.MethodDelete, "/user/tokens/"+created.ID, nil, nil).Code)
+	assert.Equal(http.StatusUnauthorized, script.do(http.MethodGet, "/budgets", nil, nil).Code)
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+)
+
+func transactionViews(transactions []*budgeting.Transaction) []transactionView {
+	views := []transactionView{}
+	for _, t := range transactions {
+		views = append(views, newTransactionView(t))
+	}
+	return views
+}
+
+func (s *Server) listTransactions(w http.ResponseWriter, r *http.Request, p params) error {
+	b, err := s.load(r, p)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, transactionViews(b.Transactions()))
+}
+
+func (s *Server) createTransaction(w http.ResponseWriter, r *http.Request, p params) error {
+	var req struct {
+		Account         string           `json:"account"`
+		Date            string           `json:"date"`
+		Amount          *decimal.Decimal `json:"amount"`
+		Description     string           `json:"description"`
+		Category        string           `json:"category"`
+		TransferAccount string           `json:"transfer_account"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	if req.Amount == nil {
+		return invalid("amount is required")
+	}
+	date, err := parseDate("date", req.Date)
+	if err != nil {
+		return err
+	}
+
+	var transaction *budgeting.Transaction
+	action := fmt.Sprintf("added a transaction of %s", req.Amount)
+	_, err = s.update(r, p, action, func(b *budgeting.Budget) error {
+		a, err := referencedAccount(b, "account", req.Account)
+		if err != nil {
+			return err
+		}
+
+		var category *budgeting.Category
+		if req.Category != "" {
+			if category, err = referencedCategory(b, "category", req.Category); err != nil {
+				return err
+			}
+		}
+
+		var rel *budgeting.Account
+		if req.TransferAccount != "" {
+			if rel, err = referencedAccount(b, "transfer_account", req.TransferAccount); err != nil {
+				return err
+			}
+		}
+
+		transaction, err = a.AddTransaction(date, *req.Amount, req.Description, category, rel)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusCreated, newTransactionView(transaction))
+}
+
+func (s *Server) getTransaction(w http.ResponseWriter, r *http.Request, p params) error {
+	b, err := s.load(r, p)
+	if err != nil {
+		return err
+	}
+
+	t, err := b.Transaction(p["transaction"])
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, newTransactionView(t))
+}
+
+func (s *Server) updateTransaction(w http.ResponseWriter, r *http.Request, p params) error {
+	var req struct {
+		Date        *string          `json:"date"`
+		Amount      *decimal.Decimal `json:"amount"`
+		Description *string          `json:"description"`
+
+		// Category is the ID of the new category, or "" to remove it.
+		Category *string `json:"category"`
+
+		Cleared *bool `json:"cleared"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+
+	var transaction *budgeting.Transaction
+	action := fmt.Sprintf("updated the transaction %s", p["transaction"])
+	_, err := s.update(r, p, action, func(b *budgeting.Budget) error {
+		t, err := b.Transaction(p["transaction"])
+		if err != nil {
+			return err
+		}
+
+		if req.Date != nil {
+			date, err := parseDate("date", *req.Date)
+			if err != nil {
+				return err
+			}
+			if err := t.SetDate(date); err != nil {
+				return err
+			}
+		}
+		if req.Amount != nil {
+			if err := t.SetAmount(*req.Amount); err != nil {
+				return err
+			}
+		}
+		if req.Description != nil {
+			if err := t.SetDescription(*req.Description); err != nil {
+				return err
+			}
+		}
+		if req.Category != nil {
+			var category *budgeting.Category
+			if *req.Category != "" {
+				if category, err = referencedCategory(b, "category", *req.Category); err != nil {
+					return err
+				}
+			}
+			if err := t.SetCategory(category); err != nil {
+				return err
+			}
+		}
+		if req.Cleared != nil {
+			if err := t.SetCleared(*req.Cleared); err != nil {
+				return err
+			}
+		}
+
+		transaction = t
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, newTransactionView(transaction))
+}
+
+func (s *Server) deleteTransaction(w http.ResponseWriter, r *http.Request, p params) error {
+	action := fmt.Sprintf("deleted the transaction %s", p["transaction"])
+	_, err := s.update(r, p, action, func(b *budgeting.Budget) error {
+		t, err := b.Transaction(p["transaction"])
+		if err != nil {
+			return err
+		}
+
+		return t.Account().DeleteTransaction(t)
+	})
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Transactions(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestClient(t)
+
+	budget, savings, food := createBudget(c)
+	path := "/budgets/" + budget.ID
+
+	var wallet accountView
+	c.do(http.MethodPost, path+"/accounts", map[string]string{"name": "Wallet", "balance": "0", "date": "2018-01-01"}, &wallet)
+
+	var lunch transactionView
+	w := c.do(http.MethodPost, path+"/transactions", map[string]interface{}{
+		"account":     savings.ID,
+		"date":        "2018-01-02",
+		"amount":      -5.5,
+		"description": "lunch",
+		"category":    food.ID,
+	}, &lunch)
+	assert.Equal(http.StatusCreated, w.Code)
+	assert.Equal("-5.50", lunch.Amount)
+	assert.Equal(food.ID, lunch.Category)
+
+	var transfer transactionView
+	w = c.do(http.MethodPost, path+"/transactions", map[string]string{
+		"account":          savings.ID,
+		"date":             "2018-01-03",
+		"amount":           "-20.00",
+		"description":      "withdraw",
+		"transfer_account": wallet.ID,
+	}, &transfer)
+	assert.Equal(http.StatusCreated, w.Code)
+	assert.Equal(wallet.ID, transfer.TransferAccount)
+
+	var transactions []transactionView
+	c.do(http.MethodGet, path+"/transactions", nil, &transactions)
+	assert.Len(transactions, 5)
+
+	var other transactionView
+	for _, tr := range transactions {
+		if tr.Account == wallet.ID && tr.TransferAccount == savings.ID {
+			other = tr
+		}
+	}
+	assert.Equal("20.00", other.Amount)
+
+	var updated transactionView
+	w = c.do(http.MethodPatch, path+"/transactions/"+lunch.ID, map[string]string{
+		"amount":      "-7.00",
+		"description": "dinner",
+		"category":    "",
+	}, &updated)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("-7.00", updated.Amount)
+	assert.Equal("dinner", updated.Description)
+	assert.Equal("", updated.Category)
+	assert.Equal("2018-01-02", updated.Date)
+	assert.False(updated.Cleared)
+
+	c.do(http.MethodPatch, path+"/transactions/"+lunch.ID, map[string]bool{"cleared": true}, &updated)
+	assert.True(updated.Cleared)
+
+	var account accountView
+	c.do(http.MethodGet, path+"/accounts/"+savings.ID, nil, &account)
+	assert.Equal("73.00", account.Balance)
+
+	assert.Equal(http.StatusNoContent, c.do(http.MethodDelete, path+"/transactions/"+other.ID, nil, nil).Code)
+	assert.Equal(http.StatusNotFound, c.do(http.MethodGet, path+"/transactions/"+transfer.ID, nil, nil).Code)
+	c.do(http.MethodGet, path+"/accounts/"+savings.ID, nil, &account)
+	assert.Equal("93.00", account.Balance)
+}
+
+func TestServer_TransactionErrors(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestClient(t)
+
+	budget, savings, food := createBudget(c)
+	path := "/budgets/" + budget.ID + "/transactions"
+
+	cases := []struct {
+		body    map[string]string
+		status  int
+		message string
+	}{
+		{map[string]string{"account": savings.ID, "date": "2018-01-02"}, http.StatusUnprocessableEntity, "amount is required"},
+		{map[string]string{"account": "missing", "date": "2018-01-02", "amount": "1"}, http.StatusUnprocessableEntity, `account: unknown account "missing"`},
+		{map[string]string{"account": savings.ID, "date": "2018-01-02", "amount": "1", "category": "missing"}, http.StatusUnprocessableEntity, `category: unknown category "missing"`},
+		{map[string]string{"account": savings.ID, "date": "2018-01-02", "amount": "1", "category": food.ID, "transfer_account": savings.ID}, http.StatusUnprocessableEntity, "a transfer cannot have category"},
+	}
+
+	for _, tc := range cases {
+		var e errorJSON
+		w := c.do(http.MethodPost, path, tc.body, &e)
+		assert.Equal(tc.status, w.Code, tc.message)
+		assert.Equal(tc.message, e.Error.Message)
+	}
+
+	var e errorJSON
+	w := c.do(http.MethodPatch, path+"/missing", map[string]string{"description": "x"}, &e)
+	assert.Equal(http.StatusNotFound, w.Code)
+	assert.Equal("transaction not found", e.Error.Message)
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/hasyimibhar/budget-app/users"
+)
+
+type userView struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+func newUserView(u users.User) userView {
+	return userView{
+		ID:    u.ID,
+		Email: u.Email,
+	}
+}
+
+type credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (s *Server) register(w http.ResponseWriter, r *http.Request, p params) error {
+	var req credentials
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+
+	u, err := s.users.Register(req.Email, req.Password)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusCreated, newUserView(u))
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request, p params) error {
+	var req credentials
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+
+	token, u, err := s.users.Login(req.Email, req.Password)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusCreated, struct {
+		Token string   `json:"token"`
+		User  userView `json:"user"`
+	}{token, newUserView(u)})
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request, p params) error {
+	if err := s.users.Logout(bearerToken(r)); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request, p params) error {
+	return writeJSON(w, http.StatusOK, newUserView(currentUser(r)))
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Users(t *testing.T) {
+	assert := assert.New(t)
+	anonymous := &client{t: t, s: newTestServer()}
+
+	var user userView
+	w := anonymous.do(http.MethodPost, "/users", map[string]string{"email": "Bob@Example.com", "password": "hunter2hunter2"}, &user)
+	assert.Equal(http.StatusCreated, w.Code)
+	assert.Equal("bob@example.com", user.Email)
+
+	var e errorJSON
+	w = anonymous.do(http.MethodPost, "/users", map[string]string{"email": "bob@example.com", "password": "hunter2hunter2"}, &e)
+	assert.Equal(http.StatusConflict, w.Code)
+	w = anonymous.do(http.MethodPost, "/users", map[string]string{"email": "carol@example.com", "password": "short"}, &e)
+	assert.Equal(http.StatusUnprocessableEntity, w.Code)
+	assert.Equal("password must be at least 8 characters", e.Error.Message)
+
+	w = anonymous.do(http.MethodPost, "/session", map[string]string{"email": "bob@example.com", "password": "wrong password"}, &e)
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal(codeUnauthorized, e.Error.Code)
+
+	w = anonymous.do(http.MethodGet, "/user", nil, &e)
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal("Bearer", w.Header().Get("WWW-Authenticate"))
+
+	var session struct {
+		Token string   `json:"token"`
+		User  userView `json:"user"`
+	}
+	w = anonymous.do(http.MethodPost, "/session", map[string]string{"email": "bob@example.com", "password": "hunter2hunter2"}, &session)
+	assert.Equal(http.StatusCreated, w.Code)
+	assert.NotEmpty(session.Token)
+	assert.Equal(user, session.User)
+
+	bob := &client{t: t, s: anonymous.s, token: session.Token}
+	var me userView
+	assert.Equal(http.StatusOK, bob.do(http.MethodGet, "/user", nil, &me).Code)
+	assert.Equal(user, me)
+
+	w = bob.do(http.MethodPut, "/session", nil, &e)
+	assert.Equal(http.StatusMethodNotAllowed, w.Code)
+	assert.Equal("DELETE, POST", w.Header().Get("Allow"))
+
+	assert.Equal(http.StatusNoContent, bob.do(http.MethodDelete, "/session", nil, nil).Code)
+	assert.Equal(http.StatusUnauthorized, bob.do(http.MethodGet, "/user", nil, nil).Code)
+}
+
+func TestServer_BudgetsAreScopedToMembers(t *testing.T) {
+	assert := assert.New(t)
+
+	alice := newTestClient(t)
+	bob := login(t, alice.s, "bob@example.com")
+
+	budget, _, _ := createBudget(alice)
+	path := "/budgets/" + budget.ID
+
+	var budgets []budgetView
+	alice.do(http.MethodGet, "/budgets", nil, &budgets)
+	assert.Len(budgets, 1)
+	bob.do(http.MethodGet, "/budgets", nil, &budgets)
+	assert.Len(budgets, 0)
+
+	var e errorJSON
+	for _, p := range []string{path, path + "/accounts", path + "/months/2018-01"} {
+		w := bob.do(http.MethodGet, p, nil, &e)
+		assert.Equal(http.StatusNotFound, w.Code, p)
+		assert.Equal("budget not found", e.Error.Message)
+	}
+	assert.Equal(http.StatusNotFound, bob.do(http.MethodDelete, path, nil, nil).Code)
+
+	anonymous := &client{t: t, s: alice.s}
+	assert.Equal(http.StatusUnauthorized, anonymous.do(http.MethodGet, path, nil, nil).Code)
+	forged := &client{t: t, s: alice.s, token: "forged"}
+	assert.Equal(http.StatusUnauthorized, forged.do(http.MethodGet, path, nil, nil).Code)
+
+	assert.Equal(http.StatusNoContent, alice.do(http.MethodDelete, path, nil, nil).Code)
+	alice.do(http.MethodGet, "/budgets", nil, &budgets)
+	assert.Len(budgets, 0)
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/users"
+	"github.com/shopspring/decimal"
+)
+
+// dateLayout is the format of dates in requests and responses.
+const dateLayout = "2006-01-02"
+
+type budgetView struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Version     int64  `json:"version"`
+	TBBCategory string `json:"tbb_category"`
+
+	// Role is the role of the user making the request on the budget.
+	Role users.Role `json:"role"`
+}
+
+func newBudgetView(b *budgeting.Budget, role users.Role) budgetView {
+	return budgetView{
+		ID:          b.ID(),
+		Name:        b.Name(),
+		Version:     b.Version(),
+		TBBCategory: b.TBBCategory().ID(),
+		Role:        role,
+	}
+}
+
+type accountView struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Balance string `json:"balance"`
+	Closed  bool   `json:"closed"`
+}
+
+func newAccountView(a *budgeting.Account) accountView {
+	return accountView{
+		ID:      a.ID(),
+		Name:    a.Name(),
+		Balance: amount(a.Balance()),
+		Closed:  a.Closed(),
+	}
+}
+
+type categoryView struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Group string `json:"group,omitempty"`
+}
+
+func newCategoryView(c *budgeting.Category) categoryView {
+	return categoryView{
+		ID:    c.ID(),
+		Name:  c.Name(),
+		Group: c.Group(),
+	}
+}
+
+type transactionView struct {
+	ID              string     `json:"id"`
+	Account         string     `json:"account"`
+	Date            string     `json:"date"`
+	Amount          string     `json:"amount"`
+	Description     string     `json:"description"`
+	Category        string     `json:"category,omitempty"`
+	TransferAccount string     `json:"transfer_account,omitempty"`
+	ImportID        string     `json:"import_id,omitempty"`
+	Cleared         bool       `json:"cleared"`
+	Approved        bool       `json:"approved"`
+	Match           *matchView `json:"match,omitempty"`
+}
+
+// matchView is the imported transaction a transaction was matched with.
+type matchView struct {
+	ImportID    string `json:"import_id,omitempty"`
+	Date        string `json:"date"`
+	Description string `json:"description"`
+}
+
+func newTransactionView(t *budgeting.Transaction) transactionView {
+	v := transactionView{
+		ID:          t.ID(),
+		Account:     t.Account().ID(),
+		Date:        t.Date().Format(dateLayout),
+		Amount:      amount(t.Amount()),
+		Description: t.Description(),
+		ImportID:    t.ImportID(),
+		Cleared:     t.Cleared(),
+		Approved:    t.Approved(),
+	}
+
+	if c := t.Category(); c != nil {
+		v.Category = c.ID()
+	}
+	if m := t.Match(); m != nil {
+		v.Match = &matchView{
+			ImportID:    m.ImportID,
+			Date:        m.Date.Format(dateLayout),
+			Description: m.Description,
+		}
+	}
+	if a := t.TransferAccount(); a != nil {
+		v.TransferAccount = a.ID()
+	}
+
+	return v
+}
+
+type monthView struct {
+	Month      string              `json:"month"`
+	TBB        string              `json:"tbb"`
+	Categories []monthCategoryView `json:"categories"`
+}
+
+type monthCategoryView struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Group      string `json:"group,omitempty"`
+	Budgeted   string `json:"budgeted"`
+	Activities string `json:"activities"`
+	Available  string `json:"available"`
+}
+
+func newMonthView(b *budgeting.Budget, month budgeting.YearMonth) monthView {
+	v := monthView{
+		Month:      month.String(),
+		TBB:        amount(b.TBB(month)),
+		Categories: []monthCategoryView{},
+	}
+
+	tbb := b.TBBCategory()
+	for _, c := range b.Categories() {
+		if c.Equal(tbb) {
+			continue
+		}
+		v.Categories = append(v.Categories, newMonthCategoryView(b, month, c))
+	}
+
+	return v
+}
+
+func newMonthCategoryView(b *budgeting.Budget, month budgeting.YearMonth, c *budgeting.Category) monthCategoryView {
+	return monthCategoryView{
+		ID:         c.ID(),
+		Name:       c.Name(),
+		Group:      c.Group(),
+		Budgeted:   amount(b.Budgeted(month, c)),
+		Activities: amount(b.Activities(month, c)),
+		Available:  amount(b.Available(month, c)),
+	}
+}
+
+func amount(d decimal.Decimal) string {
+	return d.StringFixed(2)
+}
+
+func parseDate(field, s string) (time.Time, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}, invalid("%s must be a date like %s", field, dateLayout)
+	}
+	return t, nil
+}
@@ -0,0 +1,212 @@
+// Package app is the application service layer between the outside world
+// (the HTTP API, the command line) and the budgeting domain. It decides who
+// may do what with which budget, and keeps an audit log of the changes.
+//
+// Every method takes the ID of the user acting. Budgets the user isn't a
+// member of are reported as storage.ErrNotFound, so that their existence
+// isn't revealed; members whose role doesn't allow an action get ErrForbidden.
+package app
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/hasyimibhar/budget-app/users"
+)
+
+var (
+	// ErrForbidden is returned when a member of a budget attempts something
+	// their role doesn't allow.
+	ErrForbidden = fmt.Errorf("your role on the budget doesn't allow this")
+
+	// ErrLastOwner is returned when removing or demoting the only owner of a budget.
+	ErrLastOwner = fmt.Errorf("a budget must keep at least one owner")
+
+	// ErrAlreadyMember is returned when inviting someone who is already a
+	// member of the budget.
+	ErrAlreadyMember = fmt.Errorf("already a member of the budget")
+
+	// ErrInvalidRole is returned when a role other than owner, editor or
+	// viewer is given.
+	ErrInvalidRole = fmt.Errorf("role must be owner, editor or viewer")
+)
+
+// Service gives users access to the budgets they are members of.
+type Service struct {
+	budgets storage.Store
+	users   *users.Service
+
+	// memberLocks holds a lock for each budget whose members have been
+	// changed, guarded by mu.
+	mu          sync.Mutex
+	memberLocks map[string]*sync.Mutex
+}
+
+// NewService creates a service for the budgets in the store, shared among
+// the users of the user service.
+func NewService(budgets storage.Store, users *users.Service) *Service {
+	return &Service{
+		budgets:     budgets,
+		users:       users,
+		memberLocks: map[string]*sync.Mutex{},
+	}
+}
+
+// CreateBudget creates a budget owned by the actor.
+func (s *Service) CreateBudget(actorID, name string) (*budgeting.Budget, error) {
+	if _, err := s.users.Store().User(actorID); err != nil {
+		return nil, err
+	}
+
+	b := budgeting.NewBudget(name)
+	if err := s.budgets.Save(b, 0); err != nil {
+		return nil, err
+	}
+	if err := s.users.Store().SetRole(actorID, b.ID(), users.RoleOwner); err != nil {
+		s.budgets.Delete(b.ID())
+		return nil, err
+	}
+
+	s.audit(actorID, b.ID(), "created the budget %q", name)
+	return b, nil
+}
+
+// Budget returns the budget along with the actor's role on it.
+func (s *Service) Budget(actorID, budgetID string) (*budgeting.Budget, users.Role, error) {
+	role, err := s.authorize(actorID, budgetID, users.RoleViewer)
+	if err != nil {
+		return nil, "", err
+	}
+
+	b, err := s.budgets.Load(budgetID)
+	if err != nil {
+		return nil, "", err
+	}
+	return b, role, nil
+}
+
+// Budgets returns the budgets the actor is a member of, ordered by ID.
+func (s *Service) Budgets(actorID string) ([]*budgeting.Budget, error) {
+	u, err := s.users.Store().User(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for id := range u.Roles {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	budgets := []*budgeting.Budget{}
+	for _, id := range ids {
+		b, err := s.budgets.Load(id)
+		if err == storage.ErrNotFound {
+			// Deleted from the store behind the members' back.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, b)
+	}
+
+	return budgets, nil
+}
+
+// Update applies fn to the budget like storage.Update, provided the actor
+// may edit the budget. action describes the change for the audit log.
+func (s *Service) Update(actorID, budgetID, action string, fn func(b *budgeting.Budget) error) (*budgeting.Budget, error) {
+	if _, err := s.authorize(actorID, budgetID, users.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	b, err := storage.Update(s.budgets, budgetID, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(actorID, budgetID, "%s", action)
+	return b, nil
+}
+
+// DeleteBudget deletes the budget, removing all of its members and pending
+// invitations. Only owners may delete a budget.
+func (s *Service) DeleteBudget(actorID, budgetID string) error {
+	if _, err := s.authorize(actorID, budgetID, users.RoleOwner); err != nil {
+		return err
+	}
+
+	if err := s.budgets.Delete(budgetID); err != nil {
+		return err
+	}
+
+	members, err := s.users.Store().Members(budgetID)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if err := s.users.Store().RemoveRole(m.ID, budgetID); err != nil {
+			return err
+		}
+	}
+
+	invitations, err := s.users.Store().Invitations(budgetID)
+	if err != nil {
+		return err
+	}
+	for _, inv := range invitations {
+		if err := s.users.Store().DeleteInvitation(inv.ID); err != nil {
+			return err
+		}
+	}
+
+	s.audit(actorID, budgetID, "deleted the budget")
+	return nil
+}
+
+// Audit returns the audit log of the budget, oldest entry first.
+func (s *Service) Audit(actorID, budgetID string) ([]users.AuditEntry, error) {
+	if _, err := s.authorize(actorID, budgetID, users.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.users.Store().Audit(budgetID)
+}
+
+// authorize returns the actor's role on the budget if it allows what the
+// required role allows.
+func (s *Service) authorize(actorID, budgetID string, required users.Role) (users.Role, error) {
+	u, err := s.users.Store().User(actorID)
+	if err != nil {
+		return "", err
+	}
+
+	role := u.Role(budgetID)
+	if role == "" {
+		return "", storage.ErrNotFound
+	}
+	if !role.Allows(required) {
+		return "", ErrForbidden
+	}
+	return role, nil
+}
+
+// audit records a change in the audit log. The change has already been
+// saved by then, so a failure to record it is logged rather than returned:
+// reporting it to the actor would have them retry a change which was made.
+func (s *Service) audit(actorID, budgetID string, format string, args ...interface{}) {
+	e := users.AuditEntry{
+		Time:     s.users.Now().UTC(),
+		BudgetID: budgetID,
+		UserID:   actorID,
+		Action:   fmt.Sprintf(format, args...),
+	}
+	if err := s.users.Store().AppendAudit(e); err != nil {
+		log.Printf("app: recording %q by %s on budget %s in the audit log: %v", e.Action, actorID, budgetID, err)
+	}
+}
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/hasyimibhar/budget-app/users"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestService(t *testing.T, emails ...string) (*Service, []users.User) {
+	us := users.NewService(users.NewMemoryStore())
+	us.HashCost = bcrypt.MinCost
+
+	members := []users.User{}
+	for _, email := range emails {
+		u, err := us.Register(email, "correct horse")
+		if err != nil {
+			t.Fatal(err)
+		}
+		members = append(members, u)
+	}
+
+	return NewService(storage.NewMemoryStore(), us), members
+}
+
+func TestService_Roles(t *testing.T) {
+	assert := assert.New(t)
+	s, u := newTestService(t, "alice@example.com", "bob@example.com", "carol@example.com")
+	alice, bob, carol := u[0].ID, u[1].ID, u[2].ID
+
+	b, err := s.CreateBudget(alice, "Household")
+	assert.Nil(err)
+	assert.Nil(s.users.Store().SetRole(bob, b.ID(), users.RoleEditor))
+	assert.Nil(s.users.Store().SetRole(carol, b.ID(), users.RoleViewer))
+
+	_, role, err := s.Budget(alice, b.ID())
+	assert.Nil(err)
+	assert.Equal(users.RoleOwner, role)
+	_, role, err = s.Budget(carol, b.ID())
+	assert.Nil(err)
+	assert.Equal(users.RoleViewer, role)
+
+	addCategory := func(b *budgeting.Budget) error {
+		_, err := b.AddCategory("Food")
+		return err
+	}
+	_, err = s.Update(carol, b.ID(), "added Food", addCategory)
+	assert.Equal(ErrForbidden, err)
+	_, err = s.Update(bob, b.ID(), "added Food", addCategory)
+	assert.Nil(err)
+
+	assert.Equal(ErrForbidden, s.DeleteBudget(bob, b.ID()))
+	assert.Equal(ErrForbidden, s.SetRole(bob, b.ID(), carol, users.RoleEditor))
+	assert.Equal(ErrInvalidRole, s.SetRole(alice, b.ID(), carol, "admin"))
+	assert.Equal(ErrLastOwner, s.SetRole(alice, b.ID(), alice, users.RoleEditor))
+	assert.Equal(ErrLastOwner, s.Revoke(alice, b.ID(), alice))
+
+	// Anyone may leave.
+	assert.Nil(s.Revoke(carol, b.ID(), carol))
+	_, _, err = s.Budget(carol, b.ID())
+	assert.Equal(storage.ErrNotFound, err)
+	assert.Equal(users.ErrUserNotFound, s.Revoke(alice, b.ID(), carol))
+
+	// With a second owner, the first may step down.
+	assert.Nil(s.SetRole(alice, b.ID(), bob, users.RoleOwner))
+	assert.Nil(s.SetRole(alice, b.ID(), alice, users.RoleViewer))
+
+	budgets, err := s.Budgets(alice)
+	assert.Nil(err)
+	assert.Len(budgets, 1)
+
+	assert.Nil(s.DeleteBudget(bob, b.ID()))
+	members, err := s.users.Store().Members(b.ID())
+	assert.Nil(err)
+	assert.Empty(members)
+	budgets, err = s.Budgets(alice)
+	assert.Nil(err)
+	assert.Empty(budgets)
+}
+
+func TestService_Invitations(t *testing.T) {
+	assert := assert.New(t)
+	s, u := newTestService(t, "alice@example.com", "bob@example.com", "carol@example.com")
+	alice, bob, carol := u[0].ID, u[1].ID, u[2].ID
+
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.users.Now = func() time.Time { return now }
+
+	b, _ := s.CreateBudget(alice, "Household")
+
+	_, _, err := s.Invite(alice, b.ID(), "bob@example.com", "admin")
+	assert.Equal(ErrInvalidRole, err)
+	_, _, err = s.Invite(alice, b.ID(), "alice@example.com", users.RoleViewer)
+	assert.Equal(ErrAlreadyMember, err)
+	_, _, err = s.Invite(bob, b.ID(), "bob@example.com", users.RoleViewer)
+	assert.Equal(storage.ErrNotFound, err)
+
+	token, inv, err := s.Invite(alice, b.ID(), "Bob@Example.com", users.RoleEditor)
+	assert.Nil(err)
+	assert.Equal("bob@example.com", inv.Email)
+	assert.Equal(now.Add(InvitationTTL), inv.Expires)
+
+	invitations, err := s.Invitations(alice, b.ID())
+	assert.Nil(err)
+	assert.Equal([]users.Invitation{inv}, invitations)
+
+	// Only the invitee can accept it.
+	_, err = s.AcceptInvitation(carol, token)
+	assert.Equal(users.ErrInvitationNotFound, err)
+	_, err = s.AcceptInvitation(bob, "forged")
+	assert.Equal(users.ErrInvitationNotFound, err)
+
+	id, err := s.AcceptInvitation(bob, token)
+	assert.Nil(err)
+	assert.Equal(b.ID(), id)
+	_, role, err := s.Budget(bob, b.ID())
+	assert.Nil(err)
+	assert.Equal(users.RoleEditor, role)
+
+	// Invitations can be used once.
+	_, err = s.AcceptInvitation(bob, token)
+	assert.Equal(users.ErrInvitationNotFound, err)
+
+	// Or not at all, once expired or cancelled.
+	token, _, _ = s.Invite(alice, b.ID(), "carol@example.com", users.RoleViewer)
+	now = now.Add(InvitationTTL)
+	_, err = s.AcceptInvitation(carol, token)
+	assert.Equal(users.ErrInvitationNotFound, err)
+
+	_, inv, _ = s.Invite(alice, b.ID(), "carol@example.com", users.RoleViewer)
+	assert.Equal(users.ErrInvitationNotFound, s.CancelInvitation(alice, b.ID(), "missing"))
+	assert.Nil(s.CancelInvitation(alice, b.ID(), inv.ID))
+	invitations, _ = s.Invitations(alice, b.ID())
+	assert.Empty(invitations)
+
+	audit, err := s.Audit(bob, b.ID())
+	assert.Nil(err)
+	actions := []string{}
+	for _, e := range audit {
+		actions = append(actions, e.Action)
+	}
+	assert.Equal([]string{
+		`created the budget "Household"`,
+		"invited bob@example.com as editor",
+		"joined as editor",
+		"invited carol@example.com as viewer",
+		"invited carol@example.com as viewer",
+		"cancelled the invitation of carol@example.com",
+	}, actions)
+	assert.Equal(bob, audit[2].UserID)
+}
+
+func TestService_LastOwnerConcurrently(t *testing.T) {
+	assert := assert.New(t)
+	s, u := newTestService(t, "alice@example.com", "bob@example.com")
+	alice, bob := u[0].ID, u[1].ID
+
+	b, _ := s.CreateBudget(alice, "Household")
+	assert.Nil(s.users.Store().SetRole(bob, b.ID(), users.RoleOwner))
+
+	// Both owners step down at once; one of them must stay.
+	errs := make(chan error, 2)
+	for _, id := range []string{alice, bob} {
+		go func(id string) {
+			errs <- s.SetRole(id, b.ID(), id, users.RoleEditor)
+		}(id)
+	}
+	results := []error{<-errs, <-errs}
+	assert.Contains(results, nil)
+	assert.Contains(results, ErrLastOwner)
+
+	members, _ := s.users.Store().Members(b.ID())
+	owners := 0
+	for _, m := range members {
+		if m.Role(b.ID()) == users.RoleOwner {
+			owners++
+		}
+	}
+	assert.Equal(1, owners)
+}
+
+type failingAuditStore struct {
+	*users.MemoryStore
+}
+
+func (failingAuditStore) AppendAudit(users.AuditEntry) error {
+	return fmt.Errorf("disk full")
+}
+
+func TestService_AuditFailure(t *testing.T) {
+	assert := assert.New(t)
+	us := users.NewService(failingAuditStore{users.NewMemoryStore()})
+	us.HashCost = bcrypt.MinCost
+	alice, _ := us.Register("alice@example.com", "correct horse")
+	s := NewService(storage.NewMemoryStore(), us)
+
+	// The changes are saved, so they are reported as made.
+	b, err := s.CreateBudget(alice.ID, "Household")
+	assert.Nil(err)
+	b, err = s.Update(alice.ID, b.ID(), "added Food", func(b *budgeting.Budget) error {
+		_, err := b.AddCategory("Food")
+		return err
+	})
+	assert.Nil(err)
+	assert.Len(b.Categories(), 2)
+}
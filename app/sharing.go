@@ -0,0 +1,245 @@
+package app
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/users"
+	uuid "github.com/satori/go.uuid"
+)
+
+// InvitationTTL is how long an invitation can be accepted for.
+const InvitationTTL = 7 * 24 * time.Hour
+
+// Members returns the members of the budget, ordered by email address.
+func (s *Service) Members(actorID, budgetID string) ([]users.User, error) {
+	if _, err := s.authorize(actorID, budgetID, users.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.users.Store().Members(budgetID)
+}
+
+// SetRole changes the role of a member of the budget. Only owners may change
+// roles, and the last owner can't be demoted.
+func (s *Service) SetRole(actorID, budgetID, userID string, role users.Role) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+	if _, err := s.authorize(actorID, budgetID, users.RoleOwner); err != nil {
+		return err
+	}
+
+	unlock := s.lockMembers(budgetID)
+	defer unlock()
+
+	member, err := s.member(budgetID, userID)
+	if err != nil {
+		return err
+	}
+	if role != users.RoleOwner {
+		if err := s.keepOwner(budgetID, member); err != nil {
+			return err
+		}
+	}
+
+	if err := s.users.Store().SetRole(userID, budgetID, role); err != nil {
+		return err
+	}
+	s.audit(actorID, budgetID, "made %s %s", member.Email, role)
+	return nil
+}
+
+// Revoke removes a member from the budget. Owners may remove anyone, and
+// anyone may remove themselves, but the last owner can't be removed.
+func (s *Service) Revoke(actorID, budgetID, userID string) error {
+	required := users.RoleOwner
+	if actorID == userID {
+		required = users.RoleViewer
+	}
+	if _, err := s.authorize(actorID, budgetID, required); err != nil {
+		return err
+	}
+
+	unlock := s.lockMembers(budgetID)
+	defer unlock()
+
+	member, err := s.member(budgetID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.keepOwner(budgetID, member); err != nil {
+		return err
+	}
+
+	if err := s.users.Store().RemoveRole(userID, budgetID); err != nil {
+		return err
+	}
+
+	if actorID == userID {
+		s.audit(actorID, budgetID, "left the budget")
+		return nil
+	}
+	s.audit(actorID, budgetID, "removed %s", member.Email)
+	return nil
+}
+
+// Invite invites the person with the given email address to the budget with
+// the given role. Only owners may invite. The returned token is what the
+// invitee accepts the invitation with; it isn't stored and can't be
+// recovered later.
+func (s *Service) Invite(actorID, budgetID, email string, role users.Role) (string, users.Invitation, error) {
+	if !role.Valid() {
+		return "", users.Invitation{}, ErrInvalidRole
+	}
+	if _, err := s.authorize(actorID, budgetID, users.RoleOwner); err != nil {
+		return "", users.Invitation{}, err
+	}
+
+	email = users.NormalizeEmail(email)
+	if u, err := s.users.Store().UserByEmail(email); err == nil && u.Role(budgetID) != "" {
+		return "", users.Invitation{}, ErrAlreadyMember
+	}
+
+	token, err := users.NewToken()
+	if err != nil {
+		return "", users.Invitation{}, err
+	}
+
+	inv := users.Invitation{
+		ID:        uuid.NewV4().String(),
+		TokenHash: users.HashToken(token),
+		BudgetID:  budgetID,
+		Email:     email,
+		Role:      role,
+		InvitedBy: actorID,
+		Expires:   s.users.Now().Add(InvitationTTL).UTC(),
+	}
+	if err := s.users.Store().AddInvitation(inv); err != nil {
+		return "", users.Invitation{}, err
+	}
+
+	s.audit(actorID, budgetID, "invited %s as %s", email, role)
+	return token, inv, nil
+}
+
+// Invitations returns the pending invitations to the budget. Only owners may
+// see them.
+func (s *Service) Invitations(actorID, budgetID string) ([]users.Invitation, error) {
+	if _, err := s.authorize(actorID, budgetID, users.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	return s.users.Store().Invitations(budgetID)
+}
+
+// CancelInvitation withdraws a pending invitation to the budget.
+func (s *Service) CancelInvitation(actorID, budgetID, invitationID string) error {
+	invitations, err := s.Invitations(actorID, budgetID)
+	if err != nil {
+		return err
+	}
+
+	for _, inv := range invitations {
+		if inv.ID != invitationID {
+			continue
+		}
+
+		if err := s.users.Store().DeleteInvitation(inv.ID); err != nil {
+			return err
+		}
+		s.audit(actorID, budgetID, "cancelled the invitation of %s", inv.Email)
+		return nil
+	}
+
+	return users.ErrInvitationNotFound
+}
+
+// AcceptInvitation makes the actor a member of the budget the invitation is
+// for, and returns the budget's ID. Invitations can only be accepted by the
+// user with the email address they were sent to. Accepting never lowers the
+// role the actor already has.
+func (s *Service) AcceptInvitation(actorID, token string) (string, error) {
+	u, err := s.users.Store().User(actorID)
+	if err != nil {
+		return "", err
+	}
+
+	inv, err := s.users.Store().InvitationByToken(users.HashToken(token))
+	if err != nil {
+		return "", err
+	}
+	if !s.users.Now().Before(inv.Expires) {
+		s.users.Store().DeleteInvitation(inv.ID)
+		return "", users.ErrInvitationNotFound
+	}
+	if inv.Email != u.Email {
+		return "", users.ErrInvitationNotFound
+	}
+
+	if !u.Role(inv.BudgetID).Allows(inv.Role) {
+		if err := s.users.Store().SetRole(actorID, inv.BudgetID, inv.Role); err != nil {
+			return "", err
+		}
+	}
+	if err := s.users.Store().DeleteInvitation(inv.ID); err != nil {
+		return "", err
+	}
+
+	s.audit(actorID, inv.BudgetID, "joined as %s", inv.Role)
+	return inv.BudgetID, nil
+}
+
+// member returns the member of the budget with the given ID.
+func (s *Service) member(budgetID, userID string) (users.User, error) {
+	u, err := s.users.Store().User(userID)
+	if err != nil {
+		return users.User{}, err
+	}
+	if u.Role(budgetID) == "" {
+		return users.User{}, users.ErrUserNotFound
+	}
+	return u, nil
+}
+
+// lockMembers locks the members of the budget until the returned function
+// is called, so that the last owner can't be demoted or removed by two
+// changes each seeing the other owner still there. It only guards changes
+// made through this service.
+func (s *Service) lockMembers(budgetID string) func() {
+	s.mu.Lock()
+	l, ok := s.memberLocks[budgetID]
+	if !ok {
+		l = &sync.Mutex{}
+		s.memberLocks[budgetID] = l
+	}
+	s.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// keepOwner returns ErrLastOwner if member is the only owner of the budget.
+// The members of the budget must be locked with lockMembers, for the check
+// to hold until the role is changed.
+func (s *Service) keepOwner(budgetID string, member users.User) error {
+	if member.Role(budgetID) != users.RoleOwner {
+		return nil
+	}
+
+	members, err := s.users.Store().Members(budgetID)
+	if err != nil {
+		return err
+	}
+
+	owners := 0
+	for _, m := range members {
+		if m.Role(budgetID) == users.RoleOwner {
+			owners++
+		}
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package budgeting
 
 import (
 	"fmt"
-	"sort"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -14,36 +13,122 @@ var (
 	// ErrCannotAssignCategoryToTransfer is returned when there is an attempt
 	// to create a transfer transaction without a category.
 	ErrCannotAssignCategoryToTransfer = fmt.Errorf("a transfer cannot have category")
+
+	// ErrTransactionNotFound is returned when a transaction does not belong to
+	// the account or has been deleted.
+	ErrTransactionNotFound = fmt.Errorf("transaction not found")
+
+	// ErrAccountNotFound is returned when an account doesn't belong to the budget.
+	ErrAccountNotFound = fmt.Errorf("account not found")
+
+	// ErrForeignAccount is returned when a transfer is made to an account of
+	// another budget.
+	ErrForeignAccount = fmt.Errorf("account belongs to another budget")
+
+	// ErrAccountClosed is returned when there is an attempt to add, change or
+	// delete a transaction on a closed account.
+	ErrAccountClosed = fmt.Errorf("account is closed")
 )
 
 // Account represents a physical account which stores money
 // (e.g. savings account, your wallet).
 type Account struct {
-	Name string
-
+	name                string
+	uuid                string
 	budget              *Budget
 	transactions        []*Transaction
 	transactionCategory map[string][]*Transaction
 	closed              bool
+
+	// startingBalanceID is the ID of the transaction the account was
+	// opened with.
+	startingBalanceID string
 }
 
-func newAccount(budget *Budget, name string, balance decimal.Decimal, date time.Time, tbb *Category) (*Account, error) {
+func newAccount(budget *Budget, id string, name string, balance decimal.Decimal, date time.Time, tbb *Category, startingBalanceID string) (*Account, error) {
 	a := &Account{
-		Name: name,
-
+		name:                name,
+		uuid:                id,
 		budget:              budget,
 		transactions:        []*Transaction{},
 		transactionCategory: map[string][]*Transaction{},
 		closed:              false,
 	}
 
-	if _, err := a.AddTransaction(date, balance, "Starting balance", tbb, nil); err != nil {
+	opts := options{id: startingBalanceID}
+	t, err := a.addTransaction(date, balance, "Starting balance", tbb, nil, opts)
+	if err != nil {
 		return nil, err
 	}
+	a.startingBalanceID = t.uuid
 
 	return a, nil
 }
 
+// ID returns the account's unique identifier.
+func (a *Account) ID() string {
+	return a.uuid
+}
+
+// Name returns the name of the account. It is set when the account is
+// created and never changes, so it is read without the budget's lock.
+func (a *Account) Name() string {
+	return a.name
+}
+
+// Budget returns the budget the account belongs to.
+func (a *Account) Budget() *Budget {
+	return a.budget
+}
+
+// StartingBalance returns the transaction the account was opened with,
+// or nil if it has been deleted.
+func (a *Account) StartingBalance() *Transaction {
+	a.budget.mu.RLock()
+	defer a.budget.mu.RUnlock()
+
+	return a.startingBalance()
+}
+
+func (a *Account) startingBalance() *Transaction {
+	if t, ok := a.budget.transactions[a.startingBalanceID]; ok && t.account == a {
+		return t
+	}
+	return nil
+}
+
+// Closed reports whether the account is closed.
+func (a *Account) Closed() bool {
+	a.budget.mu.RLock()
+	defer a.budget.mu.RUnlock()
+
+	return a.closed
+}
+
+// Close closes the account. The transactions of a closed account, including
+// transfers from other accounts, can't be added, changed or deleted until the
+// account is reopened.
+func (a *Account) Close() {
+	a.budget.mu.Lock()
+	defer a.budget.mu.Unlock()
+
+	if !a.closed {
+		a.closed = true
+		a.budget.touch()
+	}
+}
+
+// Reopen reopens a closed account.
+func (a *Account) Reopen() {
+	a.budget.mu.Lock()
+	defer a.budget.mu.Unlock()
+
+	if a.closed {
+		a.closed = false
+		a.budget.touch()
+	}
+}
+
 // AddTransaction creates a transaction on the account.
 // The rel argument is used to indicate a transfer between 2 accounts.
 // If rel is not nil, a matching transaction will be created on that account
@@ -53,55 +138,123 @@ func (a *Account) AddTransaction(
 	amount decimal.Decimal,
 	description string,
 	category *Category,
-	rel *Account) (*Transaction, error) {
+	rel *Account,
+	opts ...Option) (*Transaction, error) {
+
+	a.budget.mu.Lock()
+	defer a.budget.mu.Unlock()
+
+	return a.addTransaction(date, amount, description, category, rel, newOptions(opts))
+}
+
+func (a *Account) addTransaction(
+	date time.Time,
+	amount decimal.Decimal,
+	description string,
+	category *Category,
+	rel *Account,
+	o options) (*Transaction, error) {
 
 	if rel != nil && category != nil {
 		return nil, ErrCannotAssignCategoryToTransfer
 	}
+	if category != nil && !a.budget.ownsCategory(category) {
+		return nil, ErrForeignCategory
+	}
+	if rel != nil && !a.budget.ownsAccount(rel) {
+		return nil, ErrForeignAccount
+	}
+	if a.closed || (rel != nil && rel.closed) {
+		return nil, ErrAccountClosed
+	}
+
+	id := idOrNew(o.id)
+	if _, ok := a.budget.transactions[id]; ok {
+		return nil, ErrDuplicateID
+	}
 
-	t := newTransaction(a.budget, a, date, amount, description, category, rel)
+	pairID := ""
+	if rel != nil {
+		pairID = idOrNew(o.pairID)
+		if _, ok := a.budget.transactions[pairID]; ok || pairID == id {
+			return nil, ErrDuplicateID
+		}
+	}
+
+	// The category is assigned by setTransactionCategory below, which also indexes the transaction.
+	t := newTransaction(id, a.budget, a, date, amount, description, nil, rel)
+	t.importID = o.importID
+	t.cleared = o.cleared
+	t.unapproved = o.unapproved
 	a.transactions = append(a.transactions, t)
+	a.budget.transactions[t.uuid] = t
 
 	if rel != nil {
-		t2 := newTransaction(a.budget, rel, date, amount.Neg(), description, category, a)
+		t2 := newTransaction(pairID, a.budget, rel, date, amount.Neg(), description, category, a)
 		rel.transactions = append(rel.transactions, t2)
-	}
+		a.budget.transactions[t2.uuid] = t2
 
-	if a.budget.earliestMonth.EarlierTime(date) {
-		a.budget.earliestMonth = YearMonthFromTime(date)
-	}
-	if a.budget.latestMonth.LaterTime(date) {
-		a.budget.latestMonth = YearMonthFromTime(date)
+		t.pair = t2
+		t2.pair = t
 	}
 
-	a.budget.setTransactionCategory(t, category)
+	a.budget.extendMonths(YearMonthFromTime(date))
+
+	if category != nil {
+		// Can't fail: the category was checked above and t isn't a transfer.
+		a.budget.setTransactionCategory(t, category)
+	}
+	a.budget.touch()
 
 	return t, nil
 }
 
 // Balance returns the account balance.
 func (a *Account) Balance() decimal.Decimal {
-	transactions := a.transactions
-	sort.Sort(byDate(transactions))
+	a.budget.mu.RLock()
+	defer a.budget.mu.RUnlock()
 
 	balance := zero
-	for _, t := range transactions {
-		balance = balance.Add(t.Amount)
+	for _, t := range a.transactions {
+		balance = balance.Add(t.amount)
 	}
 
 	return balance
 }
 
-type byDate []*Transaction
+// Transactions returns the transactions of the account ordered by date.
+// Transactions on the same date are in the order they were added in.
+func (a *Account) Transactions() []*Transaction {
+	a.budget.mu.RLock()
+	defer a.budget.mu.RUnlock()
 
-func (p byDate) Len() int {
-	return len(p)
-}
+	transactions := make([]*Transaction, len(a.transactions))
+	copy(transactions, a.transactions)
 
-func (p byDate) Less(i, j int) bool {
-	return p[i].Date.Before(p[j].Date)
+	sortByDate(transactions)
+	return transactions
 }
 
-func (p byDate) Swap(i, j int) {
-	p[i], p[j] = p[j], p[i]
+// DeleteTransaction removes a transaction from the account.
+// Deleting either side of a transfer removes both sides.
+func (a *Account) DeleteTransaction(t *Transaction) error {
+	a.budget.mu.Lock()
+	defer a.budget.mu.Unlock()
+
+	if t.account != a {
+		return ErrTransactionNotFound
+	}
+	if err := t.checkEditable(); err != nil {
+		return err
+	}
+
+	for _, tt := range t.sides() {
+		a.budget.unindexTransaction(tt)
+		tt.account.transactions = removeTransaction(tt.account.transactions, tt)
+		delete(a.budget.transactions, tt.uuid)
+		tt.deleted = true
+	}
+
+	a.budget.touch()
+	return nil
 }
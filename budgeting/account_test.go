@@ -14,7 +14,8 @@ func TestAccount_NewAccount(t *testing.T) {
 	assert := assert.New(t)
 	b := NewBudget("My Budget")
 
-	account := b.AddAccount("Savings Account", dec("0.00"), date(2018, 1, 1))
+	account, _ := b.AddAccount("Savings Account", dec("0.00"), date(2018, 1, 1))
+	assert.True(account.Budget() == b)
 	assert.True(account.Balance().Equal(dec("0.00")))
 	assert.True(b.TBB(month).Equal(dec("0.00")))
 }
@@ -23,22 +24,37 @@ func TestAccount_NewAccountWithBalance(t *testing.T) {
 	assert := assert.New(t)
 	b := NewBudget("My Budget")
 
-	a1 := b.AddAccount("Savings Account 1", dec("5.00"), date(2018, 1, 1))
+	a1, _ := b.AddAccount("Savings Account 1", dec("5.00"), date(2018, 1, 1))
 	assert.True(a1.Balance().Equal(dec("5.00")))
 	assert.True(b.TBB(month).Equal(dec("5.00")))
 
-	a2 := b.AddAccount("Savings Account 2", dec("-12.34"), date(2018, 1, 1))
+	a2, _ := b.AddAccount("Savings Account 2", dec("-12.34"), date(2018, 1, 1))
 	assert.True(a2.Balance().Equal(dec("-12.34")))
 	assert.True(b.TBB(month).Equal(dec("-7.34")))
 }
 
+func TestAccount_StartingBalance(t *testing.T) {
+	assert := assert.New(t)
+	b := NewBudget("My Budget")
+
+	account, _ := b.AddAccount("Savings Account", dec("5.00"), date(2018, 1, 1), WithStartingBalanceID("opening"))
+	starting := account.StartingBalance()
+	assert.Equal("opening", starting.ID())
+
+	starting.SetDescription("Opened with")
+	assert.True(account.StartingBalance() == starting)
+
+	assert.Nil(account.DeleteTransaction(starting))
+	assert.Nil(account.StartingBalance())
+}
+
 func TestAccount_AddTransaction(t *testing.T) {
 	assert := assert.New(t)
 	b := NewBudget("My Budget")
 
-	account := b.AddAccount("Savings Account", dec("0.00"), date(2018, 1, 1))
-	food := b.AddCategory("Food & Beverages")
-	bills := b.AddCategory("Bills")
+	account, _ := b.AddAccount("Savings Account", dec("0.00"), date(2018, 1, 1))
+	food, _ := b.AddCategory("Food & Beverages")
+	bills, _ := b.AddCategory("Bills")
 
 	var tr *Transaction
 
@@ -63,9 +79,9 @@ func TestAccount_AddTransactionDoubleEntry(t *testing.T) {
 	assert := assert.New(t)
 	b := NewBudget("My Budget")
 
-	account := b.AddAccount("Savings Account", dec("0.00"), date(2018, 1, 1))
-	wallet := b.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
-	food := b.AddCategory("Food & Beverages")
+	account, _ := b.AddAccount("Savings Account", dec("0.00"), date(2018, 1, 1))
+	wallet, _ := b.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+	food, _ := b.AddCategory("Food & Beverages")
 
 	var tr *Transaction
 
@@ -94,9 +110,9 @@ func TestAccount_AddTransactionTransferWithCategory(t *testing.T) {
 	assert := assert.New(t)
 	b := NewBudget("My Budget")
 
-	account := b.AddAccount("Savings Account", dec("0.00"), date(2018, 1, 1))
-	wallet := b.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
-	food := b.AddCategory("Food & Beverages")
+	account, _ := b.AddAccount("Savings Account", dec("0.00"), date(2018, 1, 1))
+	wallet, _ := b.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+	food, _ := b.AddCategory("Food & Beverages")
 
 	account.AddTransaction(date(2018, 1, 1), dec("10.00"), "got some money", b.TBBCategory(), nil)
 
@@ -106,6 +122,69 @@ func TestAccount_AddTransactionTransferWithCategory(t *testing.T) {
 	assert.EqualError(err, ErrCannotAssignCategoryToTransfer.Error())
 }
 
+func TestAccount_AddTransactionForeign(t *testing.T) {
+	assert := assert.New(t)
+	b := NewBudget("My Budget")
+	other := NewBudget("Other Budget")
+
+	account, _ := b.AddAccount("Savings Account", dec("0.00"), date(2018, 1, 1))
+	wallet, _ := other.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+	rent, _ := other.AddCategory("Rent")
+
+	_, err := account.AddTransaction(date(2018, 1, 2), dec("-5.00"), "rent", rent, nil)
+	assert.Equal(ErrForeignCategory, err)
+
+	_, err = account.AddTransaction(date(2018, 1, 2), dec("-5.00"), "withdraw to wallet", nil, wallet)
+	assert.Equal(ErrForeignAccount, err)
+
+	tr, _ := account.AddTransaction(date(2018, 1, 2), dec("-5.00"), "groceries", nil, nil)
+	assert.Equal(ErrForeignCategory, tr.SetCategory(rent))
+	assert.Nil(tr.Category())
+
+	assert.Len(account.Transactions(), 2)
+	assert.Len(wallet.Transactions(), 1)
+	assert.Empty(b.Verify())
+}
+
+func TestAccount_Close(t *testing.T) {
+	assert := assert.New(t)
+	b := NewBudget("My Budget")
+
+	account, _ := b.AddAccount("Savings Account", dec("10.00"), date(2018, 1, 1))
+	wallet, _ := b.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+
+	lunch, _ := wallet.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", nil, nil)
+	transfer, _ := account.AddTransaction(date(2018, 1, 2), dec("-5.00"), "withdraw to wallet", nil, wallet)
+
+	version := b.Version()
+	wallet.Close()
+	assert.True(wallet.Closed())
+	assert.Equal(version+1, b.Version())
+
+	_, err := wallet.AddTransaction(date(2018, 1, 3), dec("-1.00"), "coffee", nil, nil)
+	assert.Equal(ErrAccountClosed, err)
+	_, err = account.AddTransaction(date(2018, 1, 3), dec("-1.00"), "withdraw to wallet", nil, wallet)
+	assert.Equal(ErrAccountClosed, err)
+
+	assert.Equal(ErrAccountClosed, lunch.SetAmount(dec("-6.00")))
+	assert.Equal(ErrAccountClosed, lunch.SetDate(date(2018, 1, 3)))
+	assert.Equal(ErrAccountClosed, lunch.SetDescription("dinner"))
+	assert.Equal(ErrAccountClosed, lunch.SetCategory(b.TBBCategory()))
+	assert.Equal(ErrAccountClosed, wallet.DeleteTransaction(lunch))
+
+	// The other side of the transfer is on the closed account.
+	assert.Equal(ErrAccountClosed, transfer.SetAmount(dec("-6.00")))
+	assert.Equal(ErrAccountClosed, account.DeleteTransaction(transfer))
+
+	assert.True(wallet.Balance().Equal(dec("0.00")))
+	assert.True(account.Balance().Equal(dec("5.00")))
+
+	wallet.Reopen()
+	assert.False(wallet.Closed())
+	assert.Nil(lunch.SetAmount(dec("-6.00")))
+	assert.True(wallet.Balance().Equal(dec("-1.00")))
+}
+
 func date(y int, m int, d int) time.Time {
 	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
 }
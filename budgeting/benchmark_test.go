@@ -0,0 +1,227 @@
+package budgeting_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/budgeting/budgetingtest"
+	"github.com/shopspring/decimal"
+)
+
+// benchmarkSizes are the budgets every benchmark runs against.
+// The large one spans 10 years with roughly 100k transactions.
+var benchmarkSizes = []struct {
+	name   string
+	config budgetingtest.Config
+}{
+	{"1y", sized(1, 4, 40)},
+	{"10y", sized(10, 5, 167)},
+}
+
+func sized(years, accounts, perMonth int) budgetingtest.Config {
+	c := budgetingtest.DefaultConfig()
+	c.Years = years
+	c.Accounts = accounts
+	c.TransactionsPerMonth = perMonth
+	return c
+}
+
+var generated = map[string]*budgeting.Budget{}
+
+// sharedBudget returns a generated budget which is reused between read-only
+// benchmarks. Benchmarks which modify the budget must call freshBudget instead.
+func sharedBudget(name string, c budgetingtest.Config) *budgeting.Budget {
+	if _, ok := generated[name]; !ok {
+		generated[name] = budgetingtest.Generate(c)
+	}
+	return generated[name]
+}
+
+func freshBudget(b *testing.B, c budgetingtest.Config) *budgeting.Budget {
+	b.StopTimer()
+	defer b.StartTimer()
+
+	return budgetingtest.Generate(c)
+}
+
+func benchmarkRead(b *testing.B, f func(b *testing.B, budget *budgeting.Budget)) {
+	for _, size := range benchmarkSizes {
+		b.Run(size.name, func(b *testing.B) {
+			budget := sharedBudget(size.name, size.config)
+			b.ResetTimer()
+			f(b, budget)
+		})
+	}
+}
+
+func benchmarkWrite(b *testing.B, f func(b *testing.B, budget *budgeting.Budget)) {
+	for _, size := range benchmarkSizes {
+		b.Run(size.name, func(b *testing.B) {
+			budget := freshBudget(b, size.config)
+			b.ResetTimer()
+			f(b, budget)
+		})
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(size.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				budgetingtest.Generate(size.config)
+			}
+		})
+	}
+}
+
+func BenchmarkBudget_TBB(b *testing.B) {
+	benchmarkRead(b, func(b *testing.B, budget *budgeting.Budget) {
+		month := budgeting.LatestMonth(budget)
+		for i := 0; i < b.N; i++ {
+			budget.TBB(month)
+		}
+	})
+}
+
+func BenchmarkBudget_Available(b *testing.B) {
+	benchmarkRead(b, func(b *testing.B, budget *budgeting.Budget) {
+		c := budgeting.SomeCategory(budget)
+		month := budgeting.LatestMonth(budget)
+		for i := 0; i < b.N; i++ {
+			budget.Available(month, c)
+		}
+	})
+}
+
+func BenchmarkBudget_Activities(b *testing.B) {
+	benchmarkRead(b, func(b *testing.B, budget *budgeting.Budget) {
+		c := budgeting.SomeCategory(budget)
+		month := budgeting.LatestMonth(budget)
+		for i := 0; i < b.N; i++ {
+			budget.Activities(month, c)
+		}
+	})
+}
+
+// BenchmarkBudget_ActivitiesLinearScan is the baseline for BenchmarkBudget_Activities
+// without the month/category index.
+func BenchmarkBudget_ActivitiesLinearScan(b *testing.B) {
+	benchmarkRead(b, func(b *testing.B, budget *budgeting.Budget) {
+		c := budgeting.SomeCategory(budget)
+		month := budgeting.LatestMonth(budget)
+		for i := 0; i < b.N; i++ {
+			budgeting.LinearScanActivities(budget, month, c)
+		}
+	})
+}
+
+func BenchmarkAccount_Balance(b *testing.B) {
+	benchmarkRead(b, func(b *testing.B, budget *budgeting.Budget) {
+		a := budgeting.FirstAccount(budget)
+		for i := 0; i < b.N; i++ {
+			a.Balance()
+		}
+	})
+}
+
+func BenchmarkBudget_MarshalJSON(b *testing.B) {
+	benchmarkRead(b, func(b *testing.B, budget *budgeting.Budget) {
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(budget); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkBudget_UnmarshalJSON(b *testing.B) {
+	benchmarkRead(b, func(b *testing.B, budget *budgeting.Budget) {
+		data, err := json.Marshal(budget)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			var restored budgeting.Budget
+			if err := json.Unmarshal(data, &restored); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkAccount_AddTransaction(b *testing.B) {
+	benchmarkWrite(b, func(b *testing.B, budget *budgeting.Budget) {
+		a := budgeting.FirstAccount(budget)
+		c := budgeting.SomeCategory(budget)
+		month := budgeting.LatestMonth(budget)
+		date := time.Date(month.Year, month.Month, 15, 0, 0, 0, 0, time.UTC)
+
+		for i := 0; i < b.N; i++ {
+			a.AddTransaction(date, decimal.New(-500, -2), "Expense", c, nil)
+		}
+	})
+}
+
+func BenchmarkBudget_SetBudgeted(b *testing.B) {
+	benchmarkWrite(b, func(b *testing.B, budget *budgeting.Budget) {
+		c := budgeting.SomeCategory(budget)
+		month := budgeting.LatestMonth(budget)
+
+		for i := 0; i < b.N; i++ {
+			budget.SetBudgeted(month, c, decimal.New(int64(i), -2))
+		}
+	})
+}
+
+// BenchmarkBudget_EditThenRead measures the common page render after an edit
+// early in the budget's history, which invalidates most of the cached summaries.
+func BenchmarkBudget_EditThenRead(b *testing.B) {
+	benchmarkWrite(b, func(b *testing.B, budget *budgeting.Budget) {
+		a := budgeting.FirstAccount(budget)
+		c := budgeting.SomeCategory(budget)
+		month := budgeting.LatestMonth(budget)
+		tr, _ := a.AddTransaction(time.Date(2018, time.January, 2, 0, 0, 0, 0, time.UTC), decimal.New(-500, -2), "Expense", c, nil)
+
+		for i := 0; i < b.N; i++ {
+			tr.SetAmount(decimal.New(-int64(i), -2))
+			budget.TBB(month)
+			budget.Available(month, c)
+		}
+	})
+}
+
+func BenchmarkTransaction_SetCategory(b *testing.B) {
+	benchmarkWrite(b, func(b *testing.B, budget *budgeting.Budget) {
+		a := budgeting.FirstAccount(budget)
+		c := budgeting.SomeCategory(budget)
+		month := budgeting.LatestMonth(budget)
+		date := time.Date(month.Year, month.Month, 15, 0, 0, 0, 0, time.UTC)
+		tr, _ := a.AddTransaction(date, decimal.New(-500, -2), "Expense", nil, nil)
+
+		for i := 0; i < b.N; i++ {
+			if i%2 == 0 {
+				tr.SetCategory(c)
+			} else {
+				tr.SetCategory(nil)
+			}
+		}
+	})
+}
+
+func BenchmarkTransaction_SetDate(b *testing.B) {
+	benchmarkWrite(b, func(b *testing.B, budget *budgeting.Budget) {
+		a := budgeting.FirstAccount(budget)
+		c := budgeting.SomeCategory(budget)
+		month := budgeting.LatestMonth(budget)
+		date := time.Date(month.Year, month.Month, 15, 0, 0, 0, 0, time.UTC)
+		tr, _ := a.AddTransaction(date, decimal.New(-500, -2), "Expense", c, nil)
+
+		for i := 0; i < b.N; i++ {
+			tr.SetDate(date.AddDate(0, -(i % 12), 0))
+		}
+	})
+}
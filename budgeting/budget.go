@@ -1,20 +1,45 @@
 package budgeting
 
 import (
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/shopspring/decimal"
 )
 
-type Budget struct {
-	Name string
+var (
+	// ErrDuplicateID is returned when an entity is created with an ID which is
+	// already used within the budget.
+	ErrDuplicateID = fmt.Errorf("duplicate ID")
+
+	// ErrCannotBudgetTBB is returned when there is an attempt to set the
+	// budgeted amount of the "To Be Budgeted" category, whose budgeted amount
+	// is the TBB balance itself.
+	ErrCannotBudgetTBB = fmt.Errorf("cannot budget To Be Budgeted")
+)
 
+// Budget is the aggregate root of the domain. It is safe for concurrent use:
+// every method of a Budget and of the accounts, categories and transactions
+// belonging to it is guarded by a single read-write lock held by the budget.
+type Budget struct {
+	name          string
+	mu            sync.RWMutex
+	uuid          string
+	version       int64
 	earliestMonth YearMonth
 	latestMonth   YearMonth
 	tbb           *Category
 	categories    map[string]*Category
+	categoryOrder []*Category
 	accounts      []*Account
+	accountIndex  map[string]*Account
+	transactions  map[string]*Transaction
 	budgeted      map[YearMonth]monthBudget
+
+	monthCategoryIndex map[monthCategory][]*Transaction
+	summaries          *summaryCache
 }
 
 type monthBudget struct {
@@ -23,121 +48,229 @@ type monthBudget struct {
 }
 
 // NewBudget creates a fresh budget.
-func NewBudget(name string) *Budget {
-	b := &Budget{
-		Name: name,
+func NewBudget(name string, opts ...Option) *Budget {
+	o := newOptions(opts)
 
+	b := &Budget{
+		name:          name,
+		uuid:          idOrNew(o.id),
 		earliestMonth: YearMonth{999999, time.December},
 		latestMonth:   YearMonth{0, time.January},
 		categories:    map[string]*Category{},
+		categoryOrder: []*Category{},
 		accounts:      []*Account{},
+		accountIndex:  map[string]*Account{},
+		transactions:  map[string]*Transaction{},
 		budgeted:      map[YearMonth]monthBudget{},
+
+		monthCategoryIndex: map[monthCategory][]*Transaction{},
+		summaries:          newSummaryCache(),
 	}
 
-	tbb := b.AddCategory("To Be Budgeted")
-	b.tbb = tbb
+	b.tbb = b.addCategory(newCategory(idOrNew(o.tbbID), "To Be Budgeted", b))
 
 	return b
 }
 
-// AddAccount creates an account within the budget.
-func (b *Budget) AddAccount(name string, balance decimal.Decimal, date time.Time) *Account {
-	account, _ := newAccount(b, name, balance, date, b.tbb)
+// ID returns the budget's unique identifier.
+func (b *Budget) ID() string {
+	return b.uuid
+}
+
+// Name returns the name of the budget. It is set when the budget is
+// created and never changes, so it is read without the lock.
+func (b *Budget) Name() string {
+	return b.name
+}
+
+// Version returns a counter which is incremented on every mutation of the
+// budget made through its methods. It is used to detect concurrent
+// modifications when saving the budget.
+func (b *Budget) Version() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.version
+}
+
+// AddAccount creates an account within the budget. It returns ErrDuplicateID
+// if an ID given by WithID or WithStartingBalanceID is already used.
+func (b *Budget) AddAccount(name string, balance decimal.Decimal, date time.Time, opts ...Option) (*Account, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	o := newOptions(opts)
+	id := idOrNew(o.id)
+	if _, ok := b.accountIndex[id]; ok {
+		return nil, ErrDuplicateID
+	}
+
+	account, err := newAccount(b, id, name, balance, date, b.tbb, o.startingBalanceID)
+	if err != nil {
+		return nil, err
+	}
+
 	b.accounts = append(b.accounts, account)
-	return account
+	b.accountIndex[account.uuid] = account
+	return account, nil
 }
 
-// TBBCategory returns the "To Be Budgeted" category.
-func (b *Budget) TBBCategory() *Category {
-	return b.tbb.clone()
+// Account returns the account with the given ID.
+func (b *Budget) Account(id string) (*Account, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	a, ok := b.accountIndex[id]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	return a, nil
 }
 
-// TBB returns the "To Be Budgeted" balance for the specified month.
-func (b *Budget) TBB(month YearMonth) decimal.Decimal {
-	tbb := zero
-	var m YearMonth
+// Accounts returns the accounts of the budget in the order they were created.
+func (b *Budget) Accounts() []*Account {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 
-	m = month
-	for {
-		if b.earliestMonth.Earlier(m) {
-			break
-		}
+	accounts := make([]*Account, len(b.accounts))
+	copy(accounts, b.accounts)
+	return accounts
+}
 
-		transactions := b.monthCategoryTransactions(m, b.tbb)
-		for _, t := range transactions {
-			tbb = tbb.Add(t.Amount)
-		}
+// Category returns the category with the given ID.
+func (b *Budget) Category(id string) (*Category, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 
-		m = m.LastMonth()
+	c, ok := b.categories[id]
+	if !ok {
+		return nil, ErrCategoryNotFound
 	}
+	return c, nil
+}
 
-	m = month
-	for {
-		if b.earliestMonth.Earlier(m) {
-			break
-		}
+// Categories returns the categories of the budget in the order they were
+// created, starting with "To Be Budgeted".
+func (b *Budget) Categories() []*Category {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 
-		budgeted := monthBudget{
-			Month:    m,
-			Budgeted: map[string]decimal.Decimal{},
-		}
+	categories := make([]*Category, len(b.categoryOrder))
+	copy(categories, b.categoryOrder)
+	return categories
+}
 
-		if _, ok := b.budgeted[m]; ok {
-			budgeted = b.budgeted[m]
-		}
+// Transaction returns the transaction with the given ID.
+func (b *Budget) Transaction(id string) (*Transaction, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 
-		for _, v := range budgeted.Budgeted {
-			tbb = tbb.Sub(v)
-		}
+	t, ok := b.transactions[id]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	return t, nil
+}
 
-		m = m.LastMonth()
+// Transactions returns the transactions of every account, both sides of
+// transfers included, ordered by date. Transactions on the same date are
+// ordered by account and then by the order they were added in.
+func (b *Budget) Transactions() []*Transaction {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	transactions := []*Transaction{}
+	for _, a := range b.accounts {
+		transactions = append(transactions, a.transactions...)
 	}
 
-	if tbb.GreaterThan(zero) {
-		m = month.NextMonth()
-		for {
-			if b.latestMonth.Later(m) {
-				break
-			}
+	sortByDate(transactions)
+	return transactions
+}
 
-			budgeted := monthBudget{
-				Month:    m,
-				Budgeted: map[string]decimal.Decimal{},
-			}
+// Months returns the earliest and latest months with transactions or
+// budgeted amounts. ok is false if the budget has neither yet.
+func (b *Budget) Months() (earliest, latest YearMonth, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 
-			if _, ok := b.budgeted[m]; ok {
-				budgeted = b.budgeted[m]
-			}
+	if b.latestMonth.Year == 0 {
+		return YearMonth{}, YearMonth{}, false
+	}
+	return b.earliestMonth, b.latestMonth, true
+}
 
-			for _, v := range budgeted.Budgeted {
-				tbb = tbb.Sub(v)
-				if tbb.LessThan(zero) {
-					tbb = zero
-					break
-				}
-			}
+// TBBCategory returns the "To Be Budgeted" category.
+func (b *Budget) TBBCategory() *Category {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 
-			m = m.NextMonth()
+	return b.tbb.clone()
+}
+
+// TBB returns the "To Be Budgeted" balance for the specified month.
+func (b *Budget) TBB(month YearMonth) decimal.Decimal {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.toBeBudgeted(month)
+}
+
+func (b *Budget) toBeBudgeted(month YearMonth) decimal.Decimal {
+	tbb := b.tbbRunning(month)
+
+	// Money budgeted in later months can't come from anywhere but this
+	// month's TBB, as long as there is any left.
+	if tbb.GreaterThan(zero) {
+		for m := month.NextMonth(); !b.latestMonth.Later(m); m = m.NextMonth() {
+			tbb = tbb.Sub(b.budgetedTotal(m))
+			if tbb.LessThan(zero) {
+				tbb = zero
+			}
 		}
 	}
 
 	return tbb
 }
 
-// AddCategory creates a budgeting category.
-func (b *Budget) AddCategory(name string) *Category {
-	category := newCategory(name, b)
+// AddCategory creates a budgeting category. It returns ErrDuplicateID if the
+// ID given by WithID is already used.
+func (b *Budget) AddCategory(name string, opts ...Option) (*Category, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	o := newOptions(opts)
+	id := idOrNew(o.id)
+	if _, ok := b.categories[id]; ok {
+		return nil, ErrDuplicateID
+	}
+
+	category := b.addCategory(newCategory(id, name, b))
+	category.group = o.group
+	b.touch()
+	return category, nil
+}
+
+func (b *Budget) addCategory(category *Category) *Category {
 	b.categories[category.uuid] = category
+	b.categoryOrder = append(b.categoryOrder, category)
 	return category
 }
 
 // Activities returns how much money has been spent for the category on the specified month.
 func (b *Budget) Activities(month YearMonth, category *Category) decimal.Decimal {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.activities(month, category)
+}
+
+func (b *Budget) activities(month YearMonth, category *Category) decimal.Decimal {
 	activities := zero
 
 	transactions := b.monthCategoryTransactions(month, category)
 	for _, t := range transactions {
-		activities = activities.Add(t.Amount)
+		activities = activities.Add(t.amount)
 	}
 
 	return activities
@@ -145,36 +278,86 @@ func (b *Budget) Activities(month YearMonth, category *Category) decimal.Decimal
 
 // Available returns the available budget balance for the category on the specified month.
 func (b *Budget) Available(month YearMonth, category *Category) decimal.Decimal {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.available(month, category)
+}
+
+func (b *Budget) available(month YearMonth, category *Category) decimal.Decimal {
+	if b.earliestMonth.Earlier(month) {
+		return zero.Add(b.budgetedFor(month, category).Add(b.activities(month, category)))
+	}
+
+	// Walk back to the latest month with a cached balance (or to the start of
+	// the budget), then carry the balance forward, caching every month on the way.
+	pending := []YearMonth{}
 	available := zero
 
-	if !b.earliestMonth.Earlier(month) {
-		available = available.Add(b.Available(month.LastMonth(), category))
+	m := month
+	for {
+		if v, ok := b.summaries.getAvailable(category.uuid, m); ok {
+			available = v
+			break
+		}
+
+		pending = append(pending, m)
+		if b.earliestMonth.Equal(m) {
+			prev := m.LastMonth()
+			available = available.Add(b.budgetedFor(prev, category).Add(b.activities(prev, category)))
+			break
+		}
+		m = m.LastMonth()
 	}
 
-	available = available.Add(b.Budgeted(month, category).Add(b.Activities(month, category)))
+	for i := len(pending) - 1; i >= 0; i-- {
+		m := pending[i]
+		available = available.Add(b.budgetedFor(m, category).Add(b.activities(m, category)))
+
+		b.summaries.setAvailable(category.uuid, m, available)
+	}
 
 	return available
 }
 
 // Budgeted returns the budgeted amount for the category on the specified month.
 func (b *Budget) Budgeted(month YearMonth, category *Category) decimal.Decimal {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.budgetedFor(month, category)
+}
+
+func (b *Budget) budgetedFor(month YearMonth, category *Category) decimal.Decimal {
 	if _, ok := b.budgeted[month]; !ok {
 		return zero
 	}
 
 	if category.Equal(b.tbb) {
-		return b.TBB(month)
+		return b.toBeBudgeted(month)
 	}
 
 	return b.budgeted[month].Budgeted[category.uuid]
 }
 
 // SetBudgeted sets the budgeted amount for the category on the specified month.
-func (b *Budget) SetBudgeted(month YearMonth, category *Category, amount decimal.Decimal) {
+// It returns ErrCannotBudgetTBB for the "To Be Budgeted" category.
+func (b *Budget) SetBudgeted(month YearMonth, category *Category, amount decimal.Decimal) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.ownsCategory(category) {
+		return ErrForeignCategory
+	}
 	if category.Equal(b.tbb) {
-		return
+		return ErrCannotBudgetTBB
 	}
 
+	b.setBudgeted(month, category, amount)
+	return nil
+}
+
+func (b *Budget) setBudgeted(month YearMonth, category *Category, amount decimal.Decimal) {
 	if _, ok := b.budgeted[month]; !ok {
 		b.budgeted[month] = monthBudget{
 			Month:    month,
@@ -183,83 +366,118 @@ func (b *Budget) SetBudgeted(month YearMonth, category *Category, amount decimal
 	}
 
 	b.budgeted[month].Budgeted[category.uuid] = amount
-
-	if b.earliestMonth.Earlier(month) {
-		b.earliestMonth = month
-	}
-	if b.latestMonth.Later(month) {
-		b.latestMonth = month
-	}
+	b.invalidateBudgeted(month, category)
+	b.extendMonths(month)
+	b.touch()
 }
 
 // MoveBudgeted moves the budget balance from one category to another on the specified month.
-func (b *Budget) MoveBudgeted(month YearMonth, from *Category, to *Category, amount decimal.Decimal) {
+// Either category may be "To Be Budgeted", whose balance follows from the
+// amounts budgeted for the other categories.
+func (b *Budget) MoveBudgeted(month YearMonth, from *Category, to *Category, amount decimal.Decimal) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.ownsCategory(from) || !b.ownsCategory(to) {
+		return ErrForeignCategory
+	}
+
 	if _, ok := b.budgeted[month]; !ok {
 		b.budgeted[month] = monthBudget{
 			Month:    month,
 			Budgeted: map[string]decimal.Decimal{},
 		}
+
+		// The "To Be Budgeted" category only has a budgeted amount in months
+		// which have a budget.
+		b.summaries.invalidateTBB(b.tbb.uuid, month)
 	}
 
-	fromAmount := b.Budgeted(month, from)
-	toAmount := b.Budgeted(month, to)
+	fromAmount := b.budgetedFor(month, from)
+	toAmount := b.budgetedFor(month, to)
 
 	fromAmount = fromAmount.Sub(amount)
 	toAmount = toAmount.Add(amount)
 
-	b.SetBudgeted(month, from, fromAmount)
-	b.SetBudgeted(month, to, toAmount)
+	if !from.Equal(b.tbb) {
+		b.setBudgeted(month, from, fromAmount)
+	}
+	if !to.Equal(b.tbb) {
+		b.setBudgeted(month, to, toAmount)
+	}
+
+	b.touch()
+	return nil
 }
 
-func (b *Budget) setTransactionCategory(t *Transaction, c *Category) error {
-	if t.Type() == TransactionTypeTransfer {
-		return ErrCannotAssignCategoryToTransfer
+// sortByDate sorts transactions by date, keeping the existing order of
+// transactions on the same date.
+func sortByDate(transactions []*Transaction) {
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].date.Before(transactions[j].date)
+	})
+}
+
+// touch records a mutation of the budget.
+func (b *Budget) touch() {
+	b.version++
+}
+
+// extendMonths widens the range of months covered by the budget to include month.
+func (b *Budget) extendMonths(month YearMonth) {
+	if b.earliestMonth.Earlier(month) {
+		b.earliestMonth = month
+
+		// Running totals start from the earliest month.
+		b.summaries.reset()
 	}
+	if b.latestMonth.Later(month) {
+		b.latestMonth = month
+	}
+}
 
-	transactions := []*Transaction{}
+// ownsCategory reports whether the category belongs to the budget. The copy
+// of "To Be Budgeted" returned by TBBCategory is recognized by its ID.
+func (b *Budget) ownsCategory(c *Category) bool {
+	if c == nil {
+		return false
+	}
 
-	if t.Category() != nil {
-		transactions = t.account.transactionCategory[t.Category().uuid]
-		for i, tt := range transactions {
-			if tt.uuid == t.uuid {
-				transactions = append(transactions[:i], transactions[i+1:]...)
-				break
-			}
-		}
+	known, ok := b.categories[c.uuid]
+	return ok && (known == c || c.budget == nil)
+}
 
-		t.account.transactionCategory[t.Category().uuid] = transactions
+// ownsAccount reports whether the account belongs to the budget.
+func (b *Budget) ownsAccount(a *Account) bool {
+	if a == nil {
+		return false
 	}
 
-	if c != nil {
-		if _, ok := t.account.transactionCategory[c.uuid]; !ok {
-			t.account.transactionCategory[c.uuid] = []*Transaction{}
-		}
+	known, ok := b.accountIndex[a.uuid]
+	return ok && known == a
+}
 
-		t.account.transactionCategory[c.uuid] = append(t.account.transactionCategory[c.uuid], t)
+func (b *Budget) setTransactionCategory(t *Transaction, c *Category) error {
+	if c != nil && t.Type() == TransactionTypeTransfer {
+		return ErrCannotAssignCategoryToTransfer
+	}
+	if c != nil && !b.ownsCategory(c) {
+		return ErrForeignCategory
+	}
+	if c != nil {
+		// Store the budget's own category rather than a copy of it.
+		c = b.categories[c.uuid]
 	}
 
+	b.unindexTransaction(t)
 	t.category = c
+	b.indexTransaction(t)
+
 	return nil
 }
 
 func (b *Budget) monthCategoryTransactions(month YearMonth, c *Category) []*Transaction {
-	allTransactions := []*Transaction{}
-
-	// TODO: Optimize this by using lookup table
-	for _, a := range b.accounts {
-		transactions := []*Transaction{}
-		if _, ok := a.transactionCategory[c.uuid]; ok {
-			transactions = a.transactionCategory[c.uuid]
-		}
-
-		for _, t := range transactions {
-			if YearMonthFromTime(t.Date).Equal(month) {
-				allTransactions = append(allTransactions, t)
-			}
-		}
-	}
-
-	return allTransactions
+	return b.monthCategoryIndex[monthCategory{month, c.uuid}]
 }
 
 // YearMonth is a helper struct for representing a month of a year
@@ -355,3 +573,34 @@ func (m YearMonth) LaterTime(t time.Time) bool {
 func (m YearMonth) Equal(other YearMonth) bool {
 	return m.Year == other.Year && m.Month == other.Month
 }
+
+// String returns the month formatted as YYYY-MM (e.g. 2018-05).
+func (m YearMonth) String() string {
+	return fmt.Sprintf("%04d-%02d", m.Year, int(m.Month))
+}
+
+// ParseYearMonth parses a month formatted as YYYY-MM.
+func ParseYearMonth(s string) (YearMonth, error) {
+	t, err := time.Parse("2006-01", s)
+	if err != nil {
+		return YearMonth{}, fmt.Errorf("invalid month %q: expected YYYY-MM", s)
+	}
+
+	return YearMonthFromTime(t), nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m YearMonth) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *YearMonth) UnmarshalText(text []byte) error {
+	parsed, err := ParseYearMonth(string(text))
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
@@ -13,8 +13,8 @@ func TestBudget_NewBudget(t *testing.T) {
 	budget := NewBudget("My Budget")
 	jan := YearMonth{2018, time.January}
 
-	food := budget.AddCategory("Food & Beverages")
-	bills := budget.AddCategory("Bills")
+	food, _ := budget.AddCategory("Food & Beverages")
+	bills, _ := budget.AddCategory("Bills")
 
 	assert.True(budget.Budgeted(jan, food).Equal(dec("0.00")))
 	assert.True(budget.Budgeted(jan, bills).Equal(dec("0.00")))
@@ -31,8 +31,8 @@ func TestBudget_SetBudgeted(t *testing.T) {
 	assert.Equal(dec("100.00").StringFixed(2), budget.TBB(jan).StringFixed(2))
 	assert.Equal(dec("100.00").StringFixed(2), budget.TBB(feb).StringFixed(2)) // TBB should carry over
 
-	food := budget.AddCategory("Food & Beverages")
-	bills := budget.AddCategory("Bills")
+	food, _ := budget.AddCategory("Food & Beverages")
+	bills, _ := budget.AddCategory("Bills")
 
 	budget.SetBudgeted(jan, food, dec("50.00"))
 	budget.SetBudgeted(jan, bills, dec("12.34"))
@@ -53,8 +53,8 @@ func TestBudget_MoveBudgeted(t *testing.T) {
 	jan := YearMonth{2018, time.January}
 	feb := YearMonth{2018, time.February}
 
-	food := budget.AddCategory("Food & Beverages")
-	bills := budget.AddCategory("Bills")
+	food, _ := budget.AddCategory("Food & Beverages")
+	bills, _ := budget.AddCategory("Bills")
 
 	assert.True(budget.TBB(jan).Equal(dec("0.00")))
 	assert.True(budget.TBB(feb).Equal(dec("0.00")))
@@ -88,8 +88,8 @@ func TestBudget_MoveTBB(t *testing.T) {
 	budget := NewBudget("My Budget")
 	jan := YearMonth{2018, time.January}
 
-	food := budget.AddCategory("Food & Beverages")
-	bills := budget.AddCategory("Bills")
+	food, _ := budget.AddCategory("Food & Beverages")
+	bills, _ := budget.AddCategory("Bills")
 
 	budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
 
@@ -109,9 +109,9 @@ func TestBudget_TBB(t *testing.T) {
 	oct := YearMonth{2018, time.October}
 	nov := YearMonth{2018, time.November}
 
-	food := budget.AddCategory("Food & Beverages")
+	food, _ := budget.AddCategory("Food & Beverages")
 
-	acc := budget.AddAccount("Savings", dec("10.00"), date(2018, 8, 1))
+	acc, _ := budget.AddAccount("Savings", dec("10.00"), date(2018, 8, 1))
 	acc.AddTransaction(date(2018, 10, 1), dec("100.00"), "test", budget.TBBCategory(), nil)
 
 	assert.Equal(dec("10.00").StringFixed(2), budget.TBB(aug).StringFixed(2))
@@ -156,8 +156,8 @@ func TestBudget_MoveBudgetedEmpty(t *testing.T) {
 	budget := NewBudget("My Budget")
 	jan := YearMonth{2018, time.January}
 
-	food := budget.AddCategory("Food & Beverages")
-	bills := budget.AddCategory("Bills")
+	food, _ := budget.AddCategory("Food & Beverages")
+	bills, _ := budget.AddCategory("Bills")
 
 	budget.MoveBudgeted(jan, food, bills, dec("10.00"))
 	assert.True(budget.Budgeted(jan, food).Equal(dec("-10.00")))
@@ -173,9 +173,9 @@ func TestBudget_Activities(t *testing.T) {
 	feb := YearMonth{2018, time.February}
 	mar := YearMonth{2018, time.March}
 
-	acc := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
-	food := budget.AddCategory("Food")
-	bills := budget.AddCategory("Bills")
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food")
+	bills, _ := budget.AddCategory("Bills")
 
 	budget.SetBudgeted(jan, food, dec("50.00"))
 	budget.SetBudgeted(jan, bills, dec("50.00"))
@@ -248,9 +248,9 @@ func TestBudget_SetCategory(t *testing.T) {
 	budget := NewBudget("My Budget")
 	jan := YearMonth{2018, time.January}
 
-	acc := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
-	food := budget.AddCategory("Food")
-	bills := budget.AddCategory("Bills")
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food")
+	bills, _ := budget.AddCategory("Bills")
 
 	budget.SetBudgeted(jan, food, dec("50.00"))
 	budget.SetBudgeted(jan, bills, dec("50.00"))
@@ -294,10 +294,10 @@ func TestBudget_SetCategoryTransfer(t *testing.T) {
 	budget := NewBudget("My Budget")
 	jan := YearMonth{2018, time.January}
 
-	acc := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
-	wallet := budget.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
-	food := budget.AddCategory("Food")
-	bills := budget.AddCategory("Bills")
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	wallet, _ := budget.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food")
+	bills, _ := budget.AddCategory("Bills")
 
 	budget.SetBudgeted(jan, food, dec("50.00"))
 	budget.SetBudgeted(jan, bills, dec("50.00"))
@@ -324,3 +324,148 @@ func TestBudget_SetCategoryTransfer(t *testing.T) {
 	assert.Equal(dec("0.00").StringFixed(2), bills.Activities(jan).StringFixed(2))
 	assert.Equal(dec("50.00").StringFixed(2), bills.Available(jan).StringFixed(2))
 }
+
+func TestBudget_Version(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	jan := YearMonth{2018, time.January}
+	assert.EqualValues(0, budget.Version())
+
+	var v int64
+	mutated := func() bool {
+		changed := budget.Version() > v
+		v = budget.Version()
+		return changed
+	}
+
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	assert.True(mutated())
+
+	food, _ := budget.AddCategory("Food")
+	assert.True(mutated())
+
+	tr, _ := acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", food, nil)
+	assert.True(mutated())
+
+	tr.SetCategory(nil)
+	assert.True(mutated())
+
+	budget.SetBudgeted(jan, food, dec("10.00"))
+	assert.True(mutated())
+
+	budget.MoveBudgeted(jan, budget.TBBCategory(), food, dec("10.00"))
+	assert.True(mutated())
+
+	budget.TBB(jan)
+	food.Available(jan)
+	acc.Balance()
+	assert.False(mutated())
+}
+
+func TestBudget_Lookups(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	wallet, _ := budget.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food")
+	bills, _ := budget.AddCategory("Bills")
+
+	later, _ := acc.AddTransaction(date(2018, 2, 1), dec("-5.00"), "later", food, nil)
+	withdraw, _ := acc.AddTransaction(date(2018, 1, 3), dec("-20.00"), "withdraw", nil, wallet)
+
+	a, err := budget.Account(wallet.ID())
+	assert.Nil(err)
+	assert.True(a == wallet)
+	_, err = budget.Account("missing")
+	assert.Equal(ErrAccountNotFound, err)
+
+	c, err := budget.Category(bills.ID())
+	assert.Nil(err)
+	assert.True(c == bills)
+	_, err = budget.Category("missing")
+	assert.Equal(ErrCategoryNotFound, err)
+
+	tr, err := budget.Transaction(later.ID())
+	assert.Nil(err)
+	assert.True(tr == later)
+	_, err = budget.Transaction("missing")
+	assert.Equal(ErrTransactionNotFound, err)
+
+	assert.Equal([]*Account{acc, wallet}, budget.Accounts())
+
+	categories := budget.Categories()
+	assert.Len(categories, 3)
+	assert.True(categories[0].Equal(budget.TBBCategory()))
+	assert.True(categories[1] == food)
+	assert.True(categories[2] == bills)
+
+	accTransactions := acc.Transactions()
+	assert.Len(accTransactions, 3)
+	assert.True(accTransactions[1] == withdraw)
+	assert.True(accTransactions[2] == later)
+
+	all := budget.Transactions()
+	assert.Len(all, 5)
+	assert.True(all[2] == withdraw)
+	assert.True(all[3].Account() == wallet)
+	assert.True(all[4] == later)
+
+	assert.Nil(acc.DeleteTransaction(withdraw))
+	_, err = budget.Transaction(withdraw.ID())
+	assert.Equal(ErrTransactionNotFound, err)
+	assert.Len(budget.Transactions(), 3)
+}
+
+func TestBudget_SetBudgetedTBB(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	jan := YearMonth{2018, time.January}
+	budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+
+	assert.Equal(ErrCannotBudgetTBB, budget.SetBudgeted(jan, budget.TBBCategory(), dec("10.00")))
+	assert.True(budget.TBB(jan).Equal(dec("100.00")))
+}
+
+func TestBudget_ForeignCategory(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	other := NewBudget("Other Budget")
+	jan := YearMonth{2018, time.January}
+
+	food, _ := budget.AddCategory("Food")
+	rent, _ := other.AddCategory("Rent")
+	lookalike, _ := other.AddCategory("Food", WithID(food.ID()))
+
+	assert.Equal(ErrForeignCategory, budget.SetBudgeted(jan, rent, dec("10.00")))
+	assert.Equal(ErrForeignCategory, budget.SetBudgeted(jan, lookalike, dec("10.00")))
+	assert.Equal(ErrForeignCategory, budget.SetBudgeted(jan, nil, dec("10.00")))
+	assert.Equal(ErrForeignCategory, budget.MoveBudgeted(jan, food, rent, dec("10.00")))
+	assert.Equal(ErrForeignCategory, budget.MoveBudgeted(jan, other.TBBCategory(), food, dec("10.00")))
+	assert.True(budget.Budgeted(jan, food).Equal(zero))
+
+	assert.Nil(budget.SetBudgeted(jan, food, dec("10.00")))
+	assert.Nil(budget.MoveBudgeted(jan, food, budget.TBBCategory(), dec("4.00")))
+	assert.True(budget.Budgeted(jan, food).Equal(dec("6.00")))
+}
+
+func TestBudget_Months(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	_, _, ok := budget.Months()
+	assert.False(ok)
+
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 2, 1))
+	food, _ := budget.AddCategory("Food")
+	acc.AddTransaction(date(2018, 3, 2), dec("-5.00"), "lunch", food, nil)
+	budget.SetBudgeted(YearMonth{2018, time.January}, food, dec("10.00"))
+
+	earliest, latest, ok := budget.Months()
+	assert.True(ok)
+	assert.Equal(YearMonth{2018, time.January}, earliest)
+	assert.Equal(YearMonth{2018, time.March}, latest)
+}
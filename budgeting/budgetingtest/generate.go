@@ -0,0 +1,145 @@
+// Package budgetingtest provides utilities for testing and benchmarking code
+// which uses the budgeting package.
+package budgetingtest
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+)
+
+// Config describes the shape of a synthetic budget.
+type Config struct {
+	// Seed makes the generated budget reproducible: the same config always
+	// produces the same accounts, categories, transactions and budgeted
+	// amounts, IDs included.
+	Seed int64
+
+	Accounts   int
+	Categories int
+
+	// Start is the date of the first transaction. Years of history follow it.
+	Start time.Time
+	Years int
+
+	// TransactionsPerMonth is the number of transactions generated for each
+	// account in each month, including income and transfers.
+	TransactionsPerMonth int
+
+	// TransferRatio is the fraction of transactions which are transfers to
+	// another account.
+	TransferRatio float64
+
+	// Budgeted controls whether every category gets an amount budgeted
+	// every month.
+	Budgeted bool
+}
+
+// DefaultConfig returns the shape of a typical household budget: a few
+// accounts, a couple dozen categories and a year of history.
+func DefaultConfig() Config {
+	return Config{
+		Seed:                 1,
+		Accounts:             4,
+		Categories:           20,
+		Start:                time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Years:                1,
+		TransactionsPerMonth: 40,
+		TransferRatio:        0.05,
+		Budgeted:             true,
+	}
+}
+
+// Transactions returns the number of transactions Generate will create for
+// the config, not counting the other side of transfers.
+func (c Config) Transactions() int {
+	return c.Accounts * (1 + c.Years*12*c.TransactionsPerMonth)
+}
+
+// Generate creates a synthetic budget.
+func Generate(c Config) *budgeting.Budget {
+	rnd := rand.New(rand.NewSource(c.Seed))
+
+	n := 0
+	id := func(kind string) budgeting.Option {
+		n++
+		return budgeting.WithID(fmt.Sprintf("%s-%d", kind, n))
+	}
+	pair := func() budgeting.Option {
+		n++
+		return budgeting.WithPairID(fmt.Sprintf("transaction-%d", n))
+	}
+
+	b := budgeting.NewBudget(fmt.Sprintf("Synthetic budget %d", c.Seed),
+		budgeting.WithID(fmt.Sprintf("budget-%d", c.Seed)), budgeting.WithTBBCategoryID("category-tbb"))
+	tbb := b.TBBCategory()
+
+	accounts := []*budgeting.Account{}
+	for i := 0; i < c.Accounts; i++ {
+		balance := amount(rnd, 0, 500000)
+		a, err := b.AddAccount(fmt.Sprintf("Account %d", i+1), balance, c.Start,
+			id("account"), budgeting.WithStartingBalanceID(fmt.Sprintf("transaction-start-%d", i+1)))
+		if err != nil {
+			panic(err)
+		}
+		accounts = append(accounts, a)
+	}
+
+	categories := []*budgeting.Category{}
+	for i := 0; i < c.Categories; i++ {
+		category, err := b.AddCategory(fmt.Sprintf("Category %d", i+1), id("category"))
+		if err != nil {
+			panic(err)
+		}
+		categories = append(categories, category)
+	}
+
+	month := budgeting.YearMonthFromTime(c.Start)
+	for i := 0; i < c.Years*12; i++ {
+		first := time.Date(month.Year, month.Month, 1, 0, 0, 0, 0, time.UTC)
+		days := first.AddDate(0, 1, -1).Day()
+
+		if c.Budgeted && len(categories) > 0 {
+			for _, category := range categories {
+				if err := b.SetBudgeted(month, category, amount(rnd, 1000, 40000)); err != nil {
+					panic(err)
+				}
+			}
+		}
+
+		for ai, a := range accounts {
+			for j := 0; j < c.TransactionsPerMonth; j++ {
+				date := first.AddDate(0, 0, rnd.Intn(days))
+
+				var err error
+				switch {
+				case j == 0:
+					_, err = a.AddTransaction(first, amount(rnd, 100000, 600000), "Income", tbb, nil, id("transaction"))
+				case len(accounts) > 1 && rnd.Float64() < c.TransferRatio:
+					other := accounts[(ai+1+rnd.Intn(len(accounts)-1))%len(accounts)]
+					_, err = a.AddTransaction(date, amount(rnd, -20000, -1000), "Transfer", nil, other, id("transaction"), pair())
+				case len(categories) > 0:
+					category := categories[rnd.Intn(len(categories))]
+					_, err = a.AddTransaction(date, amount(rnd, -15000, -100), "Expense", category, nil, id("transaction"))
+				default:
+					_, err = a.AddTransaction(date, amount(rnd, -15000, -100), "Expense", nil, nil, id("transaction"))
+				}
+				if err != nil {
+					panic(err)
+				}
+			}
+		}
+
+		month = month.NextMonth()
+	}
+
+	return b
+}
+
+// amount returns a random amount of cents in [min, max).
+func amount(rnd *rand.Rand, min, max int64) decimal.Decimal {
+	return decimal.New(min+rnd.Int63n(max-min), -2)
+}
@@ -0,0 +1,62 @@
+package budgetingtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate_Deterministic(t *testing.T) {
+	assert := assert.New(t)
+
+	c := DefaultConfig()
+	b1 := Generate(c)
+	b2 := Generate(c)
+
+	for m, i := budgeting.YearMonthFromTime(c.Start), 0; i < 12; m, i = m.NextMonth(), i+1 {
+		assert.Equal(b1.TBB(m).String(), b2.TBB(m).String())
+	}
+
+	t1, t2 := b1.Transactions(), b2.Transactions()
+	assert.Equal(b1.ID(), b2.ID())
+	assert.Equal(len(t1), len(t2))
+	for i := range t1 {
+		assert.Equal(t1[i].ID(), t2[i].ID())
+	}
+
+	c.Seed = 2
+	b3 := Generate(c)
+	dec := budgeting.YearMonth{Year: 2018, Month: time.December}
+	assert.NotEqual(b1.TBB(dec).String(), b3.TBB(dec).String())
+}
+
+func TestGenerate_Shape(t *testing.T) {
+	assert := assert.New(t)
+
+	c := Config{
+		Seed:                 3,
+		Accounts:             1,
+		Categories:           0,
+		Start:                time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Years:                2,
+		TransactionsPerMonth: 1,
+	}
+	assert.Equal(25, c.Transactions())
+
+	b := Generate(c)
+
+	// With a single transaction per month, every transaction is income.
+	last := budgeting.YearMonth{Year: 2020, Month: time.February}
+	after := budgeting.YearMonth{Year: 2020, Month: time.March}
+	assert.True(b.TBB(last).GreaterThan(b.TBB(last.LastMonth())))
+	assert.Equal(b.TBB(last).String(), b.TBB(after).String())
+}
+
+func TestGenerate_Verify(t *testing.T) {
+	c := DefaultConfig()
+	c.TransferRatio = 0.3
+
+	assert.Empty(t, Generate(c).Verify())
+}
@@ -1,25 +1,53 @@
 package budgeting
 
 import (
-	uuid "github.com/satori/go.uuid"
+	"fmt"
+
 	"github.com/shopspring/decimal"
 )
 
-type Category struct {
-	Name string
+var (
+	// ErrCategoryNotFound is returned when a category doesn't belong to the budget.
+	ErrCategoryNotFound = fmt.Errorf("category not found")
+
+	// ErrForeignCategory is returned when a category of another budget is
+	// passed to a budget.
+	ErrForeignCategory = fmt.Errorf("category belongs to another budget")
+)
 
+type Category struct {
+	name   string
+	group  string
 	uuid   string
 	budget *Budget
 }
 
-func newCategory(name string, budget *Budget) *Category {
+func newCategory(id string, name string, budget *Budget) *Category {
 	return &Category{
-		Name:   name,
-		uuid:   uuid.NewV4().String(),
+		name:   name,
+		uuid:   id,
 		budget: budget,
 	}
 }
 
+// ID returns the category's unique identifier.
+func (c *Category) ID() string {
+	return c.uuid
+}
+
+// Name returns the name of the category. It is set when the category is
+// created and never changes, so it is read without the budget's lock.
+func (c *Category) Name() string {
+	return c.name
+}
+
+// Group returns the name of the group the category is listed under, like
+// YNAB's master categories, or "" if it isn't in one. Like the name, it is
+// set when the category is created, with WithGroup.
+func (c *Category) Group() string {
+	return c.group
+}
+
 func (c *Category) Budgeted(month YearMonth) decimal.Decimal {
 	return c.budget.Budgeted(month, c)
 }
@@ -42,7 +70,8 @@ func (c *Category) Equal(other *Category) bool {
 
 func (c *Category) clone() *Category {
 	return &Category{
-		Name: c.Name,
-		uuid: c.uuid,
+		name:  c.name,
+		group: c.group,
+		uuid:  c.uuid,
 	}
 }
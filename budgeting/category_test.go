@@ -11,8 +11,8 @@ func TestCategory_Equal(t *testing.T) {
 
 	b := NewBudget("My Budget")
 
-	c1 := b.AddCategory("food")
-	c2 := b.AddCategory("bills")
+	c1, _ := b.AddCategory("food")
+	c2, _ := b.AddCategory("bills")
 
 	assert.True(c1.Equal(c1))
 	assert.True(c2.Equal(c2))
@@ -20,11 +20,11 @@ func TestCategory_Equal(t *testing.T) {
 	assert.False(c1.Equal(nil))
 	assert.False(c2.Equal(nil))
 
-	c2.Name = "food"
+	c2.name = "food"
 	assert.False(c1.Equal(c2))
 
 	c3 := c2
-	c3.Name = "entertainment"
+	c3.name = "entertainment"
 	assert.True(c2.Equal(c3))
-	assert.EqualValues("entertainment", c2.Name)
+	assert.EqualValues("entertainment", c2.Name())
 }
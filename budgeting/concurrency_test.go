@@ -0,0 +1,117 @@
+package budgeting
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// These tests are meant to be run with the race detector (go test -race).
+
+func TestBudget_ConcurrentReadersAndWriters(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	jan := YearMonth{2018, time.January}
+	feb := YearMonth{2018, time.February}
+
+	acc, _ := budget.AddAccount("Savings", dec("1000.00"), date(2018, 1, 1))
+	wallet, _ := budget.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food")
+	bills, _ := budget.AddCategory("Bills")
+
+	const n = 100
+	var wg sync.WaitGroup
+
+	run := func(f func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				f(i)
+			}
+		}()
+	}
+
+	run(func(i int) {
+		acc.AddTransaction(date(2018, 1+i%2, 2), dec("-1.00"), "lunch", food, nil)
+	})
+	run(func(i int) {
+		wallet.AddTransaction(date(2018, 2, 3), dec("-0.50"), "withdraw", nil, acc)
+	})
+	run(func(i int) {
+		budget.SetBudgeted(jan, food, dec("100.00"))
+		budget.MoveBudgeted(feb, budget.TBBCategory(), bills, dec("1.00"))
+	})
+	run(func(i int) {
+		budget.AddCategory("Category")
+	})
+	run(func(i int) {
+		budget.TBB(feb)
+		food.Available(feb)
+		bills.Activities(jan)
+		acc.Balance()
+		budget.Version()
+	})
+	run(func(i int) {
+		if _, err := json.Marshal(budget); err != nil {
+			t.Error(err)
+		}
+	})
+
+	wg.Wait()
+
+	assert.Equal(dec("950.00").StringFixed(2), acc.Balance().StringFixed(2))
+	assert.Equal(dec("-50.00").StringFixed(2), wallet.Balance().StringFixed(2))
+	assert.Equal(dec("-100.00").StringFixed(2), food.Activities(jan).Add(food.Activities(feb)).StringFixed(2))
+	assert.Equal(dec("100.00").StringFixed(2), bills.Budgeted(feb).StringFixed(2))
+	assert.Equal(dec("800.00").StringFixed(2), budget.TBB(feb).StringFixed(2))
+}
+
+func TestTransaction_ConcurrentSetCategory(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	jan := YearMonth{2018, time.January}
+
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food")
+	bills, _ := budget.AddCategory("Bills")
+	tr, _ := acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", food, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if (i+j)%2 == 0 {
+					tr.SetCategory(food)
+				} else {
+					tr.SetCategory(bills)
+				}
+				tr.Category()
+				food.Activities(jan)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	total := food.Activities(jan).Add(bills.Activities(jan))
+	assert.Equal(dec("-5.00").StringFixed(2), total.StringFixed(2))
+}
+
+func TestAccount_BalanceDoesNotReorder(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 2, 1))
+	acc.AddTransaction(date(2018, 1, 1), dec("-5.00"), "earlier", nil, nil)
+
+	assert.Equal(dec("95.00").StringFixed(2), acc.Balance().StringFixed(2))
+	assert.Equal("Starting balance", acc.transactions[0].Description())
+	assert.Equal("earlier", acc.transactions[1].Description())
+}
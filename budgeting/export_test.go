@@ -0,0 +1,52 @@
+package budgeting
+
+import "github.com/shopspring/decimal"
+
+// LinearScanActivities computes Activities the way it was done before the
+// month/category index existed, by scanning every account's transactions in
+// the category. Benchmarks use it as a baseline for the index.
+func LinearScanActivities(b *Budget, month YearMonth, c *Category) decimal.Decimal {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	activities := zero
+	for _, a := range b.accounts {
+		for _, t := range a.transactionCategory[c.uuid] {
+			if YearMonthFromTime(t.date).Equal(month) {
+				activities = activities.Add(t.amount)
+			}
+		}
+	}
+
+	return activities
+}
+
+// FirstAccount returns the first account of the budget.
+func FirstAccount(b *Budget) *Account {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.accounts[0]
+}
+
+// SomeCategory returns the first category of the budget other than "To Be
+// Budgeted", so that benchmarks use the same one on every run.
+func SomeCategory(b *Budget) *Category {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, c := range b.categoryOrder {
+		if !c.Equal(b.tbb) {
+			return c
+		}
+	}
+	return nil
+}
+
+// LatestMonth returns the last month covered by the budget.
+func LatestMonth(b *Budget) YearMonth {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.latestMonth
+}
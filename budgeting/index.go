@@ -0,0 +1,57 @@
+package budgeting
+
+// monthCategory identifies the transactions of a category within a month.
+type monthCategory struct {
+	month    YearMonth
+	category string
+}
+
+// indexTransaction adds a categorized transaction to the lookup tables used to
+// find the transactions of a category: the per-account transactionCategory
+// and the budget-wide monthCategoryIndex.
+func (b *Budget) indexTransaction(t *Transaction) {
+	if t.category == nil {
+		return
+	}
+
+	b.invalidateTransaction(t)
+
+	c := t.category.uuid
+	t.account.transactionCategory[c] = append(t.account.transactionCategory[c], t)
+
+	key := monthCategory{YearMonthFromTime(t.date), c}
+	b.monthCategoryIndex[key] = append(b.monthCategoryIndex[key], t)
+}
+
+// unindexTransaction removes a transaction from the lookup tables. It must be
+// called before changing anything the tables are keyed on (i.e. the category
+// or the date).
+func (b *Budget) unindexTransaction(t *Transaction) {
+	if t.category == nil {
+		return
+	}
+
+	b.invalidateTransaction(t)
+
+	c := t.category.uuid
+	t.account.transactionCategory[c] = removeTransaction(t.account.transactionCategory[c], t)
+	if len(t.account.transactionCategory[c]) == 0 {
+		delete(t.account.transactionCategory, c)
+	}
+
+	key := monthCategory{YearMonthFromTime(t.date), c}
+	b.monthCategoryIndex[key] = removeTransaction(b.monthCategoryIndex[key], t)
+	if len(b.monthCategoryIndex[key]) == 0 {
+		delete(b.monthCategoryIndex, key)
+	}
+}
+
+func removeTransaction(transactions []*Transaction, t *Transaction) []*Transaction {
+	for i, tt := range transactions {
+		if tt.uuid == t.uuid {
+			return append(transactions[:i], transactions[i+1:]...)
+		}
+	}
+
+	return transactions
+}
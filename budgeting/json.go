@@ -0,0 +1,281 @@
+package budgeting
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// budgetJSON is the serialized form of a Budget.
+type budgetJSON struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Version    int64             `json:"version"`
+	TBB        string            `json:"tbb"`
+	Categories []categoryJSON    `json:"categories"`
+	Accounts   []accountJSON     `json:"accounts"`
+	Budgeted   []monthBudgetJSON `json:"budgeted"`
+}
+
+type categoryJSON struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Group string `json:"group,omitempty"`
+}
+
+type accountJSON struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Closed          bool              `json:"closed"`
+	StartingBalance string            `json:"starting_balance,omitempty"`
+	Transactions    []transactionJSON `json:"transactions"`
+}
+
+type transactionJSON struct {
+	ID          string          `json:"id"`
+	Date        time.Time       `json:"date"`
+	Description string          `json:"description"`
+	Amount      decimal.Decimal `json:"amount"`
+	ImportID    string          `json:"import_id,omitempty"`
+	Cleared     bool            `json:"cleared,omitempty"`
+	Unapproved  bool            `json:"unapproved,omitempty"`
+	Match       *matchJSON      `json:"match,omitempty"`
+	Category    string          `json:"category,omitempty"`
+	Rel         string          `json:"rel,omitempty"`
+	Pair        string          `json:"pair,omitempty"`
+}
+
+type matchJSON struct {
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+}
+
+type monthBudgetJSON struct {
+	Month    YearMonth                  `json:"month"`
+	Budgeted map[string]decimal.Decimal `json:"budgeted"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b *Budget) MarshalJSON() ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	doc := budgetJSON{
+		ID:         b.uuid,
+		Name:       b.name,
+		Version:    b.version,
+		TBB:        b.tbb.uuid,
+		Categories: []categoryJSON{},
+		Accounts:   []accountJSON{},
+		Budgeted:   []monthBudgetJSON{},
+	}
+
+	for _, c := range b.categoryOrder {
+		doc.Categories = append(doc.Categories, categoryJSON{
+			ID:    c.uuid,
+			Name:  c.name,
+			Group: c.group,
+		})
+	}
+
+	for _, a := range b.accounts {
+		aj := accountJSON{
+			ID:           a.uuid,
+			Name:         a.name,
+			Closed:       a.closed,
+			Transactions: []transactionJSON{},
+		}
+		if t := a.startingBalance(); t != nil {
+			aj.StartingBalance = t.uuid
+		}
+
+		for _, t := range a.transactions {
+			tj := transactionJSON{
+				ID:          t.uuid,
+				Date:        t.date,
+				Description: t.description,
+				Amount:      t.amount,
+				ImportID:    t.importID,
+				Cleared:     t.cleared,
+				Unapproved:  t.unapproved,
+			}
+
+			if t.match != nil {
+				tj.Match = &matchJSON{Date: t.match.date, Description: t.match.description}
+			}
+			if t.category != nil {
+				tj.Category = t.category.uuid
+			}
+			if t.rel != nil {
+				if _, ok := b.accountIndex[t.rel.uuid]; !ok {
+					return nil, fmt.Errorf("transaction %s is a transfer to an account outside the budget", t.uuid)
+				}
+				tj.Rel = t.rel.uuid
+			}
+			if t.pair != nil {
+				tj.Pair = t.pair.uuid
+			}
+
+			aj.Transactions = append(aj.Transactions, tj)
+		}
+
+		doc.Accounts = append(doc.Accounts, aj)
+	}
+
+	for month, mb := range b.budgeted {
+		doc.Budgeted = append(doc.Budgeted, monthBudgetJSON{
+			Month:    month,
+			Budgeted: mb.Budgeted,
+		})
+	}
+	sort.Slice(doc.Budgeted, func(i, j int) bool {
+		return doc.Budgeted[j].Month.Earlier(doc.Budgeted[i].Month)
+	})
+
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Any existing content of the
+// budget is replaced.
+func (b *Budget) UnmarshalJSON(data []byte) error {
+	var doc budgetJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.name = doc.Name
+	b.uuid = doc.ID
+	b.version = doc.Version
+	b.earliestMonth = YearMonth{999999, time.December}
+	b.latestMonth = YearMonth{0, time.January}
+	b.tbb = nil
+	b.categories = map[string]*Category{}
+	b.categoryOrder = []*Category{}
+	b.accounts = []*Account{}
+	b.accountIndex = map[string]*Account{}
+	b.transactions = map[string]*Transaction{}
+	b.budgeted = map[YearMonth]monthBudget{}
+	b.monthCategoryIndex = map[monthCategory][]*Transaction{}
+	b.summaries = newSummaryCache()
+
+	for _, cj := range doc.Categories {
+		if _, ok := b.categories[cj.ID]; ok {
+			return fmt.Errorf("duplicate category %q", cj.ID)
+		}
+		c := b.addCategory(newCategory(cj.ID, cj.Name, b))
+		c.group = cj.Group
+	}
+
+	tbb, ok := b.categories[doc.TBB]
+	if !ok {
+		return fmt.Errorf("unknown To Be Budgeted category %q", doc.TBB)
+	}
+	b.tbb = tbb
+
+	for _, aj := range doc.Accounts {
+		if _, ok := b.accountIndex[aj.ID]; ok {
+			return fmt.Errorf("duplicate account %q", aj.ID)
+		}
+
+		a := &Account{
+			name:                aj.Name,
+			uuid:                aj.ID,
+			budget:              b,
+			transactions:        []*Transaction{},
+			transactionCategory: map[string][]*Transaction{},
+			closed:              aj.Closed,
+
+			startingBalanceID: aj.StartingBalance,
+		}
+
+		b.accounts = append(b.accounts, a)
+		b.accountIndex[a.uuid] = a
+	}
+
+	pairs := map[*Transaction]string{}
+
+	for i, aj := range doc.Accounts {
+		a := b.accounts[i]
+
+		for _, tj := range aj.Transactions {
+			if _, ok := b.transactions[tj.ID]; ok {
+				return fmt.Errorf("duplicate transaction %q", tj.ID)
+			}
+
+			t := &Transaction{
+				date:        tj.Date,
+				description: tj.Description,
+				amount:      tj.Amount,
+				importID:    tj.ImportID,
+				cleared:     tj.Cleared,
+				unapproved:  tj.Unapproved,
+
+				uuid:    tj.ID,
+				budget:  b,
+				account: a,
+			}
+
+			if tj.Match != nil {
+				t.match = &matchedImport{date: tj.Match.Date, description: tj.Match.Description}
+			}
+
+			if tj.Rel != "" {
+				rel, ok := b.accountIndex[tj.Rel]
+				if !ok {
+					return fmt.Errorf("transaction %s refers to unknown account %q", tj.ID, tj.Rel)
+				}
+				t.rel = rel
+				pairs[t] = tj.Pair
+			}
+
+			b.transactions[t.uuid] = t
+			a.transactions = append(a.transactions, t)
+			b.extendMonths(YearMonthFromTime(t.date))
+
+			if tj.Category == "" {
+				continue
+			}
+
+			c, ok := b.categories[tj.Category]
+			if !ok {
+				return fmt.Errorf("transaction %s refers to unknown category %q", tj.ID, tj.Category)
+			}
+			if err := b.setTransactionCategory(t, c); err != nil {
+				return err
+			}
+		}
+	}
+
+	for t, id := range pairs {
+		pair, ok := b.transactions[id]
+		if !ok || pair.account != t.rel || pair.rel != t.account {
+			return fmt.Errorf("transfer %s has no matching transaction on the other account", t.uuid)
+		}
+		t.pair = pair
+	}
+
+	for _, mj := range doc.Budgeted {
+		mb := monthBudget{
+			Month:    mj.Month,
+			Budgeted: map[string]decimal.Decimal{},
+		}
+
+		for id, amount := range mj.Budgeted {
+			if _, ok := b.categories[id]; !ok {
+				return fmt.Errorf("budgeted amount for %s refers to unknown category %q", mj.Month, id)
+			}
+			mb.Budgeted[id] = amount
+		}
+
+		b.budgeted[mj.Month] = mb
+		b.extendMonths(mj.Month)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package budgeting
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudget_JSONRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	jan := YearMonth{2018, time.January}
+	feb := YearMonth{2018, time.February}
+
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	wallet, _ := budget.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food", WithGroup("Everyday"))
+	bills, _ := budget.AddCategory("Bills")
+
+	budget.SetBudgeted(jan, food, dec("50.00"))
+	budget.SetBudgeted(feb, bills, dec("20.00"))
+
+	acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", food, nil, WithCleared())
+	acc.AddTransaction(date(2018, 1, 3), dec("-20.00"), "withdraw", nil, wallet)
+	acc.AddTransaction(date(2018, 2, 3), dec("-7.00"), "uncategorized", nil, nil, WithImportID("fitid-1"), WithUnapproved())
+	electricity, _ := wallet.AddTransaction(date(2018, 2, 4), dec("-12.50"), "electricity", bills, nil)
+	electricity.MatchImport(Match{ImportID: "fitid-2", Date: date(2018, 2, 6), Description: "ELECTRIC CO"})
+
+	data, err := json.Marshal(budget)
+	assert.Nil(err)
+
+	var restored Budget
+	assert.Nil(json.Unmarshal(data, &restored))
+
+	assert.Equal(budget.ID(), restored.ID())
+	assert.Equal("My Budget", restored.Name())
+	assert.True(restored.TBBCategory().Equal(budget.TBBCategory()))
+	assert.Len(restored.accounts, 2)
+	assert.Len(restored.categories, 3)
+
+	rFood := restored.categories[food.uuid]
+	rBills := restored.categories[bills.uuid]
+	assert.Equal("Food", rFood.Name())
+	assert.Equal("Everyday", rFood.Group())
+
+	for _, m := range []YearMonth{jan, feb} {
+		assert.Equal(budget.TBB(m).StringFixed(2), restored.TBB(m).StringFixed(2))
+		assert.Equal(food.Available(m).StringFixed(2), rFood.Available(m).StringFixed(2))
+		assert.Equal(bills.Available(m).StringFixed(2), rBills.Available(m).StringFixed(2))
+	}
+
+	assert.True(restored.accounts[0].StartingBalance() == restored.accounts[0].transactions[0])
+	assert.Equal(acc.Balance().StringFixed(2), restored.accounts[0].Balance().StringFixed(2))
+	assert.Equal(wallet.Balance().StringFixed(2), restored.accounts[1].Balance().StringFixed(2))
+
+	assert.Equal("fitid-1", restored.accounts[0].transactions[3].ImportID())
+	assert.False(restored.accounts[0].transactions[3].Approved())
+	assert.True(restored.accounts[0].transactions[2].Approved())
+	assert.True(restored.accounts[0].transactions[1].Cleared())
+	assert.False(restored.accounts[0].transactions[2].Cleared())
+	assert.Equal(&Match{ImportID: "fitid-2", Date: date(2018, 2, 6), Description: "ELECTRIC CO"}, restored.accounts[1].transactions[2].Match())
+
+	transfer := restored.accounts[1].transactions[1]
+	assert.Equal(TransactionTypeTransfer, transfer.Type())
+	assert.True(transfer.rel == restored.accounts[0])
+	assert.True(transfer.pair == restored.accounts[0].transactions[2])
+	assert.True(transfer.pair.pair == transfer)
+
+	again, err := json.Marshal(&restored)
+	assert.Nil(err)
+	assert.JSONEq(string(data), string(again))
+}
+
+func TestBudget_UnmarshalJSONUnknownCategory(t *testing.T) {
+	assert := assert.New(t)
+
+	var b Budget
+	err := json.Unmarshal([]byte(`{"id":"b","name":"x","tbb":"missing","categories":[],"accounts":[],"budgeted":[]}`), &b)
+	assert.NotNil(err)
+}
+
+func TestYearMonth_Text(t *testing.T) {
+	assert := assert.New(t)
+
+	m := YearMonth{2018, time.May}
+	assert.Equal("2018-05", m.String())
+
+	parsed, err := ParseYearMonth("2018-05")
+	assert.Nil(err)
+	assert.True(parsed.Equal(m))
+
+	_, err = ParseYearMonth("May 2018")
+	assert.NotNil(err)
+}
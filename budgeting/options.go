@@ -0,0 +1,107 @@
+package budgeting
+
+import (
+	uuid "github.com/satori/go.uuid"
+)
+
+// Option customizes the creation of a budget, account, category or transaction.
+// Each option applies to some of them only, and is ignored by the others:
+//
+//   - WithID: NewBudget, AddAccount, AddCategory and AddTransaction;
+//   - WithTBBCategoryID: NewBudget;
+//   - WithStartingBalanceID: AddAccount;
+//   - WithGroup: AddCategory;
+//   - WithPairID, WithImportID, WithCleared and WithUnapproved: AddTransaction.
+//
+// IDs are generated when no option gives them; the ID options are meant for
+// rehydrating entities from storage with the IDs they were stored with.
+type Option func(*options)
+
+type options struct {
+	id                string
+	pairID            string
+	startingBalanceID string
+	tbbID             string
+	importID          string
+	group             string
+	cleared           bool
+	unapproved        bool
+}
+
+// WithID sets the ID of the created budget, account, category or transaction.
+func WithID(id string) Option {
+	return func(o *options) {
+		o.id = id
+	}
+}
+
+// WithPairID sets the ID of the transaction created on the other account of
+// a transfer by AddTransaction.
+func WithPairID(id string) Option {
+	return func(o *options) {
+		o.pairID = id
+	}
+}
+
+// WithStartingBalanceID sets the ID of the starting balance transaction
+// created by AddAccount.
+func WithStartingBalanceID(id string) Option {
+	return func(o *options) {
+		o.startingBalanceID = id
+	}
+}
+
+// WithTBBCategoryID sets the ID of the "To Be Budgeted" category created by NewBudget.
+func WithTBBCategoryID(id string) Option {
+	return func(o *options) {
+		o.tbbID = id
+	}
+}
+
+// WithImportID sets the ID a bank gave the transaction created by
+// AddTransaction, for recognising it when the same statement is imported
+// again. Only the side on the account is given the ID.
+func WithImportID(id string) Option {
+	return func(o *options) {
+		o.importID = id
+	}
+}
+
+// WithGroup lists the category created by AddCategory under the group.
+func WithGroup(group string) Option {
+	return func(o *options) {
+		o.group = group
+	}
+}
+
+// WithCleared marks the transaction created by AddTransaction as cleared by
+// the bank. Only the side on the account is cleared.
+func WithCleared() Option {
+	return func(o *options) {
+		o.cleared = true
+	}
+}
+
+// WithUnapproved leaves the transaction created by AddTransaction unapproved,
+// for someone to review it. Only the side on the account is unapproved.
+func WithUnapproved() Option {
+	return func(o *options) {
+		o.unapproved = true
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// idOrNew returns id, or a freshly generated ID if id is empty.
+func idOrNew(id string) string {
+	if id != "" {
+		return id
+	}
+	return uuid.NewV4().String()
+}
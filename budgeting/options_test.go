@@ -0,0 +1,66 @@
+package budgeting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions_IDs(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget", WithID("budget"), WithTBBCategoryID("tbb"))
+	assert.Equal("budget", budget.ID())
+	assert.Equal("tbb", budget.TBBCategory().ID())
+
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1), WithID("savings"), WithStartingBalanceID("start"))
+	wallet, _ := budget.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1), WithID("wallet"))
+	food, _ := budget.AddCategory("Food", WithID("food"))
+	assert.Equal("savings", acc.ID())
+	assert.Equal("food", food.ID())
+	assert.Equal("start", acc.Transactions()[0].ID())
+
+	lunch, err := acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", food, nil, WithID("lunch"))
+	assert.Nil(err)
+	assert.Equal("lunch", lunch.ID())
+
+	withdraw, err := acc.AddTransaction(date(2018, 1, 3), dec("-20.00"), "withdraw", nil, wallet, WithID("out"), WithPairID("in"))
+	assert.Nil(err)
+	assert.Equal("out", withdraw.ID())
+
+	in, err := budget.Transaction("in")
+	assert.Nil(err)
+	assert.True(in.Account() == wallet)
+	assert.True(in.TransferAccount() == acc)
+	assert.Equal("20.00", in.Amount().StringFixed(2))
+}
+
+func TestOptions_DuplicateIDs(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	acc, _ := budget.AddAccount("Savings", dec("0.00"), date(2018, 1, 1), WithID("savings"))
+	wallet, _ := budget.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+	budget.AddCategory("Food", WithID("food"))
+
+	_, err := acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", nil, nil, WithID("t1"))
+	assert.Nil(err)
+
+	_, err = acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", nil, nil, WithID("t1"))
+	assert.Equal(ErrDuplicateID, err)
+	_, err = acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "withdraw", nil, wallet, WithPairID("t1"))
+	assert.Equal(ErrDuplicateID, err)
+	_, err = acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "withdraw", nil, wallet, WithID("t2"), WithPairID("t2"))
+	assert.Equal(ErrDuplicateID, err)
+	assert.Len(acc.Transactions(), 2)
+	assert.Len(wallet.Transactions(), 1)
+
+	_, err = budget.AddAccount("Again", dec("0.00"), date(2018, 1, 1), WithID("savings"))
+	assert.Equal(ErrDuplicateID, err)
+	_, err = budget.AddAccount("Again", dec("0.00"), date(2018, 1, 1), WithStartingBalanceID("t1"))
+	assert.Equal(ErrDuplicateID, err)
+	_, err = budget.AddCategory("Again", WithID("food"))
+	assert.Equal(ErrDuplicateID, err)
+	assert.Len(budget.Accounts(), 2)
+	assert.Len(budget.Categories(), 2)
+}
@@ -0,0 +1,210 @@
+package budgeting
+
+import (
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// summaryCache holds per-month running totals so that TBB and Available don't
+// have to walk the whole history of the budget on every call.
+//
+// Entries are filled lazily by readers and dropped by writers from the month
+// of a change forward, since a change never affects the totals of earlier
+// months. The one exception is the "To Be Budgeted" category, whose budgeted
+// amount is the TBB balance and therefore depends on later months as well;
+// its Available entries are dropped entirely whenever TBB might change.
+//
+// Readers fill the cache while only holding the budget's read lock, so the
+// cache has a lock of its own.
+type summaryCache struct {
+	mu sync.Mutex
+
+	// available maps a category to its Available balance by month.
+	available map[string]map[YearMonth]decimal.Decimal
+
+	// tbbRunning is the TBB balance by month before money budgeted in later
+	// months is taken into account.
+	tbbRunning map[YearMonth]decimal.Decimal
+
+	// budgetedTotal is the sum of the amounts budgeted in a month.
+	budgetedTotal map[YearMonth]decimal.Decimal
+}
+
+func newSummaryCache() *summaryCache {
+	return &summaryCache{
+		available:     map[string]map[YearMonth]decimal.Decimal{},
+		tbbRunning:    map[YearMonth]decimal.Decimal{},
+		budgetedTotal: map[YearMonth]decimal.Decimal{},
+	}
+}
+
+func (c *summaryCache) getAvailable(category string, month YearMonth) (decimal.Decimal, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.available[category][month]
+	return v, ok
+}
+
+func (c *summaryCache) setAvailable(category string, month YearMonth, v decimal.Decimal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.available[category]; !ok {
+		c.available[category] = map[YearMonth]decimal.Decimal{}
+	}
+	c.available[category][month] = v
+}
+
+func (c *summaryCache) getTBBRunning(month YearMonth) (decimal.Decimal, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.tbbRunning[month]
+	return v, ok
+}
+
+func (c *summaryCache) setTBBRunning(month YearMonth, v decimal.Decimal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tbbRunning[month] = v
+}
+
+func (c *summaryCache) getBudgetedTotal(month YearMonth) (decimal.Decimal, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.budgetedTotal[month]
+	return v, ok
+}
+
+func (c *summaryCache) setBudgetedTotal(month YearMonth, v decimal.Decimal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.budgetedTotal[month] = v
+}
+
+// invalidateAvailable drops the Available balances of the category from the
+// given month forward.
+func (c *summaryCache) invalidateAvailable(category string, from YearMonth) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for m := range c.available[category] {
+		if !from.Earlier(m) {
+			delete(c.available[category], m)
+		}
+	}
+}
+
+// invalidateTBB drops the TBB running totals from the given month forward,
+// along with every Available balance of the "To Be Budgeted" category.
+func (c *summaryCache) invalidateTBB(tbb string, from YearMonth) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for m := range c.tbbRunning {
+		if !from.Earlier(m) {
+			delete(c.tbbRunning, m)
+		}
+	}
+	delete(c.available, tbb)
+}
+
+// invalidateBudgeted drops the budgeted total of a single month.
+func (c *summaryCache) invalidateBudgeted(month YearMonth) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.budgetedTotal, month)
+}
+
+// reset drops everything.
+func (c *summaryCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.available = map[string]map[YearMonth]decimal.Decimal{}
+	c.tbbRunning = map[YearMonth]decimal.Decimal{}
+	c.budgetedTotal = map[YearMonth]decimal.Decimal{}
+}
+
+// invalidateTransaction drops every summary affected by a change to a
+// categorized transaction.
+func (b *Budget) invalidateTransaction(t *Transaction) {
+	if t.category == nil {
+		return
+	}
+
+	month := YearMonthFromTime(t.date)
+	b.summaries.invalidateAvailable(t.category.uuid, month)
+	if t.category.Equal(b.tbb) {
+		b.summaries.invalidateTBB(b.tbb.uuid, month)
+	}
+}
+
+// invalidateBudgeted drops every summary affected by a change to the amount
+// budgeted for a category in a month.
+func (b *Budget) invalidateBudgeted(month YearMonth, category *Category) {
+	b.summaries.invalidateAvailable(category.uuid, month)
+	b.summaries.invalidateBudgeted(month)
+	b.summaries.invalidateTBB(b.tbb.uuid, month)
+}
+
+// tbbRunning returns the TBB balance of the month, ignoring money budgeted in
+// later months. It is the sum of the "To Be Budgeted" inflows minus the sum of
+// the budgeted amounts of every month up to and including the given month.
+func (b *Budget) tbbRunning(month YearMonth) decimal.Decimal {
+	if b.earliestMonth.Earlier(month) {
+		return zero
+	}
+
+	pending := []YearMonth{}
+	running := zero
+
+	m := month
+	for {
+		if v, ok := b.summaries.getTBBRunning(m); ok {
+			running = v
+			break
+		}
+
+		pending = append(pending, m)
+		if b.earliestMonth.Equal(m) {
+			break
+		}
+		m = m.LastMonth()
+	}
+
+	for i := len(pending) - 1; i >= 0; i-- {
+		m := pending[i]
+		for _, t := range b.monthCategoryTransactions(m, b.tbb) {
+			running = running.Add(t.amount)
+		}
+		running = running.Sub(b.budgetedTotal(m))
+
+		b.summaries.setTBBRunning(m, running)
+	}
+
+	return running
+}
+
+// budgetedTotal returns the sum of the amounts budgeted in the month.
+func (b *Budget) budgetedTotal(month YearMonth) decimal.Decimal {
+	if v, ok := b.summaries.getBudgetedTotal(month); ok {
+		return v
+	}
+
+	total := zero
+	if mb, ok := b.budgeted[month]; ok {
+		for _, v := range mb.Budgeted {
+			total = total.Add(v)
+		}
+	}
+
+	b.summaries.setBudgetedTotal(month, total)
+	return total
+}
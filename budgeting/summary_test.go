@@ -0,0 +1,132 @@
+package budgeting
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// referenceTBB is the uncached TBB calculation.
+func referenceTBB(b *Budget, month YearMonth) decimal.Decimal {
+	tbb := zero
+
+	for m := month; !b.earliestMonth.Earlier(m); m = m.LastMonth() {
+		for _, t := range b.monthCategoryTransactions(m, b.tbb) {
+			tbb = tbb.Add(t.amount)
+		}
+		for _, v := range b.budgeted[m].Budgeted {
+			tbb = tbb.Sub(v)
+		}
+	}
+
+	if tbb.GreaterThan(zero) {
+		for m := month.NextMonth(); !b.latestMonth.Later(m); m = m.NextMonth() {
+			for _, v := range b.budgeted[m].Budgeted {
+				tbb = tbb.Sub(v)
+				if tbb.LessThan(zero) {
+					tbb = zero
+					break
+				}
+			}
+		}
+	}
+
+	return tbb
+}
+
+// referenceAvailable is the uncached Available calculation.
+func referenceAvailable(b *Budget, month YearMonth, c *Category) decimal.Decimal {
+	available := zero
+
+	if !b.earliestMonth.Earlier(month) {
+		available = available.Add(referenceAvailable(b, month.LastMonth(), c))
+	}
+
+	budgeted := zero
+	if _, ok := b.budgeted[month]; ok {
+		if c.Equal(b.tbb) {
+			budgeted = referenceTBB(b, month)
+		} else {
+			budgeted = b.budgeted[month].Budgeted[c.uuid]
+		}
+	}
+
+	return available.Add(budgeted).Add(b.activities(month, c))
+}
+
+func TestBudget_SummariesMatchReference(t *testing.T) {
+	assert := assert.New(t)
+	rnd := rand.New(rand.NewSource(42))
+
+	budget := NewBudget("My Budget")
+	savings, _ := budget.AddAccount("Savings", dec("500.00"), date(2018, 3, 1))
+	wallet, _ := budget.AddAccount("Wallet", dec("20.00"), date(2018, 3, 1))
+	food, _ := budget.AddCategory("Food")
+	bills, _ := budget.AddCategory("Bills")
+	accounts := []*Account{savings, wallet}
+	categories := []*Category{budget.tbb, food, bills}
+	transactions := []*Transaction{}
+
+	randomMonth := func() YearMonth {
+		return YearMonth{2018, time.Month(1 + rnd.Intn(12))}
+	}
+	randomDate := func() time.Time {
+		return date(2018, 1+rnd.Intn(12), 1+rnd.Intn(28))
+	}
+	randomAmount := func() decimal.Decimal {
+		return decimal.New(int64(rnd.Intn(10000)-7000), -2)
+	}
+
+	check := func(step int) {
+		for m := (YearMonth{2017, time.November}); !m.Equal(YearMonth{2019, time.March}); m = m.NextMonth() {
+			assert.Equal(referenceTBB(budget, m).StringFixed(2), budget.TBB(m).StringFixed(2), "TBB %s after step %d", m, step)
+			for _, c := range categories {
+				assert.Equal(referenceAvailable(budget, m, c).StringFixed(2), budget.Available(m, c).StringFixed(2), "Available %s %s after step %d", c.Name(), m, step)
+			}
+		}
+	}
+
+	for step := 0; step < 300; step++ {
+		acc := accounts[rnd.Intn(len(accounts))]
+
+		switch op := rnd.Intn(8); {
+		case op == 0 || len(transactions) == 0:
+			c := categories[rnd.Intn(len(categories))]
+			if rnd.Intn(5) == 0 {
+				c = nil
+			}
+			tr, _ := acc.AddTransaction(randomDate(), randomAmount(), "transaction", c, nil)
+			transactions = append(transactions, tr)
+		case op == 1:
+			budget.SetBudgeted(randomMonth(), categories[1+rnd.Intn(2)], decimal.New(int64(rnd.Intn(5000)), -2))
+		case op == 2:
+			budget.MoveBudgeted(randomMonth(), budget.TBBCategory(), categories[1+rnd.Intn(2)], decimal.New(int64(rnd.Intn(3000)), -2))
+		case op == 3:
+			transactions[rnd.Intn(len(transactions))].SetCategory(categories[rnd.Intn(len(categories))])
+		case op == 4:
+			transactions[rnd.Intn(len(transactions))].SetDate(randomDate())
+		case op == 5:
+			transactions[rnd.Intn(len(transactions))].SetAmount(randomAmount())
+		case op == 6:
+			i := rnd.Intn(len(transactions))
+			transactions[i].account.DeleteTransaction(transactions[i])
+			transactions = append(transactions[:i], transactions[i+1:]...)
+		case op == 7:
+			tr, _ := acc.AddTransaction(randomDate(), randomAmount(), "transfer", nil, accounts[0])
+			transactions = append(transactions, tr)
+		}
+
+		if step%10 == 0 {
+			check(step)
+		}
+	}
+
+	check(300)
+
+	// Moving data before the start of the budget resets the running totals.
+	accounts[0].AddTransaction(date(2017, 6, 1), dec("10.00"), "early", budget.tbb, nil)
+	check(301)
+}
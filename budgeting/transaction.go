@@ -1,9 +1,9 @@
 package budgeting
 
 import (
+	"fmt"
 	"time"
 
-	"github.com/satori/go.uuid"
 	"github.com/shopspring/decimal"
 )
 
@@ -19,20 +19,36 @@ const (
 	TransactionTypeTransfer
 )
 
+// ErrNotMatched is returned when rejecting the match of a transaction which
+// wasn't matched with an imported one.
+var ErrNotMatched = fmt.Errorf("transaction isn't matched with an imported one")
+
 // Transaction represents a movement of money in the budget.
+//
+// Imported transactions are unapproved until someone has reviewed them.
+// Being unapproved only flags them for review: they count towards the
+// account balance and the activities of their category all the same, as
+// the money has moved whether or not anyone looked at it.
 type Transaction struct {
-	Date        time.Time
-	Description string
-	Amount      decimal.Decimal
+	date        time.Time
+	description string
+	amount      decimal.Decimal
+	importID    string
+	cleared     bool
+	unapproved  bool
+	match       *matchedImport
 
 	uuid     string
 	budget   *Budget
 	account  *Account
 	category *Category
 	rel      *Account
+	pair     *Transaction
+	deleted  bool
 }
 
 func newTransaction(
+	id string,
 	budget *Budget,
 	account *Account,
 	date time.Time,
@@ -42,11 +58,11 @@ func newTransaction(
 	rel *Account) *Transaction {
 
 	return &Transaction{
-		Date:        date,
-		Amount:      amount,
-		Description: description,
+		date:        date,
+		amount:      amount,
+		description: description,
 
-		uuid:     uuid.NewV4().String(),
+		uuid:     id,
 		budget:   budget,
 		account:  account,
 		category: category,
@@ -54,14 +70,288 @@ func newTransaction(
 	}
 }
 
+// ID returns the transaction's unique identifier.
+func (t *Transaction) ID() string {
+	return t.uuid
+}
+
+// Account returns the account the transaction is on.
+func (t *Transaction) Account() *Account {
+	return t.account
+}
+
+// TransferAccount returns the other account of a transfer, or nil if the
+// transaction isn't a transfer.
+func (t *Transaction) TransferAccount() *Account {
+	return t.rel
+}
+
+// ImportID returns the ID the bank gave the transaction, or an empty string
+// if it wasn't imported with one.
+func (t *Transaction) ImportID() string {
+	t.budget.mu.RLock()
+	defer t.budget.mu.RUnlock()
+
+	return t.importID
+}
+
+// SetImportID gives the transaction the ID a bank gave it, linking a
+// transaction entered by hand to the one on the bank's statement. Only
+// this side of a transfer is changed.
+func (t *Transaction) SetImportID(id string) error {
+	t.budget.mu.Lock()
+	defer t.budget.mu.Unlock()
+
+	if err := t.checkEditable(); err != nil {
+		return err
+	}
+
+	t.importID = id
+	t.budget.touch()
+	return nil
+}
+
+// Match is the transaction of a bank's statement which a transaction entered
+// by hand was matched with when importing the statement.
+type Match struct {
+	ImportID    string
+	Date        time.Time
+	Description string
+}
+
+// matchedImport is what a matched transaction keeps of the imported one,
+// whose amount is the same, to undo the match.
+type matchedImport struct {
+	date        time.Time
+	description string
+}
+
+// Cleared tells whether the bank has taken the transaction into account.
+func (t *Transaction) Cleared() bool {
+	t.budget.mu.RLock()
+	defer t.budget.mu.RUnlock()
+
+	return t.cleared
+}
+
+// SetCleared marks the transaction as cleared by the bank, or not. Only this
+// side of a transfer is changed, as the other account's bank clears it on
+// its own.
+func (t *Transaction) SetCleared(cleared bool) error {
+	t.budget.mu.Lock()
+	defer t.budget.mu.Unlock()
+
+	if err := t.checkEditable(); err != nil {
+		return err
+	}
+
+	t.cleared = cleared
+	t.budget.touch()
+	return nil
+}
+
+// Approved tells whether the transaction has been reviewed. Only imported
+// transactions start unapproved.
+func (t *Transaction) Approved() bool {
+	t.budget.mu.RLock()
+	defer t.budget.mu.RUnlock()
+
+	return !t.unapproved
+}
+
+// Approve marks the transaction as reviewed, accepting its match if it has
+// one. Both sides of a transfer are approved together.
+func (t *Transaction) Approve() error {
+	t.budget.mu.Lock()
+	defer t.budget.mu.Unlock()
+
+	if err := t.checkEditable(); err != nil {
+		return err
+	}
+
+	for _, tt := range t.sides() {
+		tt.unapproved = false
+		tt.match = nil
+	}
+
+	t.budget.touch()
+	return nil
+}
+
+// Match returns the imported transaction the transaction was matched with
+// and which hasn't been approved yet, or nil if there is none.
+func (t *Transaction) Match() *Match {
+	t.budget.mu.RLock()
+	defer t.budget.mu.RUnlock()
+
+	if t.match == nil {
+		return nil
+	}
+	return &Match{
+		ImportID:    t.importID,
+		Date:        t.match.date,
+		Description: t.match.description,
+	}
+}
+
+// MatchImport records that the transaction, entered by hand, is the
+// imported transaction m, of the same amount. It takes the import ID of m,
+// is cleared, as it was on the bank's statement, and is unapproved until
+// the match is approved or rejected.
+func (t *Transaction) MatchImport(m Match) error {
+	t.budget.mu.Lock()
+	defer t.budget.mu.Unlock()
+
+	if err := t.checkEditable(); err != nil {
+		return err
+	}
+
+	t.importID = m.ImportID
+	t.cleared = true
+	t.unapproved = true
+	t.match = &matchedImport{date: m.Date, description: m.Description}
+
+	t.budget.touch()
+	return nil
+}
+
+// RejectMatch undoes the match of the transaction: the imported transaction
+// is added to the account without a category, cleared and unapproved, and
+// the transaction is left as it was entered, uncleared. It returns the
+// added transaction.
+func (t *Transaction) RejectMatch() (*Transaction, error) {
+	t.budget.mu.Lock()
+	defer t.budget.mu.Unlock()
+
+	if err := t.checkEditable(); err != nil {
+		return nil, err
+	}
+	if t.match == nil {
+		return nil, ErrNotMatched
+	}
+
+	imported, err := t.account.addTransaction(t.match.date, t.amount, t.match.description, nil, nil, options{
+		importID:   t.importID,
+		cleared:    true,
+		unapproved: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t.importID = ""
+	t.cleared = false
+	t.unapproved = false
+	t.match = nil
+	return imported, nil
+}
+
+// Date returns the date of the transaction.
+func (t *Transaction) Date() time.Time {
+	t.budget.mu.RLock()
+	defer t.budget.mu.RUnlock()
+
+	return t.date
+}
+
+// SetDate changes the date of the transaction.
+// Both sides of a transfer are moved together.
+func (t *Transaction) SetDate(date time.Time) error {
+	t.budget.mu.Lock()
+	defer t.budget.mu.Unlock()
+
+	if err := t.checkEditable(); err != nil {
+		return err
+	}
+
+	for _, tt := range t.sides() {
+		t.budget.unindexTransaction(tt)
+		tt.date = date
+		t.budget.indexTransaction(tt)
+	}
+
+	t.budget.extendMonths(YearMonthFromTime(date))
+	t.budget.touch()
+	return nil
+}
+
+// Description returns the transaction description.
+func (t *Transaction) Description() string {
+	t.budget.mu.RLock()
+	defer t.budget.mu.RUnlock()
+
+	return t.description
+}
+
+// SetDescription changes the transaction description.
+// Both sides of a transfer are changed together.
+func (t *Transaction) SetDescription(description string) error {
+	t.budget.mu.Lock()
+	defer t.budget.mu.Unlock()
+
+	if err := t.checkEditable(); err != nil {
+		return err
+	}
+
+	for _, tt := range t.sides() {
+		tt.description = description
+	}
+
+	t.budget.touch()
+	return nil
+}
+
+// Amount returns the transaction amount. Inflows are positive and outflows are negative.
+func (t *Transaction) Amount() decimal.Decimal {
+	t.budget.mu.RLock()
+	defer t.budget.mu.RUnlock()
+
+	return t.amount
+}
+
+// SetAmount changes the transaction amount. The other side of a transfer
+// gets the opposite amount.
+func (t *Transaction) SetAmount(amount decimal.Decimal) error {
+	t.budget.mu.Lock()
+	defer t.budget.mu.Unlock()
+
+	if err := t.checkEditable(); err != nil {
+		return err
+	}
+
+	t.budget.invalidateTransaction(t)
+	t.amount = amount
+	if t.pair != nil {
+		t.pair.amount = amount.Neg()
+	}
+
+	t.budget.touch()
+	return nil
+}
+
 // Category returns the transactino category.
 func (t *Transaction) Category() *Category {
+	t.budget.mu.RLock()
+	defer t.budget.mu.RUnlock()
+
 	return t.category
 }
 
 // SetCategory sets the transaction category.
 func (t *Transaction) SetCategory(category *Category) error {
-	return t.budget.setTransactionCategory(t, category)
+	t.budget.mu.Lock()
+	defer t.budget.mu.Unlock()
+
+	if err := t.checkEditable(); err != nil {
+		return err
+	}
+
+	if err := t.budget.setTransactionCategory(t, category); err != nil {
+		return err
+	}
+
+	t.budget.touch()
+	return nil
 }
 
 // Type returns the transaction type.
@@ -72,3 +362,28 @@ func (t *Transaction) Type() TransactionType {
 
 	return TransactionTypeTransfer
 }
+
+// checkEditable returns an error if the transaction has been deleted or
+// either side of it is on a closed account.
+func (t *Transaction) checkEditable() error {
+	if t.deleted {
+		return ErrTransactionNotFound
+	}
+
+	for _, tt := range t.sides() {
+		if tt.account.closed {
+			return ErrAccountClosed
+		}
+	}
+
+	return nil
+}
+
+// sides returns the transaction along with the other side of the transfer, if any.
+func (t *Transaction) sides() []*Transaction {
+	if t.pair == nil {
+		return []*Transaction{t}
+	}
+
+	return []*Transaction{t, t.pair}
+}
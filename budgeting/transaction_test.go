@@ -0,0 +1,196 @@
+package budgeting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_SetDate(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	jan := YearMonth{2018, time.January}
+	feb := YearMonth{2018, time.February}
+	mar := YearMonth{2018, time.March}
+
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food")
+	budget.SetBudgeted(jan, food, dec("50.00"))
+
+	tr, _ := acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", food, nil)
+	assert.Equal(dec("-5.00").StringFixed(2), food.Activities(jan).StringFixed(2))
+
+	assert.Nil(tr.SetDate(date(2018, 2, 14)))
+	assert.Equal(date(2018, 2, 14), tr.Date())
+	assert.Equal(dec("0.00").StringFixed(2), food.Activities(jan).StringFixed(2))
+	assert.Equal(dec("-5.00").StringFixed(2), food.Activities(feb).StringFixed(2))
+	assert.Equal(dec("50.00").StringFixed(2), food.Available(jan).StringFixed(2))
+	assert.Equal(dec("45.00").StringFixed(2), food.Available(feb).StringFixed(2))
+
+	// Moving a transaction past the last month extends the budget.
+	assert.Nil(tr.SetDate(date(2018, 3, 1)))
+	assert.Equal(dec("-5.00").StringFixed(2), food.Activities(mar).StringFixed(2))
+	assert.Equal(dec("45.00").StringFixed(2), food.Available(mar).StringFixed(2))
+}
+
+func TestTransaction_SetAmount(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	jan := YearMonth{2018, time.January}
+
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	wallet, _ := budget.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food")
+
+	tr, _ := acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", food, nil)
+	assert.Nil(tr.SetAmount(dec("-7.50")))
+	assert.Equal(dec("-7.50").StringFixed(2), tr.Amount().StringFixed(2))
+	assert.Equal(dec("-7.50").StringFixed(2), food.Activities(jan).StringFixed(2))
+	assert.Equal(dec("92.50").StringFixed(2), acc.Balance().StringFixed(2))
+
+	transfer, _ := acc.AddTransaction(date(2018, 1, 3), dec("-20.00"), "withdraw", nil, wallet)
+	assert.Nil(transfer.SetAmount(dec("-30.00")))
+	assert.Nil(transfer.SetDescription("withdraw more"))
+	assert.Equal(dec("62.50").StringFixed(2), acc.Balance().StringFixed(2))
+	assert.Equal(dec("30.00").StringFixed(2), wallet.Balance().StringFixed(2))
+	assert.Equal("withdraw more", wallet.transactions[1].Description())
+	assert.Equal(dec("100.00").StringFixed(2), budget.TBB(jan).StringFixed(2))
+}
+
+func TestTransaction_SetImportID(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	wallet, _ := budget.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+
+	transfer, _ := acc.AddTransaction(date(2018, 1, 3), dec("-20.00"), "withdraw", nil, wallet)
+	assert.Nil(transfer.SetImportID("20180103-1"))
+	assert.Equal("20180103-1", transfer.ImportID())
+	assert.Equal("", wallet.transactions[1].ImportID())
+
+	wallet.Close()
+	assert.Equal(ErrAccountClosed, transfer.SetImportID("20180103-2"))
+}
+
+func TestAccount_DeleteTransaction(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	jan := YearMonth{2018, time.January}
+
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	wallet, _ := budget.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food")
+
+	lunch, _ := acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", food, nil)
+	dinner, _ := acc.AddTransaction(date(2018, 1, 2), dec("-8.00"), "dinner", food, nil)
+	transfer, _ := acc.AddTransaction(date(2018, 1, 3), dec("-20.00"), "withdraw", nil, wallet)
+
+	assert.EqualError(wallet.DeleteTransaction(lunch), ErrTransactionNotFound.Error())
+
+	assert.Nil(acc.DeleteTransaction(lunch))
+	assert.Equal(dec("-8.00").StringFixed(2), food.Activities(jan).StringFixed(2))
+	assert.Equal(dec("72.00").StringFixed(2), acc.Balance().StringFixed(2))
+	assert.Len(acc.transactionCategory[food.uuid], 1)
+	assert.True(acc.transactionCategory[food.uuid][0] == dinner)
+
+	assert.EqualError(acc.DeleteTransaction(lunch), ErrTransactionNotFound.Error())
+	assert.EqualError(lunch.SetCategory(food), ErrTransactionNotFound.Error())
+	assert.EqualError(lunch.SetAmount(dec("1.00")), ErrTransactionNotFound.Error())
+	assert.Equal(dec("-8.00").StringFixed(2), food.Activities(jan).StringFixed(2))
+
+	// Deleting the wallet side of a transfer removes both sides.
+	assert.Nil(wallet.DeleteTransaction(wallet.transactions[1]))
+	assert.Equal(dec("92.00").StringFixed(2), acc.Balance().StringFixed(2))
+	assert.Equal(dec("0.00").StringFixed(2), wallet.Balance().StringFixed(2))
+	assert.EqualError(acc.DeleteTransaction(transfer), ErrTransactionNotFound.Error())
+
+	assert.Nil(acc.DeleteTransaction(dinner))
+	assert.Equal(dec("0.00").StringFixed(2), food.Activities(jan).StringFixed(2))
+	assert.Empty(acc.transactionCategory[food.uuid])
+	assert.Empty(budget.monthCategoryIndex[monthCategory{jan, food.uuid}])
+}
+
+func TestTransaction_Approve(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	jan := YearMonth{2018, time.January}
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	wallet, _ := budget.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food")
+
+	// Unapproved transactions count all the same.
+	lunch, _ := acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", food, nil, WithUnapproved())
+	assert.False(lunch.Approved())
+	assert.Equal(dec("-5.00").StringFixed(2), food.Activities(jan).StringFixed(2))
+	assert.Nil(lunch.Approve())
+	assert.True(lunch.Approved())
+
+	transfer, _ := acc.AddTransaction(date(2018, 1, 3), dec("-20.00"), "withdraw", nil, wallet, WithUnapproved())
+	assert.False(transfer.Approved())
+	assert.True(wallet.transactions[1].Approved())
+	assert.Nil(wallet.transactions[1].Approve())
+	assert.True(transfer.Approved())
+}
+
+func TestTransaction_MatchImport(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	jan := YearMonth{2018, time.January}
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food")
+
+	lunch, _ := acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", food, nil)
+	assert.Nil(lunch.Match())
+	_, err := lunch.RejectMatch()
+	assert.Equal(ErrNotMatched, err)
+
+	m := Match{ImportID: "fitid-1", Date: date(2018, 1, 4), Description: "CAFE"}
+	assert.Nil(lunch.MatchImport(m))
+	assert.Equal(&m, lunch.Match())
+	assert.Equal("fitid-1", lunch.ImportID())
+	assert.False(lunch.Approved())
+	assert.True(lunch.Cleared())
+
+	imported, err := lunch.RejectMatch()
+	assert.Nil(err)
+	assert.Nil(lunch.Match())
+	assert.Equal("", lunch.ImportID())
+	assert.True(lunch.Approved())
+	assert.False(lunch.Cleared())
+	assert.True(imported.Cleared())
+	assert.Equal("fitid-1", imported.ImportID())
+	assert.Equal(date(2018, 1, 4), imported.Date())
+	assert.Equal("CAFE", imported.Description())
+	assert.Nil(imported.Category())
+	assert.False(imported.Approved())
+	assert.Equal(dec("90.00").StringFixed(2), acc.Balance().StringFixed(2))
+	assert.Equal(dec("-5.00").StringFixed(2), food.Activities(jan).StringFixed(2))
+
+	// Approving accepts the match.
+	lunch.MatchImport(m)
+	assert.Nil(lunch.Approve())
+	assert.Nil(lunch.Match())
+	assert.Equal("fitid-1", lunch.ImportID())
+}
+
+func TestTransaction_SetCleared(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := NewBudget("My Budget")
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	wallet, _ := budget.AddAccount("Wallet", dec("0.00"), date(2018, 1, 1))
+
+	transfer, _ := acc.AddTransaction(date(2018, 1, 3), dec("-20.00"), "withdraw", nil, wallet, WithCleared())
+	assert.True(transfer.Cleared())
+	assert.False(wallet.transactions[1].Cleared())
+
+	assert.Nil(transfer.SetCleared(false))
+	assert.False(transfer.Cleared())
+}
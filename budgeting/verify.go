@@ -0,0 +1,520 @@
+package budgeting
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// ViolationKind classifies a broken invariant.
+type ViolationKind string
+
+const (
+	// ViolationBalance means the money in the accounts doesn't add up to the
+	// money in the budget.
+	ViolationBalance ViolationKind = "balance"
+
+	// ViolationTransfer means a transfer doesn't have a matching opposite
+	// transaction on the other account.
+	ViolationTransfer ViolationKind = "transfer"
+
+	// ViolationIndex means a transaction lookup table disagrees with the
+	// transactions themselves.
+	ViolationIndex ViolationKind = "index"
+
+	// ViolationReference means something refers to a category, account or
+	// transaction which isn't part of the budget.
+	ViolationReference ViolationKind = "reference"
+
+	// ViolationMonthRange means some data lies outside the months the budget
+	// thinks it covers.
+	ViolationMonthRange ViolationKind = "month-range"
+
+	// ViolationSummary means a cached TBB or Available balance disagrees with
+	// the amounts budgeted and the transactions it sums up.
+	ViolationSummary ViolationKind = "summary"
+)
+
+// Violation describes a broken invariant found by Verify.
+type Violation struct {
+	Kind ViolationKind
+
+	// Account, Transaction and Category identify what the violation is
+	// about, where applicable. Account is the account name.
+	Account     string
+	Transaction string
+	Category    string
+
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Kind, v.Message)
+}
+
+// Verify checks the invariants of the budget and returns every violation
+// found, or nil if the budget is consistent. It checks that:
+//
+//   - the sum of the account balances equals TBB plus the Available balance
+//     of every other category plus uncategorized transactions, as of the
+//     latest month (every account is on-budget);
+//   - every transfer has a matching opposite transaction on the other account;
+//   - the per-account and per-month category lookup tables agree with the
+//     categories and dates of the transactions;
+//   - all referenced categories and accounts belong to the budget;
+//   - all transactions and budgeted amounts lie within the budget's months;
+//   - the cached TBB and Available balances of every month agree with the
+//     ones summed up without the cache.
+//
+// Violations are reported in a stable order: categories in the order they
+// were created, months earliest first, and IDs sorted.
+func (b *Budget) Verify() []Violation {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	v := &verifier{b: b}
+	v.verifyReferences()
+	v.verifyTransfers()
+	v.verifyIndexes()
+	v.verifyMonthRange()
+	v.verifyBalance()
+	v.verifySummaries()
+
+	return v.violations
+}
+
+type verifier struct {
+	b          *Budget
+	violations []Violation
+}
+
+func (v *verifier) add(violation Violation) {
+	v.violations = append(v.violations, violation)
+}
+
+func (v *verifier) isAccount(a *Account) bool {
+	for _, aa := range v.b.accounts {
+		if aa == a {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *verifier) verifyReferences() {
+	for _, c := range v.b.categoryOrder {
+		if registered, ok := v.b.categories[c.uuid]; !ok || registered != c || c.budget != v.b {
+			v.add(Violation{
+				Kind:     ViolationReference,
+				Category: c.uuid,
+				Message:  fmt.Sprintf("category %q is registered under the wrong ID or budget", c.Name()),
+			})
+		}
+	}
+
+	ordered := map[*Category]bool{}
+	for _, c := range v.b.categoryOrder {
+		ordered[c] = true
+	}
+	ids := make([]string, 0, len(v.b.categories))
+	for id := range v.b.categories {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if c := v.b.categories[id]; c.uuid != id || !ordered[c] {
+			v.add(Violation{
+				Kind:     ViolationReference,
+				Category: id,
+				Message:  fmt.Sprintf("category %q is registered under the wrong ID or missing from the category order", c.Name()),
+			})
+		}
+	}
+
+	for _, a := range v.b.accounts {
+		if a.budget != v.b {
+			v.add(Violation{
+				Kind:    ViolationReference,
+				Account: a.Name(),
+				Message: fmt.Sprintf("account %q belongs to another budget", a.Name()),
+			})
+		}
+
+		for _, t := range a.transactions {
+			if t.account != a || t.budget != v.b || t.deleted {
+				v.add(Violation{
+					Kind:        ViolationReference,
+					Account:     a.Name(),
+					Transaction: t.uuid,
+					Message:     fmt.Sprintf("transaction %s is listed on account %q but doesn't belong to it", t.uuid, a.Name()),
+				})
+			}
+
+			if t.category != nil {
+				if _, ok := v.b.categories[t.category.uuid]; !ok {
+					v.add(Violation{
+						Kind:        ViolationReference,
+						Account:     a.Name(),
+						Transaction: t.uuid,
+						Category:    t.category.uuid,
+						Message:     fmt.Sprintf("transaction %s has a category which isn't part of the budget", t.uuid),
+					})
+				}
+			}
+		}
+	}
+
+	for _, month := range v.budgetedMonths() {
+		for _, id := range sortedCategoryIDs(v.b.budgeted[month].Budgeted) {
+			if _, ok := v.b.categories[id]; !ok {
+				v.add(Violation{
+					Kind:     ViolationReference,
+					Category: id,
+					Message:  fmt.Sprintf("amount budgeted in %s for a category which isn't part of the budget", month),
+				})
+			}
+			if id == v.b.tbb.uuid {
+				v.add(Violation{
+					Kind:     ViolationReference,
+					Category: id,
+					Message:  fmt.Sprintf("amount budgeted in %s for To Be Budgeted", month),
+				})
+			}
+		}
+	}
+}
+
+func (v *verifier) verifyTransfers() {
+	for _, a := range v.b.accounts {
+		for _, t := range a.transactions {
+			if t.rel == nil {
+				if t.pair != nil {
+					v.addTransfer(a, t, "is paired with %s but isn't a transfer", t.pair.uuid)
+				}
+				continue
+			}
+
+			if t.category != nil {
+				v.addTransfer(a, t, "is a transfer with a category")
+			}
+			if !v.isAccount(t.rel) {
+				v.addTransfer(a, t, "is a transfer to an account outside the budget")
+				continue
+			}
+
+			p := t.pair
+			switch {
+			case p == nil:
+				v.addTransfer(a, t, "has no matching transaction on %q", t.rel.Name())
+			case p.pair != t:
+				v.addTransfer(a, t, "is paired with %s which is paired with something else", p.uuid)
+			case p.account != t.rel || p.rel != a:
+				v.addTransfer(a, t, "is paired with %s which isn't on %q transferring back", p.uuid, t.rel.Name())
+			case p.deleted || !containsTransaction(t.rel.transactions, p):
+				v.addTransfer(a, t, "is paired with %s which is missing from %q", p.uuid, t.rel.Name())
+			case !p.amount.Equal(t.amount.Neg()):
+				v.addTransfer(a, t, "has amount %s but its pair %s has %s", t.amount, p.uuid, p.amount)
+			case !p.date.Equal(t.date):
+				v.addTransfer(a, t, "is dated %s but its pair %s is dated %s", t.date.Format("2006-01-02"), p.uuid, p.date.Format("2006-01-02"))
+			}
+		}
+	}
+}
+
+func (v *verifier) addTransfer(a *Account, t *Transaction, format string, args ...interface{}) {
+	v.add(Violation{
+		Kind:        ViolationTransfer,
+		Account:     a.Name(),
+		Transaction: t.uuid,
+		Message:     fmt.Sprintf("transaction %s on %q ", t.uuid, a.Name()) + fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *verifier) verifyIndexes() {
+	indexed := map[*Transaction]int{}
+
+	for _, a := range v.b.accounts {
+		categories := make([]string, 0, len(a.transactionCategory))
+		for c := range a.transactionCategory {
+			categories = append(categories, c)
+		}
+		sort.Strings(categories)
+
+		for _, c := range categories {
+			for _, t := range a.transactionCategory[c] {
+				if t.category == nil || t.category.uuid != c || t.account != a || t.deleted {
+					v.add(Violation{
+						Kind:        ViolationIndex,
+						Account:     a.Name(),
+						Transaction: t.uuid,
+						Category:    c,
+						Message:     fmt.Sprintf("transaction %s is indexed under category %s of %q but doesn't belong there", t.uuid, c, a.Name()),
+					})
+				}
+			}
+		}
+
+		for _, t := range a.transactions {
+			if t.category == nil {
+				continue
+			}
+
+			if n := countTransaction(a.transactionCategory[t.category.uuid], t); n != 1 {
+				v.add(Violation{
+					Kind:        ViolationIndex,
+					Account:     a.Name(),
+					Transaction: t.uuid,
+					Category:    t.category.uuid,
+					Message:     fmt.Sprintf("transaction %s appears %d times in its account's category index", t.uuid, n),
+				})
+			}
+
+			key := monthCategory{YearMonthFromTime(t.date), t.category.uuid}
+			if n := countTransaction(v.b.monthCategoryIndex[key], t); n != 1 {
+				v.add(Violation{
+					Kind:        ViolationIndex,
+					Account:     a.Name(),
+					Transaction: t.uuid,
+					Category:    t.category.uuid,
+					Message:     fmt.Sprintf("transaction %s appears %d times in the index for %s", t.uuid, n, key.month),
+				})
+			}
+			indexed[t]++
+		}
+	}
+
+	keys := make([]monthCategory, 0, len(v.b.monthCategoryIndex))
+	for key := range v.b.monthCategoryIndex {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].month.Equal(keys[j].month) {
+			return keys[j].month.Earlier(keys[i].month)
+		}
+		return keys[i].category < keys[j].category
+	})
+
+	for _, key := range keys {
+		for _, t := range v.b.monthCategoryIndex[key] {
+			if _, ok := indexed[t]; ok && t.category.uuid == key.category && YearMonthFromTime(t.date).Equal(key.month) {
+				continue
+			}
+
+			v.add(Violation{
+				Kind:        ViolationIndex,
+				Transaction: t.uuid,
+				Category:    key.category,
+				Message:     fmt.Sprintf("transaction %s is indexed under %s for category %s but doesn't belong there", t.uuid, key.month, key.category),
+			})
+		}
+	}
+}
+
+func (v *verifier) verifyMonthRange() {
+	outside := func(m YearMonth) bool {
+		return v.b.earliestMonth.Earlier(m) || v.b.latestMonth.Later(m)
+	}
+
+	for _, a := range v.b.accounts {
+		for _, t := range a.transactions {
+			if m := YearMonthFromTime(t.date); outside(m) {
+				v.add(Violation{
+					Kind:        ViolationMonthRange,
+					Account:     a.Name(),
+					Transaction: t.uuid,
+					Message:     fmt.Sprintf("transaction %s in %s is outside of %s to %s", t.uuid, m, v.b.earliestMonth, v.b.latestMonth),
+				})
+			}
+		}
+	}
+
+	for _, m := range v.budgetedMonths() {
+		if len(v.b.budgeted[m].Budgeted) == 0 || !outside(m) {
+			continue
+		}
+		v.add(Violation{
+			Kind:    ViolationMonthRange,
+			Message: fmt.Sprintf("budget for %s is outside of %s to %s", m, v.b.earliestMonth, v.b.latestMonth),
+		})
+	}
+}
+
+// verifyBalance sums up TBB and the Available balances from the amounts
+// budgeted and the transactions on the accounts, rather than reading them
+// from the summary cache or the month index, which verifySummaries and
+// verifyIndexes check.
+func (v *verifier) verifyBalance() {
+	b := v.b
+	first, month := b.earliestMonth.LastMonth(), b.latestMonth
+	within := func(m YearMonth) bool {
+		return !first.Earlier(m) && !month.Later(m)
+	}
+
+	balances := zero
+	uncategorized := zero
+	tbb := zero
+	activities := map[string]decimal.Decimal{}
+	for _, a := range b.accounts {
+		for _, t := range a.transactions {
+			balances = balances.Add(t.amount)
+
+			m := YearMonthFromTime(t.date)
+			switch {
+			case t.category == nil:
+				if t.rel == nil {
+					uncategorized = uncategorized.Add(t.amount)
+				}
+			case t.category.uuid == b.tbb.uuid:
+				// TBB starts with the earliest month.
+				if within(m) && !first.Equal(m) {
+					tbb = tbb.Add(t.amount)
+				}
+			case within(m):
+				activities[t.category.uuid] = activities[t.category.uuid].Add(t.amount)
+			}
+		}
+	}
+
+	budget := tbb.Add(uncategorized)
+	for m := first; !month.Later(m); m = m.NextMonth() {
+		mb, ok := b.budgeted[m]
+		if !ok {
+			continue
+		}
+		for _, id := range sortedCategoryIDs(mb.Budgeted) {
+			amount := mb.Budgeted[id]
+			if !first.Equal(m) {
+				budget = budget.Sub(amount)
+			}
+			if c, ok := b.categories[id]; ok && !c.Equal(b.tbb) {
+				budget = budget.Add(amount)
+			}
+		}
+	}
+	for _, c := range b.categoryOrder {
+		if !c.Equal(b.tbb) {
+			budget = budget.Add(activities[c.uuid])
+		}
+	}
+
+	if !balances.Equal(budget) {
+		v.add(Violation{
+			Kind: ViolationBalance,
+			Message: fmt.Sprintf("accounts hold %s but TBB, Available and uncategorized transactions add up to %s as of %s",
+				balances.StringFixed(2), budget.StringFixed(2), month),
+		})
+	}
+}
+
+// verifySummaries compares TBB and the Available balances in the summary
+// cache with the ones summed up afresh from the amounts budgeted and the
+// month index. Only the first month a balance is off is reported, as the
+// months after it carry the difference forward.
+func (v *verifier) verifySummaries() {
+	b := v.b
+	months := []YearMonth{}
+	for m := b.earliestMonth; !b.latestMonth.Later(m); m = m.NextMonth() {
+		months = append(months, m)
+	}
+	if len(months) == 0 {
+		return
+	}
+
+	totals := make([]decimal.Decimal, len(months))
+	tbb := make([]decimal.Decimal, len(months))
+	running := zero
+	for i, m := range months {
+		totals[i] = zero
+		for _, amount := range b.budgeted[m].Budgeted {
+			totals[i] = totals[i].Add(amount)
+		}
+		running = running.Add(b.activities(m, b.tbb)).Sub(totals[i])
+		tbb[i] = running
+	}
+	for i := range months {
+		if !tbb[i].GreaterThan(zero) {
+			continue
+		}
+		for _, total := range totals[i+1:] {
+			tbb[i] = tbb[i].Sub(total)
+			if tbb[i].LessThan(zero) {
+				tbb[i] = zero
+			}
+		}
+	}
+
+	for i, m := range months {
+		if cached := b.toBeBudgeted(m); !cached.Equal(tbb[i]) {
+			v.add(Violation{
+				Kind:     ViolationSummary,
+				Category: b.tbb.uuid,
+				Message: fmt.Sprintf("cached To Be Budgeted for %s is %s but the budget and transactions add up to %s",
+					m, cached.StringFixed(2), tbb[i].StringFixed(2)),
+			})
+			break
+		}
+	}
+
+	for _, c := range b.categoryOrder {
+		prev := b.earliestMonth.LastMonth()
+		available := b.activities(prev, c)
+		if !c.Equal(b.tbb) {
+			available = available.Add(b.budgeted[prev].Budgeted[c.uuid])
+		}
+
+		for i, m := range months {
+			if mb, ok := b.budgeted[m]; ok {
+				if c.Equal(b.tbb) {
+					available = available.Add(tbb[i])
+				} else {
+					available = available.Add(mb.Budgeted[c.uuid])
+				}
+			}
+			available = available.Add(b.activities(m, c))
+
+			if cached := b.available(m, c); !cached.Equal(available) {
+				v.add(Violation{
+					Kind:     ViolationSummary,
+					Category: c.uuid,
+					Message: fmt.Sprintf("cached Available of %q for %s is %s but the budget and transactions add up to %s",
+						c.Name(), m, cached.StringFixed(2), available.StringFixed(2)),
+				})
+				break
+			}
+		}
+	}
+}
+
+func containsTransaction(transactions []*Transaction, t *Transaction) bool {
+	return countTransaction(transactions, t) > 0
+}
+
+func countTransaction(transactions []*Transaction, t *Transaction) int {
+	n := 0
+	for _, tt := range transactions {
+		if tt == t {
+			n++
+		}
+	}
+	return n
+}
+
+func sortedCategoryIDs(amounts map[string]decimal.Decimal) []string {
+	ids := make([]string, 0, len(amounts))
+	for id := range amounts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// budgetedMonths returns the months with a budget, earliest first.
+func (v *verifier) budgetedMonths() []YearMonth {
+	months := make([]YearMonth, 0, len(v.b.budgeted))
+	for m := range v.b.budgeted {
+		months = append(months, m)
+	}
+	sort.Slice(months, func(i, j int) bool { return months[j].Earlier(months[i]) })
+	return months
+}
@@ -0,0 +1,157 @@
+package budgeting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func verifyTestBudget() (*Budget, *Account, *Account, *Category) {
+	budget := NewBudget("My Budget")
+	jan := YearMonth{2018, time.January}
+	feb := YearMonth{2018, time.February}
+
+	acc, _ := budget.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	wallet, _ := budget.AddAccount("Wallet", dec("10.00"), date(2018, 1, 1))
+	food, _ := budget.AddCategory("Food")
+	bills, _ := budget.AddCategory("Bills")
+
+	budget.SetBudgeted(jan, food, dec("50.00"))
+	budget.SetBudgeted(feb, bills, dec("30.00"))
+
+	acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", food, nil)
+	acc.AddTransaction(date(2018, 1, 3), dec("-20.00"), "withdraw", nil, wallet)
+	wallet.AddTransaction(date(2018, 2, 4), dec("-12.50"), "electricity", bills, nil)
+	wallet.AddTransaction(date(2018, 2, 5), dec("-1.50"), "uncategorized", nil, nil)
+	acc.AddTransaction(date(2018, 3, 1), dec("40.00"), "salary", budget.TBBCategory(), nil)
+
+	return budget, acc, wallet, food
+}
+
+func violationKinds(violations []Violation) []ViolationKind {
+	kinds := []ViolationKind{}
+	for _, v := range violations {
+		kinds = append(kinds, v.Kind)
+	}
+	return kinds
+}
+
+func TestBudget_Verify(t *testing.T) {
+	assert := assert.New(t)
+
+	budget, _, _, _ := verifyTestBudget()
+	assert.Empty(budget.Verify())
+
+	assert.Empty(NewBudget("Empty").Verify())
+}
+
+func TestBudget_VerifyAfterEdits(t *testing.T) {
+	assert := assert.New(t)
+
+	budget, acc, wallet, food := verifyTestBudget()
+
+	tr, _ := acc.AddTransaction(date(2018, 1, 9), dec("-3.00"), "snack", food, nil)
+	tr.SetDate(date(2018, 4, 1))
+	tr.SetAmount(dec("-4.00"))
+	tr.SetCategory(nil)
+	wallet.transactions[1].SetAmount(dec("25.00"))
+	acc.DeleteTransaction(acc.transactions[1])
+	budget.MoveBudgeted(YearMonth{2018, time.April}, budget.TBBCategory(), food, dec("5.00"))
+
+	assert.Empty(budget.Verify())
+}
+
+func TestBudget_VerifyBalance(t *testing.T) {
+	assert := assert.New(t)
+
+	budget, _, _, _ := verifyTestBudget()
+
+	// Budget for categories which aren't part of the budget.
+	budget.budgeted[YearMonth{2018, time.February}].Budgeted["b"] = dec("4.00")
+	budget.budgeted[YearMonth{2018, time.February}].Budgeted["a"] = dec("6.00")
+
+	violations := budget.Verify()
+	assert.Equal([]ViolationKind{ViolationReference, ViolationReference, ViolationBalance}, violationKinds(violations))
+	assert.Equal("a", violations[0].Category)
+	assert.Equal("b", violations[1].Category)
+	assert.Equal("balance: accounts hold 131.00 but TBB, Available and uncategorized transactions add up to 121.00 as of 2018-03", violations[2].String())
+}
+
+func TestBudget_VerifySummaries(t *testing.T) {
+	assert := assert.New(t)
+
+	budget, acc, _, _ := verifyTestBudget()
+
+	// Change an amount without invalidating the cached summaries.
+	assert.Empty(budget.Verify())
+	acc.transactions[0].amount = dec("90.00")
+
+	violations := budget.Verify()
+	assert.Equal([]ViolationKind{ViolationSummary, ViolationSummary}, violationKinds(violations))
+	assert.Equal("summary: cached To Be Budgeted for 2018-01 is 30.00 but the budget and transactions add up to 20.00", violations[0].String())
+	assert.Equal(budget.TBBCategory().ID(), violations[1].Category)
+}
+
+func TestBudget_VerifyTransfer(t *testing.T) {
+	assert := assert.New(t)
+
+	budget, acc, wallet, _ := verifyTestBudget()
+	transfer := acc.transactions[2]
+
+	transfer.pair.amount = dec("21.00")
+	violations := budget.Verify()
+	assert.Equal([]ViolationKind{ViolationTransfer, ViolationTransfer, ViolationBalance}, violationKinds(violations))
+	assert.Equal(transfer.uuid, violations[0].Transaction)
+	assert.Equal("Savings", violations[0].Account)
+
+	transfer.pair.amount = dec("20.00")
+	wallet.transactions = removeTransaction(wallet.transactions, transfer.pair)
+	violations = budget.Verify()
+	assert.Contains(violationKinds(violations), ViolationTransfer)
+	assert.Equal("transfer: transaction "+transfer.uuid+` on "Savings" is paired with `+transfer.pair.uuid+` which is missing from "Wallet"`, violations[0].String())
+
+	budget, acc, _, _ = verifyTestBudget()
+	acc.transactions[2].pair = nil
+	assert.Contains(violationKinds(budget.Verify()), ViolationTransfer)
+}
+
+func TestBudget_VerifyIndex(t *testing.T) {
+	assert := assert.New(t)
+
+	budget, acc, _, food := verifyTestBudget()
+	lunch := acc.transactions[1]
+
+	// Change the category behind the index's back.
+	lunch.category = budget.categories[budget.tbb.uuid]
+	kinds := violationKinds(budget.Verify())
+	assert.Contains(kinds, ViolationIndex)
+	assert.NotContains(kinds, ViolationBalance)
+
+	budget, acc, _, food = verifyTestBudget()
+	lunch = acc.transactions[1]
+
+	// Change the date behind the index's back.
+	lunch.date = date(2018, 2, 2)
+	assert.Equal([]ViolationKind{ViolationIndex, ViolationIndex}, violationKinds(budget.Verify()))
+
+	budget, acc, _, food = verifyTestBudget()
+	acc.transactionCategory[food.uuid] = append(acc.transactionCategory[food.uuid], acc.transactionCategory[food.uuid]...)
+	assert.Equal([]ViolationKind{ViolationIndex}, violationKinds(budget.Verify()))
+}
+
+func TestBudget_VerifyReferencesAndMonths(t *testing.T) {
+	assert := assert.New(t)
+
+	budget, acc, _, _ := verifyTestBudget()
+	other := NewBudget("Other")
+	foreign, _ := other.AddCategory("Foreign")
+
+	budget.budgeted[YearMonth{2018, time.January}].Budgeted[foreign.uuid] = dec("0.00")
+	kinds := violationKinds(budget.Verify())
+	assert.Equal([]ViolationKind{ViolationReference}, kinds)
+
+	budget, acc, _, _ = verifyTestBudget()
+	acc.transactions[1].date = date(2019, 1, 1)
+	assert.Contains(violationKinds(budget.Verify()), ViolationMonthRange)
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+var accountCommands = map[string]command{
+	"list": {
+		summary: "list the accounts of a budget",
+		run:     runAccountList,
+	},
+	"add": {
+		summary: "add an account: add [-balance AMOUNT] [-date DATE] NAME",
+		run:     runAccountAdd,
+	},
+	"close": {
+		summary: "close an account: close ACCOUNT",
+		run:     runAccountClose,
+	},
+	"reopen": {
+		summary: "reopen a closed account: reopen ACCOUNT",
+		run:     runAccountReopen,
+	},
+}
+
+func accountTable(accounts ...*budgeting.Account) ([]accountView, *table) {
+	views := []accountView{}
+	t := newTable("ID", "NAME", "BALANCE", "CLOSED")
+	for _, a := range accounts {
+		v := newAccountView(a)
+		views = append(views, v)
+
+		closed := ""
+		if v.Closed {
+			closed = "yes"
+		}
+		t.add(v.ID, v.Name, v.Balance, closed)
+	}
+	return views, t
+}
+
+func runAccountList(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("account list", stderr)
+	f := addBudgetFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	_, b, err := f.open()
+	if err != nil {
+		return err
+	}
+
+	views, t := accountTable(b.Accounts()...)
+	return f.output(stdout).print(views, t)
+}
+
+func runAccountAdd(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("account add", stderr)
+	f := addBudgetFlags(fs)
+	balance := fs.String("balance", "0", "starting balance")
+	date := fs.String("date", today(), "date of the starting balance")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: account add [flags] NAME")
+	}
+
+	amount, err := parseAmount(*balance)
+	if err != nil {
+		return err
+	}
+	d, err := parseDate(*date)
+	if err != nil {
+		return err
+	}
+
+	var account *budgeting.Account
+	_, err = f.update(func(b *budgeting.Budget) error {
+		var err error
+		account, err = b.AddAccount(fs.Arg(0), amount, d)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	views, t := accountTable(account)
+	return f.output(stdout).print(views[0], t)
+}
+
+func runAccountClose(args []string, stdout, stderr io.Writer) error {
+	return setAccountClosed("close", true, args, stdout, stderr)
+}
+
+func runAccountReopen(args []string, stdout, stderr io.Writer) error {
+	return setAccountClosed("reopen", false, args, stdout, stderr)
+}
+
+func setAccountClosed(name string, closed bool, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("account "+name, stderr)
+	f := addBudgetFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: account %s [flags] ACCOUNT", name)
+	}
+
+	var account *budgeting.Account
+	_, err := f.update(func(b *budgeting.Budget) error {
+		a, err := findAccount(b, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		if closed {
+			a.Close()
+		} else {
+			a.Reopen()
+		}
+		account = a
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	views, t := accountTable(account)
+	return f.output(stdout).print(views[0], t)
+}
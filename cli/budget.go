@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/shopspring/decimal"
+)
+
+var budgetCommands = map[string]command{
+	"list": {
+		summary: "list the stored budgets",
+		run:     runBudgetList,
+	},
+	"create": {
+		summary: "create a budget: create NAME",
+		run:     runBudgetCreate,
+	},
+	"delete": {
+		summary: "delete a budget: delete BUDGET",
+		run:     runBudgetDelete,
+	},
+}
+
+// budgetFlags are the flags shared by the commands working on a budget.
+type budgetFlags struct {
+	store  *string
+	budget *string
+	json   *bool
+}
+
+func addBudgetFlags(fs *flag.FlagSet) budgetFlags {
+	return budgetFlags{
+		store:  fs.String("store", storeDir(), "directory containing the stored budgets"),
+		budget: fs.String("budget", "", "ID or name of the budget (default the only stored budget)"),
+		json:   fs.Bool("json", false, "write JSON instead of a table"),
+	}
+}
+
+// open opens the store and finds the budget the flags refer to.
+func (f budgetFlags) open() (storage.Store, *budgeting.Budget, error) {
+	s, err := storage.NewFileStore(*f.store)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b, err := findBudget(s, *f.budget)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, b, nil
+}
+
+// update applies fn to the budget the flags refer to, like storage.Update.
+func (f budgetFlags) update(fn func(b *budgeting.Budget) error) (*budgeting.Budget, error) {
+	s, b, err := f.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.Update(s, b.ID(), fn)
+}
+
+func (f budgetFlags) output(stdout io.Writer) output {
+	return output{w: stdout, json: *f.json}
+}
+
+// findBudget returns the budget with the given ID or name. Without either,
+// it returns the only budget in the store.
+func findBudget(s storage.Store, ref string) (*budgeting.Budget, error) {
+	if ref != "" {
+		if b, err := s.Load(ref); err == nil {
+			return b, nil
+		}
+	}
+
+	ids, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *budgeting.Budget
+	for _, id := range ids {
+		b, err := s.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		if ref != "" && !strings.EqualFold(b.Name(), ref) {
+			continue
+		}
+		if found != nil {
+			if ref == "" {
+				return nil, fmt.Errorf("there are several budgets, choose one with -budget")
+			}
+			return nil, fmt.Errorf("several budgets are named %q, use the ID instead", ref)
+		}
+		found = b
+	}
+
+	if found == nil {
+		if ref == "" {
+			return nil, fmt.Errorf("there are no budgets, create one with \"budget create\"")
+		}
+		return nil, fmt.Errorf("no budget %q", ref)
+	}
+	return found, nil
+}
+
+// findAccount returns the account of the budget with the given ID or name.
+func findAccount(b *budgeting.Budget, ref string) (*budgeting.Account, error) {
+	if a, err := b.Account(ref); err == nil {
+		return a, nil
+	}
+
+	var found *budgeting.Account
+	for _, a := range b.Accounts() {
+		if !strings.EqualFold(a.Name(), ref) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("several accounts are named %q, use the ID instead", ref)
+		}
+		found = a
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("no account %q", ref)
+	}
+	return found, nil
+}
+
+// findCategory returns the category of the budget with the given ID or name.
+func findCategory(b *budgeting.Budget, ref string) (*budgeting.Category, error) {
+	if c, err := b.Category(ref); err == nil {
+		return c, nil
+	}
+
+	var found *budgeting.Category
+	for _, c := range b.Categories() {
+		if !strings.EqualFold(c.Name(), ref) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("several categories are named %q, use the ID instead", ref)
+		}
+		found = c
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("no category %q", ref)
+	}
+	return found, nil
+}
+
+func parseAmount(s string) (decimal.Decimal, error) {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("invalid amount %q", s)
+	}
+	return d, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, want one like %s", s, dateLayout)
+	}
+	return t, nil
+}
+
+func today() string {
+	return now().Format(dateLayout)
+}
+
+func runBudgetList(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("budget list", stderr)
+	dir := fs.String("store", storeDir(), "directory containing the stored budgets")
+	asJSON := fs.Bool("json", false, "write JSON instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, err := storage.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	ids, err := s.List()
+	if err != nil {
+		return err
+	}
+
+	views := []budgetView{}
+	t := newTable("ID", "NAME")
+	for _, id := range ids {
+		b, err := s.Load(id)
+		if err != nil {
+			return err
+		}
+		views = append(views, newBudgetView(b))
+		t.add(b.ID(), b.Name())
+	}
+
+	return output{w: stdout, json: *asJSON}.print(views, t)
+}
+
+func runBudgetCreate(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("budget create", stderr)
+	dir := fs.String("store", storeDir(), "directory containing the stored budgets")
+	asJSON := fs.Bool("json", false, "write JSON instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: budget create [flags] NAME")
+	}
+
+	s, err := storage.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	b := budgeting.NewBudget(fs.Arg(0))
+	if err := s.Save(b, 0); err != nil {
+		return err
+	}
+
+	t := newTable("ID", "NAME")
+	t.add(b.ID(), b.Name())
+	return output{w: stdout, json: *asJSON}.print(newBudgetView(b), t)
+}
+
+func runBudgetDelete(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("budget delete", stderr)
+	dir := fs.String("store", storeDir(), "directory containing the stored budgets")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: budget delete [flags] BUDGET")
+	}
+
+	s, err := storage.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	b, err := findBudget(s, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return s.Delete(b.ID())
+}
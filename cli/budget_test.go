@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// useTestStore points the commands at a temporary store, and returns a
+// function removing it.
+func useTestStore(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "budget-app-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old, had := os.LookupEnv("BUDGET_STORE")
+	os.Setenv("BUDGET_STORE", dir)
+
+	return func() {
+		if had {
+			os.Setenv("BUDGET_STORE", old)
+		} else {
+			os.Unsetenv("BUDGET_STORE")
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+// run runs the command line and returns what it wrote to stdout, failing
+// the test if it fails.
+func run(t *testing.T, args ...string) string {
+	var stdout, stderr bytes.Buffer
+	if code := Run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("%s: exit code %d: %s", strings.Join(args, " "), code, stderr.String())
+	}
+	return stdout.String()
+}
+
+// runJSON runs the command line and decodes what it wrote to stdout into v.
+func runJSON(t *testing.T, v interface{}, args ...string) {
+	if err := json.Unmarshal([]byte(run(t, args...)), v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runFails runs the command line, which must fail, and returns what it
+// wrote to stderr.
+func runFails(t *testing.T, args ...string) string {
+	var stdout, stderr bytes.Buffer
+	if code := Run(args, &stdout, &stderr); code == 0 {
+		t.Fatalf("%s: succeeded: %s", strings.Join(args, " "), stdout.String())
+	}
+	return stderr.String()
+}
+
+func TestBudgetCommands(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	assert.Contains(runFails(t, "account", "list"), "there are no budgets")
+
+	var home budgetView
+	runJSON(t, &home, "budget", "create", "-json", "Home")
+	assert.Equal("Home", home.Name)
+	run(t, "budget", "create", "Work")
+
+	var budgets []budgetView
+	runJSON(t, &budgets, "budget", "list", "-json")
+	assert.Len(budgets, 2)
+	assert.Contains(run(t, "budget", "list"), "Home")
+
+	assert.Contains(runFails(t, "account", "list"), "choose one with -budget")
+
+	var account accountView
+	runJSON(t, &account, "account", "add", "-budget", "home", "-json", "-balance", "100", "-date", "2018-01-01", "Checking")
+	assert.Equal("100.00", account.Balance)
+
+	var accounts []accountView
+	runJSON(t, &accounts, "account", "list", "-budget", home.ID, "-json")
+	assert.Equal([]accountView{account}, accounts)
+
+	run(t, "account", "close", "-budget", "Home", "checking")
+	runJSON(t, &accounts, "account", "list", "-budget", "Home", "-json")
+	assert.True(accounts[0].Closed)
+	assert.Contains(runFails(t, "account", "close", "-budget", "Home", "Savings"), `no account "Savings"`)
+
+	run(t, "category", "add", "-budget", "Home", "-group", "Everyday", "Food")
+	var categories []categoryView
+	runJSON(t, &categories, "category", "list", "-budget", "Home", "-json")
+	assert.Len(categories, 2)
+	assert.Equal("Food", categories[1].Name)
+	assert.Equal("Everyday", categories[1].Group)
+
+	run(t, "budget", "delete", "Work")
+	runJSON(t, &budgets, "budget", "list", "-json")
+	assert.Len(budgets, 1)
+
+	// With a single budget, -budget can be left out.
+	assert.Contains(run(t, "category", "list"), "Food")
+
+	assert.Contains(runFails(t, "budget", "rename"), `unknown command "budget rename"`)
+}
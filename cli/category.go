@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+var categoryCommands = map[string]command{
+	"list": {
+		summary: "list the categories of a budget",
+		run:     runCategoryList,
+	},
+	"add": {
+		summary: "add a category: add [-group GROUP] NAME",
+		run:     runCategoryAdd,
+	},
+}
+
+func categoryTable(categories ...*budgeting.Category) ([]categoryView, *table) {
+	views := []categoryView{}
+	t := newTable("ID", "GROUP", "NAME")
+	for _, c := range categories {
+		v := newCategoryView(c)
+		views = append(views, v)
+		t.add(v.ID, v.Group, v.Name)
+	}
+	return views, t
+}
+
+func runCategoryList(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("category list", stderr)
+	f := addBudgetFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	_, b, err := f.open()
+	if err != nil {
+		return err
+	}
+
+	views, t := categoryTable(b.Categories()...)
+	return f.output(stdout).print(views, t)
+}
+
+func runCategoryAdd(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("category add", stderr)
+	f := addBudgetFlags(fs)
+	group := fs.String("group", "", "group to list the category under")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: category add [flags] NAME")
+	}
+
+	var category *budgeting.Category
+	_, err := f.update(func(b *budgeting.Budget) error {
+		var err error
+		category, err = b.AddCategory(fs.Arg(0), budgeting.WithGroup(*group))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	views, t := categoryTable(category)
+	return f.output(stdout).print(views[0], t)
+}
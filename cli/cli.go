@@ -0,0 +1,159 @@
+// Package cli implements the budget-app command line.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// defaultStoreDir is where budgets are stored unless -store or
+// BUDGET_STORE says otherwise.
+const defaultStoreDir = "budgets"
+
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) error
+}
+
+var commands = map[string]command{
+	"budget": {
+		summary: "list, create and delete budgets",
+		run:     group("budget", budgetCommands),
+	},
+	"account": {
+		summary: "list, add, close and reopen accounts",
+		run:     group("account", accountCommands),
+	},
+	"category": {
+		summary: "list and add categories",
+		run:     group("category", categoryCommands),
+	},
+	"transaction": {
+		summary: "list, add, edit and delete transactions",
+		run:     group("transaction", transactionCommands),
+	},
+	"import": {
+		summary: "import transactions from bank statements and other apps",
+		run:     group("import", importCommands),
+	},
+	"export": {
+		summary: "write accounts and budgets for other apps",
+		run:     group("export", exportCommands),
+	},
+	"month": {
+		summary: "show what is budgeted, spent and available in a month",
+		run:     runMonth,
+	},
+	"set-budgeted": {
+		summary: "set the amount budgeted for a category in a month",
+		run:     runSetBudgeted,
+	},
+	"move": {
+		summary: "move budgeted money between categories in a month",
+		run:     runMove,
+	},
+	"tui": {
+		summary: "browse and budget a month full-screen",
+		run:     runTUI,
+	},
+	"migrate": {
+		summary: "upgrade every stored budget to the current schema version",
+		run:     runMigrate,
+	},
+	"serve": {
+		summary: "serve the stored budgets over a REST/JSON HTTP API",
+		run:     runServe,
+	},
+	"verify": {
+		summary: "check stored budgets for broken invariants",
+		run:     runVerify,
+	},
+}
+
+// Run executes the command line given in args (without the program name)
+// and returns the process exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	if err := cmd.run(args[1:], stdout, stderr); err != nil {
+		if err == flag.ErrHelp {
+			return 2
+		}
+		fmt.Fprintf(stderr, "%s: %v\n", args[0], err)
+		return 1
+	}
+
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: budget-app <command> [flags]")
+	fmt.Fprintln(w)
+	listCommands(w, commands)
+}
+
+func listCommands(w io.Writer, commands map[string]command) {
+	fmt.Fprintln(w, "commands:")
+
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].summary)
+	}
+}
+
+// group returns a command running one of the given subcommands, such as
+// "budget list".
+func group(name string, subcommands map[string]command) func(args []string, stdout, stderr io.Writer) error {
+	return func(args []string, stdout, stderr io.Writer) error {
+		if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			groupUsage(stderr, name, subcommands)
+			return flag.ErrHelp
+		}
+
+		cmd, ok := subcommands[args[0]]
+		if !ok {
+			fmt.Fprintf(stderr, "unknown command %q\n\n", name+" "+args[0])
+			groupUsage(stderr, name, subcommands)
+			return flag.ErrHelp
+		}
+
+		return cmd.run(args[1:], stdout, stderr)
+	}
+}
+
+func groupUsage(w io.Writer, name string, subcommands map[string]command) {
+	fmt.Fprintf(w, "usage: budget-app %s <command> [flags]\n", name)
+	fmt.Fprintln(w)
+	listCommands(w, subcommands)
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+func storeDir() string {
+	if dir := os.Getenv("BUDGET_STORE"); dir != "" {
+		return dir
+	}
+	return defaultStoreDir
+}
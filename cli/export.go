@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/export"
+)
+
+var exportCommands = map[string]command{
+	"qif": {
+		summary: "write the transactions of an account as QIF: qif -account ACCOUNT",
+		run:     runExportQIF,
+	},
+	"transactions": {
+		summary: "write the transactions of every account as CSV, or JSON with -json",
+		run:     runExportTransactions,
+	},
+	"months": {
+		summary: "write what is budgeted, spent and available per category and month as CSV, or JSON with -json",
+		run:     runExportMonths,
+	},
+	"ledger": {
+		summary: "write the budget as a ledger-cli journal",
+		run:     journalCommand(export.Ledger),
+	},
+	"hledger": {
+		summary: "write the budget as an hledger journal",
+		run:     journalCommand(export.HLedger),
+	},
+	"beancount": {
+		summary: "write the budget as a beancount journal: beancount -currency CURRENCY",
+		run:     journalCommand(export.Beancount),
+	},
+}
+
+// listFlag is a flag which can be given several times.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// filterFlags are the flags choosing what to export.
+type filterFlags struct {
+	accounts   listFlag
+	categories listFlag
+	from, to   *string
+}
+
+func addFilterFlags(fs *flag.FlagSet, accounts bool) *filterFlags {
+	f := &filterFlags{
+		from: fs.String("from", "", "only export from this date on, like 2006-01-02"),
+		to:   fs.String("to", "", "only export up to this date, included"),
+	}
+	if accounts {
+		fs.Var(&f.accounts, "account", "only export this account; can be given several times")
+	}
+	fs.Var(&f.categories, "category", "only export this category; can be given several times")
+	return f
+}
+
+// filter returns the export filter of the flags for the budget.
+func (f *filterFlags) filter(b *budgeting.Budget) (export.Filter, error) {
+	var filter export.Filter
+	for _, ref := range f.accounts {
+		a, err := findAccount(b, ref)
+		if err != nil {
+			return filter, err
+		}
+		filter.Accounts = append(filter.Accounts, a)
+	}
+	for _, ref := range f.categories {
+		c, err := findCategory(b, ref)
+		if err != nil {
+			return filter, err
+		}
+		filter.Categories = append(filter.Categories, c)
+	}
+
+	var err error
+	if *f.from != "" {
+		if filter.From, err = parseDate(*f.from); err != nil {
+			return filter, err
+		}
+	}
+	if *f.to != "" {
+		if filter.To, err = parseDate(*f.to); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
+
+func runExportTransactions(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("export transactions", stderr)
+	f := addBudgetFlags(fs)
+	filter := addFilterFlags(fs, true)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: export transactions [-account ACCOUNT] [-category CATEGORY] [-from DATE] [-to DATE] [-json]")
+	}
+
+	_, b, err := f.open()
+	if err != nil {
+		return err
+	}
+
+	ff, err := filter.filter(b)
+	if err != nil {
+		return err
+	}
+
+	if *f.json {
+		return export.TransactionsJSON(stdout, b, ff)
+	}
+	return export.TransactionsCSV(stdout, b, ff)
+}
+
+func runExportMonths(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("export months", stderr)
+	f := addBudgetFlags(fs)
+	filter := addFilterFlags(fs, false)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: export months [-category CATEGORY] [-from DATE] [-to DATE] [-json]")
+	}
+
+	_, b, err := f.open()
+	if err != nil {
+		return err
+	}
+
+	ff, err := filter.filter(b)
+	if err != nil {
+		return err
+	}
+
+	if *f.json {
+		return export.MonthsJSON(stdout, b, ff)
+	}
+	return export.MonthsCSV(stdout, b, ff)
+}
+
+// journalCommand returns the command writing the budget as a journal of the
+// format.
+func journalCommand(format export.JournalFormat) func([]string, io.Writer, io.Writer) error {
+	return func(args []string, stdout, stderr io.Writer) error {
+		fs := newFlagSet("export "+string(format), stderr)
+		f := addBudgetFlags(fs)
+		currency := fs.String("currency", "", "currency written after every amount")
+		var liabilities listFlag
+		fs.Var(&liabilities, "liability", "account which is a debt, like a credit card; can be given several times")
+		envelopes := false
+		if format != export.Beancount {
+			fs.BoolVar(&envelopes, "envelopes", false, "write what is budgeted and available as virtual postings")
+		}
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() != 0 {
+			if format == export.Beancount {
+				return fmt.Errorf("usage: export beancount -currency CURRENCY [-liability ACCOUNT]")
+			}
+			return fmt.Errorf("usage: export %s [-currency CURRENCY] [-liability ACCOUNT] [-envelopes]", format)
+		}
+
+		_, b, err := f.open()
+		if err != nil {
+			return err
+		}
+
+		o := export.JournalOptions{Format: format, Currency: *currency, Envelopes: envelopes}
+		for _, ref := range liabilities {
+			a, err := findAccount(b, ref)
+			if err != nil {
+				return err
+			}
+			o.Liabilities = append(o.Liabilities, a)
+		}
+
+		return export.Journal(stdout, b, o)
+	}
+}
+
+func runExportQIF(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("export qif", stderr)
+	f := addBudgetFlags(fs)
+	account := fs.String("account", "", "account to export (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *account == "" || fs.NArg() != 0 {
+		return fmt.Errorf("usage: export qif -account ACCOUNT")
+	}
+
+	_, b, err := f.open()
+	if err != nil {
+		return err
+	}
+
+	a, err := findAccount(b, *account)
+	if err != nil {
+		return err
+	}
+
+	return export.QIF(stdout, a)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportQIF(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	run(t, "budget", "create", "Home")
+	run(t, "account", "add", "-balance", "100", "-date", "2018-01-01", "Checking")
+	run(t, "category", "add", "Food")
+	run(t, "transaction", "add", "-account", "Checking", "-amount", "-12.50", "-date", "2018-01-03", "-category", "Food", "-description", "Lunch")
+
+	assert.Equal("!Account\nNChecking\nTBank\n^\n!Type:Bank\n"+
+		"D01/01/2018\nT100.00\nPStarting balance\nLTo Be Budgeted\n^\n"+
+		"D01/03/2018\nT-12.50\nPLunch\nLFood\n^\n", run(t, "export", "qif", "-account", "checking"))
+
+	assert.Contains(runFails(t, "export", "qif"), "usage: export qif")
+}
+
+func TestExportTransactionsAndMonths(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	run(t, "budget", "create", "Home")
+	run(t, "account", "add", "-balance", "100", "-date", "2018-01-01", "Checking")
+	run(t, "account", "add", "-date", "2018-01-01", "Wallet")
+	run(t, "category", "add", "Food")
+	run(t, "set-budgeted", "2018-01", "Food", "30")
+	run(t, "transaction", "add", "-account", "Checking", "-amount", "-12.50", "-date", "2018-01-03", "-category", "Food", "-description", "Lunch")
+	run(t, "transaction", "add", "-account", "Wallet", "-amount", "-5", "-date", "2018-02-03", "-category", "Food", "-description", "Cafe")
+
+	out := run(t, "export", "transactions", "-account", "Checking", "-from", "2018-01-02")
+	assert.Contains(out, "id,date,account,category_group,category,transfer_account,description,amount,cleared,approved,import_id\n")
+	assert.Contains(out, ",2018-01-03,Checking,,Food,,Lunch,-12.50,false,true,\n")
+	assert.NotContains(out, "Starting balance")
+
+	var transactions []map[string]interface{}
+	runJSON(t, &transactions, "export", "transactions", "-json", "-category", "food", "-account", "checking", "-account", "wallet")
+	assert.Len(transactions, 2)
+	assert.Equal("Wallet", transactions[1]["account"])
+
+	assert.Equal("month,category_group,category,budgeted,activities,available\n"+
+		"2018-01,,Food,30.00,-12.50,17.50\n"+
+		"2018-02,,Food,0.00,-5.00,12.50\n", run(t, "export", "months"))
+
+	var months []map[string]interface{}
+	runJSON(t, &months, "export", "months", "-json", "-from", "2018-02-01")
+	assert.Len(months, 1)
+	assert.Equal("70.00", months[0]["tbb"])
+
+	assert.Contains(runFails(t, "export", "transactions", "-from", "yesterday"), "invalid date")
+	assert.Contains(runFails(t, "export", "months", "-account", "Checking"), "flag provided but not defined")
+}
+
+func TestExportJournals(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	run(t, "budget", "create", "Home")
+	run(t, "account", "add", "-balance", "100", "-date", "2018-01-01", "Checking")
+	run(t, "account", "add", "-date", "2018-01-01", "Visa")
+	run(t, "category", "add", "Food")
+	run(t, "set-budgeted", "2018-01", "Food", "30")
+	run(t, "transaction", "add", "-account", "Visa", "-amount", "-12.50", "-date", "2018-01-03", "-category", "Food", "-description", "Lunch")
+
+	out := run(t, "export", "ledger", "-liability", "visa", "-envelopes")
+	assert.Contains(out, "2018/01/03 Lunch\n    Expenses:Food            12.50\n    Liabilities:Visa         -12.50\n    (Budget:Food)            -12.50\n")
+	assert.Contains(out, "    [Budget:Food]            30.00\n")
+
+	assert.Contains(run(t, "export", "hledger"), "account Assets:Visa  ; type: Asset\n")
+
+	out = run(t, "export", "beancount", "-currency", "EUR")
+	assert.Contains(out, "2018-01-01 open Equity:Opening-Balances EUR\n")
+	assert.Contains(out, "    Assets:Visa              -12.50 EUR\n")
+
+	assert.Contains(runFails(t, "export", "beancount"), "beancount journals need a currency")
+	assert.Contains(runFails(t, "export", "beancount", "-currency", "EUR", "-envelopes"), "flag provided but not defined")
+	assert.Contains(runFails(t, "export", "ledger", "-liability", "Amex"), `no account "Amex"`)
+}
@@ -0,0 +1,704 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/importer"
+)
+
+var importCommands = map[string]command{
+	"csv": {
+		summary: "import a bank's CSV export: csv -account ACCOUNT [-profile NAME] FILE",
+		run:     runImportCSV,
+	},
+	"ofx": {
+		summary: "import an OFX or QFX statement: ofx -account ACCOUNT FILE",
+		run:     runImportOFX,
+	},
+	"camt": {
+		summary: "import an ISO 20022 camt.053 statement: camt -account ACCOUNT FILE",
+		run:     statementCommand("camt", importer.ReadCAMT),
+	},
+	"mt940": {
+		summary: "import a SWIFT MT940 statement: mt940 -account ACCOUNT FILE",
+		run:     statementCommand("mt940", importer.ReadMT940),
+	},
+	"qif": {
+		summary: "import the bank and cash accounts of a QIF file: qif [-account ACCOUNT] FILE",
+		run:     runImportQIF,
+	},
+	"ynab": {
+		summary: "move a YNAB budget over from its register and budget exports: ynab [-budgeted FILE] REGISTER",
+		run:     runImportYNAB,
+	},
+	"gnucash": {
+		summary: "move the accounts and transactions of a GnuCash book over: gnucash FILE",
+		run:     runImportGnuCash,
+	},
+	"profiles": {
+		summary: "list the saved CSV profiles",
+		run:     runImportProfiles,
+	},
+}
+
+// profilesPath is where CSV profiles are saved in the store.
+func profilesPath(dir string) string {
+	// Files starting with a dot aren't budgets to the store.
+	return filepath.Join(dir, ".import-profiles.json")
+}
+
+// matchFlags are the flags changing how imported transactions are matched
+// with those entered by hand.
+type matchFlags struct {
+	days       *int
+	similarity *float64
+	off        *bool
+}
+
+func addMatchFlags(fs *flag.FlagSet) matchFlags {
+	return matchFlags{
+		days:       fs.Int("match-days", importer.DefaultMatchDays, "days apart transactions entered by hand may be to match imported ones"),
+		similarity: fs.Float64("match-similarity", importer.DefaultMatchSimilarity, "how similar descriptions must be to match, from 0 to 1"),
+		off:        fs.Bool("no-match", false, "add every transaction not imported before, even if it was entered by hand"),
+	}
+}
+
+func (m matchFlags) options() []importer.Option {
+	if *m.off {
+		return []importer.Option{importer.NoMatching()}
+	}
+	return []importer.Option{importer.MatchDays(*m.days), importer.MatchSimilarity(*m.similarity)}
+}
+
+// importStatement imports the statement into the account the flags refer
+// to, and prints the report.
+func importStatement(f budgetFlags, m matchFlags, account string, s *importer.Statement, stdout io.Writer) error {
+	return importStatements(f, m, []*importer.Statement{s}, func(b *budgeting.Budget, _ *importer.Statement) (*budgeting.Account, error) {
+		return findAccount(b, account)
+	}, stdout)
+}
+
+// importStatements imports the statements in one update of the budget,
+// each into the account chosen for it, and prints the reports.
+func importStatements(
+	f budgetFlags,
+	m matchFlags,
+	statements []*importer.Statement,
+	choose func(b *budgeting.Budget, s *importer.Statement) (*budgeting.Account, error),
+	stdout io.Writer) error {
+
+	var reports []*importer.Report
+	_, err := f.update(func(b *budgeting.Budget) error {
+		reports = nil
+
+		accounts := []*budgeting.Account{}
+		for _, s := range statements {
+			a, err := choose(b, s)
+			if err != nil {
+				return err
+			}
+			accounts = append(accounts, a)
+		}
+
+		for i, s := range statements {
+			reports = append(reports, importer.Import(accounts[i], s, m.options()...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	o := f.output(stdout)
+	if len(reports) == 1 {
+		if o.json {
+			return o.print(newReportView(reports[0]), nil)
+		}
+		printReport(o.w, reports[0])
+		return nil
+	}
+
+	if o.json {
+		views := []reportView{}
+		for _, r := range reports {
+			views = append(views, newReportView(r))
+		}
+		return o.print(views, nil)
+	}
+	for _, r := range reports {
+		fmt.Fprintf(o.w, "%s: ", r.Account.Name())
+		printReport(o.w, r)
+	}
+	return nil
+}
+
+type reportView struct {
+	Account        string              `json:"account"`
+	Imported       []transactionView   `json:"imported"`
+	Matched        []matchView         `json:"matched"`
+	Categories     []string            `json:"categories,omitempty"`
+	Skipped        []importer.Problem  `json:"skipped"`
+	Failed         []importer.Problem  `json:"failed"`
+	Reconciliation *reconciliationView `json:"reconciliation,omitempty"`
+	Opening        *reconciliationView `json:"opening,omitempty"`
+}
+
+type matchView struct {
+	Line        int             `json:"line"`
+	Transaction transactionView `json:"transaction"`
+}
+
+type reconciliationView struct {
+	Date       string `json:"date"`
+	Statement  string `json:"statement"`
+	Account    string `json:"account"`
+	Difference string `json:"difference"`
+}
+
+func newReportView(r *importer.Report) reportView {
+	views, _ := transactionTable(r.Imported...)
+	v := reportView{
+		Account:  r.Account.Name(),
+		Imported: views,
+		Matched:  []matchView{},
+		Skipped:  r.Skipped,
+		Failed:   r.Failed,
+	}
+	for _, m := range r.Matched {
+		v.Matched = append(v.Matched, matchView{Line: m.Line, Transaction: newTransactionView(m.Transaction)})
+	}
+	for _, c := range r.Categories {
+		v.Categories = append(v.Categories, c.Name())
+	}
+	if rec := r.Reconciliation; rec != nil {
+		v.Reconciliation = newReconciliationView(rec)
+	}
+	if rec := r.Opening; rec != nil {
+		v.Opening = newReconciliationView(rec)
+	}
+	return v
+}
+
+func newReconciliationView(rec *importer.Reconciliation) *reconciliationView {
+	return &reconciliationView{
+		Date:       rec.Date.Format(dateLayout),
+		Statement:  amount(rec.Statement),
+		Account:    amount(rec.Account),
+		Difference: amount(rec.Difference()),
+	}
+}
+
+func printReport(w io.Writer, r *importer.Report) {
+	fmt.Fprintln(w, r.Summary())
+	for _, m := range r.Matched {
+		t := m.Transaction
+		fmt.Fprintf(w, "  matched line %d: %s %s %s\n", m.Line, t.Date().Format(dateLayout), amount(t.Amount()), t.Description())
+	}
+	for _, p := range r.Skipped {
+		fmt.Fprintf(w, "  skipped %s\n", p)
+	}
+	for _, p := range r.Failed {
+		fmt.Fprintf(w, "  failed %s\n", p)
+	}
+	if len(r.Categories) > 0 {
+		names := []string{}
+		for _, c := range r.Categories {
+			names = append(names, c.Name())
+		}
+		fmt.Fprintf(w, "Created categories: %s\n", strings.Join(names, ", "))
+	}
+
+	if rec := r.Opening; rec != nil {
+		day := rec.Date.Format(dateLayout)
+		if rec.Balanced() {
+			fmt.Fprintf(w, "The balance on %s matches the bank's opening balance: %s\n", day, amount(rec.Statement))
+		} else {
+			fmt.Fprintf(w, "The balance on %s is %s but the bank's opening balance is %s: the difference of %s is from before the statement\n",
+				day, amount(rec.Account), amount(rec.Statement), amount(rec.Difference()))
+		}
+	}
+	if rec := r.Reconciliation; rec != nil {
+		day := rec.Date.Format(dateLayout)
+		if rec.Balanced() {
+			fmt.Fprintf(w, "The balance on %s matches the bank's: %s\n", day, amount(rec.Statement))
+		} else {
+			fmt.Fprintf(w, "The balance on %s is %s but the bank's is %s: a transaction of %s would reconcile the account\n",
+				day, amount(rec.Account), amount(rec.Statement), amount(rec.Difference()))
+		}
+	}
+}
+
+// openFile opens the named file, or standard input for "-".
+func openFile(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+func runImportCSV(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("import csv", stderr)
+	f := addBudgetFlags(fs)
+	m := addMatchFlags(fs)
+	account := fs.String("account", "", "account to import into (required)")
+	profileName := fs.String("profile", "", "saved profile to read the file with")
+	save := fs.Bool("save", false, "save the profile with the flags below under the -profile name")
+
+	// The flags below override the profile.
+	fs.String("delimiter", ",", "field delimiter")
+	fs.Int("skip", 0, "rows to skip before the header or first transaction")
+	fs.Bool("header", false, "the first row names the columns")
+	fs.String("date", "", "date column, by name with -header or by number from 1")
+	fs.String("date-format", "YYYY-MM-DD", "date format, made of YYYY, YY, MM, M, DD and D")
+	fs.String("amount", "", "column of signed amounts")
+	fs.String("inflow", "", "column of money entering the account")
+	fs.String("outflow", "", "column of money leaving the account")
+	fs.Bool("negate", false, "flip the sign of amounts")
+	fs.String("decimal", ".", "decimal separator")
+	fs.String("thousands", "", "thousands separator")
+	fs.String("description", "", "comma-separated columns making up the description")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *account == "" || fs.NArg() != 1 || *save && *profileName == "" {
+		return fmt.Errorf("usage: import csv -account ACCOUNT [-profile NAME [-save]] [flags] FILE")
+	}
+
+	profiles, err := importer.LoadProfiles(profilesPath(*f.store))
+	if err != nil {
+		return err
+	}
+
+	p := importer.Profile{Name: *profileName}
+	if *profileName != "" {
+		saved, ok := profiles[*profileName]
+		if !ok && !*save {
+			return fmt.Errorf("no profile %q", *profileName)
+		}
+		if ok {
+			p = saved
+		}
+	}
+
+	fs.Visit(func(fl *flag.Flag) {
+		v := fl.Value.String()
+		switch fl.Name {
+		case "delimiter":
+			p.Delimiter = v
+		case "skip":
+			p.SkipLines, _ = strconv.Atoi(v)
+		case "header":
+			p.Header = v == "true"
+		case "date":
+			p.Date = v
+		case "date-format":
+			p.DateFormat = v
+		case "amount":
+			p.Amount = v
+		case "inflow":
+			p.Inflow = v
+		case "outflow":
+			p.Outflow = v
+		case "negate":
+			p.Negate = v == "true"
+		case "decimal":
+			p.DecimalSeparator = v
+		case "thousands":
+			p.ThousandsSeparator = v
+		case "description":
+			p.Description = nil
+			for _, col := range strings.Split(v, ",") {
+				if col = strings.TrimSpace(col); col != "" {
+					p.Description = append(p.Description, col)
+				}
+			}
+		}
+	})
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if *save {
+		profiles[p.Name] = p
+		if err := profiles.Save(profilesPath(*f.store)); err != nil {
+			return err
+		}
+	}
+
+	in, err := openFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	s, err := importer.ReadCSV(in, p)
+	if err != nil {
+		return err
+	}
+
+	return importStatement(f, m, *account, s, stdout)
+}
+
+func runImportOFX(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("import ofx", stderr)
+	f := addBudgetFlags(fs)
+	m := addMatchFlags(fs)
+	account := fs.String("account", "", "account to import into (required)")
+	number := fs.String("statement", "", "account number at the bank to import the statement of, for files with several")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *account == "" || fs.NArg() != 1 {
+		return fmt.Errorf("usage: import ofx -account ACCOUNT [-statement NUMBER] FILE")
+	}
+
+	in, err := openFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	statements, err := importer.ReadOFX(in)
+	if err != nil {
+		return err
+	}
+
+	s, err := chooseStatement(statements, *number)
+	if err != nil {
+		return err
+	}
+
+	return importStatement(f, m, *account, s, stdout)
+}
+
+// statementCommand returns the command importing a bank statement of the
+// format, which read reads.
+func statementCommand(format string, read func(io.Reader) ([]*importer.Statement, error)) func([]string, io.Writer, io.Writer) error {
+	return func(args []string, stdout, stderr io.Writer) error {
+		fs := newFlagSet("import "+format, stderr)
+		f := addBudgetFlags(fs)
+		m := addMatchFlags(fs)
+		account := fs.String("account", "", "account to import into (required)")
+		number := fs.String("statement", "", "account number at the bank to import the statement of, for files with several")
+		valueDates := fs.Bool("value-date", false, "date transactions with their value dates rather than when the bank booked them")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *account == "" || fs.NArg() != 1 {
+			return fmt.Errorf("usage: import %s -account ACCOUNT [-statement NUMBER] [-value-date] FILE", format)
+		}
+
+		in, err := openFile(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		statements, err := read(in)
+		if err != nil {
+			return err
+		}
+
+		s, err := chooseStatement(statements, *number)
+		if err != nil {
+			return err
+		}
+		if *valueDates {
+			s.UseValueDates()
+		}
+
+		return importStatement(f, m, *account, s, stdout)
+	}
+}
+
+// chooseStatement returns the statement of the account with the number, or
+// the only statement without a number.
+func chooseStatement(statements []*importer.Statement, number string) (*importer.Statement, error) {
+	numbers := []string{}
+	for _, s := range statements {
+		if number != "" && s.Account == number {
+			return s, nil
+		}
+		numbers = append(numbers, s.Account)
+	}
+
+	if number == "" && len(statements) == 1 {
+		return statements[0], nil
+	}
+	if number == "" {
+		return nil, fmt.Errorf("the file has statements of accounts %s: choose one with -statement", strings.Join(numbers, ", "))
+	}
+	return nil, fmt.Errorf("no statement of account %q in the file", number)
+}
+
+func runImportQIF(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("import qif", stderr)
+	f := addBudgetFlags(fs)
+	m := addMatchFlags(fs)
+	account := fs.String("account", "", "account to import into, for files of a single account")
+	dayFirst := fs.Bool("day-first", false, "dates are written day first, like 31/01/2018")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: import qif [-account ACCOUNT] [-day-first] FILE")
+	}
+
+	in, err := openFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	statements, err := importer.ReadQIF(in, *dayFirst)
+	if err != nil {
+		return err
+	}
+
+	// Files of several accounts name them, and they are imported into the
+	// accounts of the same names.
+	return importStatements(f, m, statements, func(b *budgeting.Budget, s *importer.Statement) (*budgeting.Account, error) {
+		name := s.Account
+		if *account != "" && (len(statements) == 1 || name == "") {
+			name = *account
+		}
+		if name == "" {
+			return nil, fmt.Errorf("the file doesn't name its account, choose one with -account")
+		}
+		return findAccount(b, name)
+	}, stdout)
+}
+
+func runImportYNAB(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("import ynab", stderr)
+	f := addBudgetFlags(fs)
+	budgeted := fs.String("budgeted", "", "YNAB's budget export, to budget the same amounts")
+	dayFirst := fs.Bool("day-first", false, "dates are written day first, like 31/01/2018")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: import ynab [-budgeted FILE] [-day-first] REGISTER")
+	}
+
+	in, err := openFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	register, err := importer.ReadYNABRegister(in, *dayFirst)
+	if err != nil {
+		return err
+	}
+
+	var budget *importer.BudgetFile
+	if *budgeted != "" {
+		in, err := os.Open(*budgeted)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		if budget, err = importer.ReadYNABBudget(in); err != nil {
+			return err
+		}
+	}
+
+	var r *importer.MigrationReport
+	_, err = f.update(func(b *budgeting.Budget) error {
+		r = importer.ImportYNAB(b, register, budget)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	o := f.output(stdout)
+	if o.json {
+		return o.print(newMigrationView(r), nil)
+	}
+	printMigration(o.w, r)
+	return nil
+}
+
+func runImportGnuCash(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("import gnucash", stderr)
+	f := addBudgetFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: import gnucash FILE")
+	}
+
+	in, err := openFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	book, err := importer.ReadGnuCash(in)
+	if err != nil {
+		return err
+	}
+
+	var r *importer.MigrationReport
+	_, err = f.update(func(b *budgeting.Budget) error {
+		r = importer.ImportGnuCash(b, book)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	o := f.output(stdout)
+	if o.json {
+		return o.print(newMigrationView(r), nil)
+	}
+	printMigration(o.w, r)
+	return nil
+}
+
+type migrationView struct {
+	Accounts     []accountView      `json:"accounts"`
+	Categories   []categoryView     `json:"categories"`
+	Transactions []reportView       `json:"transactions"`
+	Budgeted     int                `json:"budgeted"`
+	Skipped      []importer.Problem `json:"skipped"`
+	Failed       []importer.Problem `json:"failed"`
+	Differences  []differenceView   `json:"differences"`
+}
+
+type differenceView struct {
+	Line      int    `json:"line"`
+	Month     string `json:"month"`
+	Category  string `json:"category"`
+	Expected  string `json:"expected"`
+	Available string `json:"available"`
+}
+
+func newMigrationView(r *importer.MigrationReport) migrationView {
+	v := migrationView{
+		Accounts:     []accountView{},
+		Categories:   []categoryView{},
+		Transactions: []reportView{},
+		Budgeted:     r.Budgeted,
+		Skipped:      r.Skipped,
+		Failed:       r.Failed,
+		Differences:  []differenceView{},
+	}
+	for _, a := range r.Accounts {
+		v.Accounts = append(v.Accounts, newAccountView(a))
+	}
+	for _, c := range r.Categories {
+		v.Categories = append(v.Categories, newCategoryView(c))
+	}
+	for _, t := range r.Transactions {
+		v.Transactions = append(v.Transactions, newReportView(t))
+	}
+	for _, d := range r.Differences {
+		v.Differences = append(v.Differences, differenceView{
+			Line:      d.Line,
+			Month:     d.Month.String(),
+			Category:  d.Category.Name(),
+			Expected:  amount(d.Expected),
+			Available: amount(d.Available),
+		})
+	}
+	return v
+}
+
+func printMigration(w io.Writer, r *importer.MigrationReport) {
+	if len(r.Accounts) > 0 {
+		names := []string{}
+		for _, a := range r.Accounts {
+			names = append(names, a.Name())
+		}
+		fmt.Fprintf(w, "Created accounts: %s\n", strings.Join(names, ", "))
+	}
+	if len(r.Categories) > 0 {
+		names := []string{}
+		for _, c := range r.Categories {
+			names = append(names, c.Name())
+		}
+		fmt.Fprintf(w, "Created categories: %s\n", strings.Join(names, ", "))
+	}
+
+	// The categories are listed once above rather than under the account
+	// whose transactions created them.
+	for _, t := range r.Transactions {
+		fmt.Fprintf(w, "%s: %s\n", t.Account.Name(), t.Summary())
+		for _, p := range t.Skipped {
+			fmt.Fprintf(w, "  skipped %s\n", p)
+		}
+		for _, p := range t.Failed {
+			fmt.Fprintf(w, "  failed %s\n", p)
+		}
+	}
+
+	if r.Budgeted > 0 {
+		fmt.Fprintf(w, "Budgeted %d category months\n", r.Budgeted)
+	}
+	if len(r.Skipped)+len(r.Failed) > 0 {
+		fmt.Fprintln(w, "Not imported:")
+	}
+	for _, p := range r.Skipped {
+		fmt.Fprintf(w, "  skipped %s\n", p)
+	}
+	for _, p := range r.Failed {
+		fmt.Fprintf(w, "  failed %s\n", p)
+	}
+
+	if len(r.Differences) > 0 {
+		fmt.Fprintln(w, "Available amounts differing from YNAB's:")
+		for _, d := range r.Differences {
+			fmt.Fprintf(w, "  line %d: %s in %s is %s here but %s in YNAB\n",
+				d.Line, d.Category.Name(), d.Month, amount(d.Available), amount(d.Expected))
+		}
+	}
+}
+
+func runImportProfiles(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("import profiles", stderr)
+	dir := fs.String("store", storeDir(), "directory containing the stored budgets")
+	asJSON := fs.Bool("json", false, "write JSON instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	profiles, err := importer.LoadProfiles(profilesPath(*dir))
+	if err != nil {
+		return err
+	}
+
+	list := []importer.Profile{}
+	t := newTable("NAME", "DATE", "AMOUNT", "DESCRIPTION")
+	for _, name := range profiles.Names() {
+		p := profiles[name]
+		list = append(list, p)
+
+		amount := p.Amount
+		if amount == "" {
+			amount = p.Inflow + " / " + p.Outflow
+		}
+		t.add(p.Name, p.Date+" ("+dateFormatOrDefault(p.DateFormat)+")", amount, strings.Join(p.Description, ", "))
+	}
+
+	return output{w: stdout, json: *asJSON}.print(list, t)
+}
+
+func dateFormatOrDefault(format string) string {
+	if format == "" {
+		return "YYYY-MM-DD"
+	}
+	return format
+}
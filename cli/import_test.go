@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/importer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportCSV(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	run(t, "budget", "create", "Home")
+	run(t, "account", "add", "-date", "2018-01-01", "Checking")
+
+	file := filepath.Join(os.Getenv("BUDGET_STORE"), "statement.csv")
+	statement := "Date;Payee;Amount\n" +
+		"05/01/2018;Supermarket;-42,10\n" +
+		"06/01/2018;Employer;1.500,00\n" +
+		"07/01/2018;Cafe;abc\n"
+	assert.Nil(ioutil.WriteFile(file, []byte(statement), 0600))
+
+	assert.Contains(runFails(t, "import", "csv", "-account", "Checking", "-profile", "bank", file), `no profile "bank"`)
+
+	out := run(t, "import", "csv", "-account", "checking", "-profile", "bank", "-save",
+		"-delimiter", ";", "-header", "-date", "Date", "-date-format", "DD/MM/YYYY",
+		"-amount", "Amount", "-decimal", ",", "-thousands", ".", "-description", "Payee", file)
+	assert.Contains(out, "2 imported, 0 skipped, 1 failed")
+	assert.Contains(out, `failed line 4: invalid amount "abc"`)
+
+	// Rows already imported are recognised, rather than matched.
+	var report reportView
+	runJSON(t, &report, "import", "csv", "-json", "-account", "Checking", "-profile", "bank", file)
+	assert.Len(report.Imported, 0)
+	assert.Len(report.Matched, 0)
+	assert.Len(report.Skipped, 2)
+	assert.Len(report.Failed, 1)
+
+	var transactions []transactionView
+	runJSON(t, &transactions, "transaction", "list", "-json", "-account", "Checking")
+	assert.Len(transactions, 3)
+
+	run(t, "transaction", "add", "-account", "Checking", "-amount", "-3.20", "-date", "2018-01-08", "-description", "Bakery")
+	assert.Nil(ioutil.WriteFile(file, []byte("Date;Payee;Amount\n08/01/2018;Bakery;-3,20\n"), 0600))
+	out = run(t, "import", "csv", "-no-match", "-account", "Checking", "-profile", "bank", file)
+	assert.Contains(out, "1 imported, 0 skipped, 0 failed")
+
+	assert.Contains(run(t, "import", "profiles"), "bank")
+}
+
+func TestImportOFX(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	run(t, "budget", "create", "Home")
+	run(t, "account", "add", "-balance", "100", "-date", "2018-01-01", "Checking")
+
+	file := filepath.Join(os.Getenv("BUDGET_STORE"), "statement.ofx")
+	statement := `<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKACCTFROM><ACCTID>0001234</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN><DTPOSTED>20180105<TRNAMT>-42.10<FITID>1<NAME>Supermarket</STMTTRN>
+<STMTTRN><DTPOSTED>20180106<TRNAMT>-7.90<FITID>2<NAME>Cafe</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>40.00<DTASOF>20180131</LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>
+`
+	assert.Nil(ioutil.WriteFile(file, []byte(statement), 0600))
+
+	out := run(t, "import", "ofx", "-account", "Checking", file)
+	assert.Contains(out, "2 imported, 0 skipped, 0 failed")
+	assert.Contains(out, "The balance on 2018-01-31 is 50.00 but the bank's is 40.00: a transaction of -10.00 would reconcile the account")
+
+	var report reportView
+	runJSON(t, &report, "import", "ofx", "-json", "-account", "Checking", "-statement", "0001234", file)
+	assert.Len(report.Imported, 0)
+	assert.Equal([]importer.Problem{{Line: 4, Reason: "already imported 1"}, {Line: 5, Reason: "already imported 2"}}, report.Skipped)
+	assert.Equal("-10.00", report.Reconciliation.Difference)
+
+	assert.Contains(runFails(t, "import", "ofx", "-account", "Checking", "-statement", "999", file), `no statement of account "999"`)
+}
+
+func TestImportQIF(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	run(t, "budget", "create", "Home")
+	run(t, "account", "add", "-date", "2018-01-01", "Checking")
+	run(t, "account", "add", "-date", "2018-01-01", "Wallet")
+
+	file := filepath.Join(os.Getenv("BUDGET_STORE"), "export.qif")
+	qif := "!Account\nNChecking\n^\n!Type:Bank\n" +
+		"D05/01/2018\nT-100.00\nPSupermarket\nSFood\n$-60.00\nSHousehold\n$-40.00\n^\n" +
+		"D06/01/2018\nT-50.00\nL[Wallet]\n^\n" +
+		"!Account\nNWallet\n^\n!Type:Cash\n" +
+		"D06/01/2018\nT50.00\nL[Checking]\n^\n"
+	assert.Nil(ioutil.WriteFile(file, []byte(qif), 0600))
+
+	out := run(t, "import", "qif", "-day-first", file)
+	assert.Contains(out, "Checking: 3 imported, 0 skipped, 0 failed\nCreated categories: Food, Household\n")
+	assert.Contains(out, "Wallet: 0 imported, 1 skipped, 0 failed\n  skipped line 21: transfer from Checking already imported\n")
+
+	var balances []accountView
+	runJSON(t, &balances, "account", "list", "-json")
+	assert.Equal("-150.00", balances[0].Balance)
+	assert.Equal("50.00", balances[1].Balance)
+
+	unnamed := filepath.Join(os.Getenv("BUDGET_STORE"), "wallet.qif")
+	assert.Nil(ioutil.WriteFile(unnamed, []byte("!Type:Cash\nD1/7/18\nT-4.50\nPCafe\n^\n"), 0600))
+	assert.Contains(runFails(t, "import", "qif", unnamed), "choose one with -account")
+	assert.Contains(runFails(t, "import", "qif", "-account", "Savings", unnamed), `no account "Savings"`)
+	assert.Contains(run(t, "import", "qif", "-account", "Wallet", unnamed), "1 imported")
+}
+
+func TestImportYNAB(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	run(t, "budget", "create", "Home")
+
+	register := filepath.Join(os.Getenv("BUDGET_STORE"), "register.csv")
+	assert.Nil(ioutil.WriteFile(register, []byte(
+		"Account,Date,Payee,Category Group,Category,Memo,Outflow,Inflow,Cleared\n"+
+			"Checking,2018-01-01,Starting Balance,Inflow,Ready to Assign,,0.00,1000.00,Reconciled\n"+
+			"Checking,2018-01-05,Supermarket,Everyday,Groceries,,42.10,0.00,Cleared\n"+
+			"Checking,2018-01-07,Cafe,Everyday,Restaurants,,4.50,0.00,Uncleared\n"), 0600))
+
+	budget := filepath.Join(os.Getenv("BUDGET_STORE"), "budget.csv")
+	assert.Nil(ioutil.WriteFile(budget, []byte(
+		"Month,Category Group/Category,Category Group,Category,Assigned,Activity,Available\n"+
+			"Jan 2018,Everyday: Groceries,Everyday,Groceries,300.00,-42.10,257.90\n"+
+			"Jan 2018,Everyday: Restaurants,Everyday,Restaurants,0.00,-4.50,0.00\n"), 0600))
+
+	out := run(t, "import", "ynab", "-budgeted", budget, register)
+	assert.Contains(out, "Created accounts: Checking\nCreated categories: Groceries, Restaurants\n")
+	assert.Contains(out, "Checking: 2 imported, 0 skipped, 0 failed\n")
+	assert.Contains(out, "Budgeted 1 category months\n")
+	assert.Contains(out, "  line 3: Restaurants in 2018-01 is -4.50 here but 0.00 in YNAB\n")
+
+	var month monthView
+	runJSON(t, &month, "month", "-json", "2018-01")
+	assert.Equal("700.00", month.TBB)
+	assert.Equal("Everyday", month.Categories[0].Group)
+	assert.Equal("257.90", month.Categories[0].Available)
+
+	var report migrationView
+	runJSON(t, &report, "import", "ynab", "-json", register)
+	assert.Len(report.Accounts, 0)
+	assert.Len(report.Transactions, 0)
+	assert.Equal([]importer.Problem{{Line: 2, Reason: `account "Checking" already exists`}}, report.Failed)
+}
+
+func TestImportCAMTAndMT940(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	run(t, "budget", "create", "Home")
+	run(t, "account", "add", "-balance", "100", "-date", "2018-01-01", "Checking")
+
+	camt := filepath.Join(os.Getenv("BUDGET_STORE"), "statement.xml")
+	assert.Nil(ioutil.WriteFile(camt, []byte(`<Document><BkToCstmrStmt><Stmt>
+<Acct><Id><IBAN>DE89370400440532013000</IBAN></Id></Acct>
+<Bal><Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp><Amt>100.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2018-01-02</Dt></Dt></Bal>
+<Bal><Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp><Amt>57.90</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2018-01-31</Dt></Dt></Bal>
+<Ntry><Amt>42.10</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts><BookgDt><Dt>2018-01-05</Dt></BookgDt><ValDt><Dt>2018-01-04</Dt></ValDt>
+<AcctSvcrRef>1</AcctSvcrRef><AddtlNtryInf>Supermarket</AddtlNtryInf></Ntry>
+</Stmt></BkToCstmrStmt></Document>
+`), 0600))
+
+	out := run(t, "import", "camt", "-account", "Checking", "-value-date", camt)
+	assert.Contains(out, "1 imported, 0 skipped, 0 failed\n")
+	assert.Contains(out, "The balance on 2018-01-01 matches the bank's opening balance: 100.00\n")
+	assert.Contains(out, "The balance on 2018-01-31 matches the bank's: 57.90\n")
+
+	var transactions []transactionView
+	runJSON(t, &transactions, "transaction", "list", "-json", "-account", "Checking")
+	assert.Equal("2018-01-04", transactions[1].Date)
+
+	mt940 := filepath.Join(os.Getenv("BUDGET_STORE"), "statement.sta")
+	assert.Nil(ioutil.WriteFile(mt940, []byte(":20:STARTUMSE\n:25:12345678\n:60F:C180131EUR60,00\n"+
+		":61:1802010201D4,50NMSC//2\n:86:Cafe\n:62F:C180201EUR55,50\n-\n"), 0600))
+
+	var report reportView
+	runJSON(t, &report, "import", "mt940", "-json", "-account", "Checking", "-statement", "12345678", mt940)
+	assert.Equal("Cafe", report.Imported[0].Description)
+	assert.Equal("2.10", report.Opening.Difference)
+	assert.Equal("2.10", report.Reconciliation.Difference)
+
+	assert.Contains(runFails(t, "import", "mt940", "-account", "Checking", camt), "not an MT940 file")
+}
+
+func TestImportGnuCash(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	run(t, "budget", "create", "Home")
+
+	file := filepath.Join(os.Getenv("BUDGET_STORE"), "books.gnucash")
+	assert.Nil(ioutil.WriteFile(file, []byte(`<?xml version="1.0" encoding="utf-8" ?>
+<gnc-v2>
+<gnc:book version="2.0.0">
+<gnc:account version="2.0.0"><act:name>Root Account</act:name><act:id type="guid">root</act:id><act:type>ROOT</act:type></gnc:account>
+<gnc:account version="2.0.0"><act:name>Checking</act:name><act:id type="guid">checking</act:id><act:type>BANK</act:type><act:parent type="guid">root</act:parent></gnc:account>
+<gnc:account version="2.0.0"><act:name>Expenses</act:name><act:id type="guid">expenses</act:id><act:type>EXPENSE</act:type><act:parent type="guid">root</act:parent></gnc:account>
+<gnc:account version="2.0.0"><act:name>Groceries</act:name><act:id type="guid">groceries</act:id><act:type>EXPENSE</act:type><act:parent type="guid">expenses</act:parent></gnc:account>
+<gnc:account version="2.0.0"><act:name>Opening Balances</act:name><act:id type="guid">opening</act:id><act:type>EQUITY</act:type><act:parent type="guid">root</act:parent></gnc:account>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">t1</trn:id>
+  <trn:date-posted><ts:date>2018-01-01 10:59:00 +0000</ts:date></trn:date-posted>
+  <trn:description>Opening Balance</trn:description>
+  <trn:splits>
+    <trn:split><split:reconciled-state>y</split:reconciled-state><split:value>100000/100</split:value><split:quantity>100000/100</split:quantity><split:account type="guid">checking</split:account></trn:split>
+    <trn:split><split:reconciled-state>n</split:reconciled-state><split:value>-100000/100</split:value><split:quantity>-100000/100</split:quantity><split:account type="guid">opening</split:account></trn:split>
+  </trn:splits>
+</gnc:transaction>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">t2</trn:id>
+  <trn:date-posted><ts:date>2018-01-05 10:59:00 +0000</ts:date></trn:date-posted>
+  <trn:description>Supermarket</trn:description>
+  <trn:splits>
+    <trn:split><split:reconciled-state>c</split:reconciled-state><split:value>-4210/100</split:value><split:quantity>-4210/100</split:quantity><split:account type="guid">checking</split:account></trn:split>
+    <trn:split><split:reconciled-state>n</split:reconciled-state><split:value>4210/100</split:value><split:quantity>4210/100</split:quantity><split:account type="guid">groceries</split:account></trn:split>
+  </trn:splits>
+</gnc:transaction>
+<gnc:schedxaction version="2.0.0"><sx:name>Rent</sx:name></gnc:schedxaction>
+</gnc:book>
+</gnc-v2>
+`), 0600))
+
+	out := run(t, "import", "gnucash", file)
+	assert.Contains(out, "Created accounts: Checking\nCreated categories: Groceries\n")
+	assert.Contains(out, "Checking: 1 imported, 0 skipped, 0 failed\n")
+	assert.Contains(out, "Not imported:\n  skipped line 27: GnuCash scheduled transactions aren't imported\n")
+	assert.NotContains(out, "Budgeted")
+
+	var accounts []accountView
+	runJSON(t, &accounts, "account", "list", "-json")
+	assert.Equal("957.90", accounts[0].Balance)
+
+	var report migrationView
+	runJSON(t, &report, "import", "gnucash", "-json", file)
+	assert.Equal([]importer.Problem{{Line: 9, Reason: `account "Checking" already exists`}}, report.Failed)
+
+	assert.Nil(ioutil.WriteFile(file, []byte("Date,Amount\n"), 0600))
+	assert.Contains(runFails(t, "import", "gnucash", file), "not a GnuCash book")
+}
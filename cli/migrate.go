@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hasyimibhar/budget-app/storage"
+)
+
+func runMigrate(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("migrate", stderr)
+	dir := fs.String("store", storeDir(), "directory containing the stored budgets")
+	dryRun := fs.Bool("dry-run", false, "report what would be migrated without writing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, err := storage.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	results, err := s.Migrate(*dryRun)
+	if err != nil {
+		return err
+	}
+
+	migrated, failed := 0, 0
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			failed++
+			fmt.Fprintf(stdout, "%s: error: %v\n", r.ID, r.Err)
+		case r.Migrated:
+			migrated++
+			fmt.Fprintf(stdout, "%s: schema v%d -> v%d\n", r.ID, r.From, r.To)
+		default:
+			fmt.Fprintf(stdout, "%s: up to date (v%d)\n", r.ID, r.To)
+		}
+	}
+
+	suffix := ""
+	if *dryRun {
+		suffix = " (dry run, nothing written)"
+	}
+	fmt.Fprintf(stdout, "%d of %d budget(s) migrated%s\n", migrated, len(results), suffix)
+
+	if failed > 0 {
+		return fmt.Errorf("%d budget(s) failed to migrate", failed)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "budget-app-cli")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	s, err := storage.NewFileStore(dir)
+	assert.Nil(err)
+
+	b := budgeting.NewBudget("My Budget")
+	b.AddAccount("Savings", decimal.New(10000, -2), time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Nil(s.Save(b, 0))
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"migrate", "-store", dir, "-dry-run"}, &stdout, &stderr)
+	assert.Equal(0, code, stderr.String())
+	assert.Contains(stdout.String(), b.ID()+": up to date")
+	assert.Contains(stdout.String(), "0 of 1 budget(s) migrated (dry run, nothing written)")
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 2, Run([]string{"frobnicate"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `unknown command "frobnicate"`)
+}
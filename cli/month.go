@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+func parseMonth(s string) (budgeting.YearMonth, error) {
+	m, err := budgeting.ParseYearMonth(s)
+	if err != nil {
+		return budgeting.YearMonth{}, fmt.Errorf("invalid month %q, want one like 2006-01", s)
+	}
+	return m, nil
+}
+
+func printMonth(o output, b *budgeting.Budget, month budgeting.YearMonth) error {
+	v := newMonthView(b, month)
+
+	t := newTable("CATEGORY", "BUDGETED", "ACTIVITY", "AVAILABLE")
+	for _, c := range v.Categories {
+		t.add(c.Name, c.Budgeted, c.Activities, c.Available)
+	}
+	t.add("")
+	t.add("To Be Budgeted", "", "", v.TBB)
+
+	return o.print(v, t)
+}
+
+func runMonth(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("month", stderr)
+	f := addBudgetFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("usage: month [flags] [MONTH]")
+	}
+
+	month := budgeting.YearMonthFromTime(now())
+	if fs.NArg() == 1 {
+		var err error
+		if month, err = parseMonth(fs.Arg(0)); err != nil {
+			return err
+		}
+	}
+
+	_, b, err := f.open()
+	if err != nil {
+		return err
+	}
+
+	return printMonth(f.output(stdout), b, month)
+}
+
+func runSetBudgeted(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("set-budgeted", stderr)
+	f := addBudgetFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 3 {
+		return fmt.Errorf("usage: set-budgeted [flags] MONTH CATEGORY AMOUNT")
+	}
+
+	month, err := parseMonth(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	amount, err := parseAmount(fs.Arg(2))
+	if err != nil {
+		return err
+	}
+
+	b, err := f.update(func(b *budgeting.Budget) error {
+		c, err := findCategory(b, fs.Arg(1))
+		if err != nil {
+			return err
+		}
+
+		return b.SetBudgeted(month, c, amount)
+	})
+	if err != nil {
+		return err
+	}
+
+	return printMonth(f.output(stdout), b, month)
+}
+
+func runMove(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("move", stderr)
+	f := addBudgetFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 4 {
+		return fmt.Errorf("usage: move [flags] MONTH FROM TO AMOUNT")
+	}
+
+	month, err := parseMonth(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	amount, err := parseAmount(fs.Arg(3))
+	if err != nil {
+		return err
+	}
+
+	b, err := f.update(func(b *budgeting.Budget) error {
+		from, err := findCategory(b, fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		to, err := findCategory(b, fs.Arg(2))
+		if err != nil {
+			return err
+		}
+
+		return b.MoveBudgeted(month, from, to, amount)
+	})
+	if err != nil {
+		return err
+	}
+
+	return printMonth(f.output(stdout), b, month)
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonthCommands(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	now = func() time.Time { return time.Date(2018, time.January, 15, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	run(t, "budget", "create", "Home")
+	run(t, "account", "add", "-balance", "1000", "Checking")
+	run(t, "category", "add", "Food")
+	run(t, "category", "add", "Rent")
+	run(t, "transaction", "add", "-account", "Checking", "-amount", "-30", "-category", "Food")
+
+	var month monthView
+	runJSON(t, &month, "set-budgeted", "-json", "2018-01", "Food", "200")
+	assert.Equal("800.00", month.TBB)
+	assert.Equal(monthCategoryView{
+		ID:         month.Categories[0].ID,
+		Name:       "Food",
+		Budgeted:   "200.00",
+		Activities: "-30.00",
+		Available:  "170.00",
+	}, month.Categories[0])
+
+	runJSON(t, &month, "move", "-json", "2018-01", "Food", "Rent", "50")
+	assert.Equal("150.00", month.Categories[0].Budgeted)
+	assert.Equal("50.00", month.Categories[1].Budgeted)
+
+	assert.Contains(runFails(t, "set-budgeted", "2018-01", "To Be Budgeted", "10"), "cannot budget To Be Budgeted")
+	assert.Contains(runFails(t, "move", "2018-13", "Food", "Rent", "50"), "invalid month")
+
+	// The current month by default.
+	out := run(t, "month")
+	assert.Contains(out, "Food      150.00    -30.00    120.00")
+	assert.Contains(out, "To Be Budgeted")
+	assert.Contains(out, "800.00")
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+)
+
+// dateLayout is the format of dates on the command line and in output.
+const dateLayout = "2006-01-02"
+
+// now returns the current time. Tests replace it.
+var now = time.Now
+
+// output writes the results of a command as an aligned table or, with
+// -json, as JSON.
+type output struct {
+	w    io.Writer
+	json bool
+}
+
+// table is the tabular form of a result.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func newTable(header ...string) *table {
+	return &table{header: header}
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// print writes v as JSON or t as a table.
+func (o output) print(v interface{}, t *table) error {
+	if o.json {
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+type budgetView struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version int64  `json:"version"`
+}
+
+func newBudgetView(b *budgeting.Budget) budgetView {
+	return budgetView{
+		ID:      b.ID(),
+		Name:    b.Name(),
+		Version: b.Version(),
+	}
+}
+
+type accountView struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Balance string `json:"balance"`
+	Closed  bool   `json:"closed"`
+}
+
+func newAccountView(a *budgeting.Account) accountView {
+	return accountView{
+		ID:      a.ID(),
+		Name:    a.Name(),
+		Balance: amount(a.Balance()),
+		Closed:  a.Closed(),
+	}
+}
+
+type categoryView struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Group string `json:"group,omitempty"`
+}
+
+func newCategoryView(c *budgeting.Category) categoryView {
+	return categoryView{
+		ID:    c.ID(),
+		Name:  c.Name(),
+		Group: c.Group(),
+	}
+}
+
+type transactionView struct {
+	ID              string             `json:"id"`
+	Account         string             `json:"account"`
+	Date            string             `json:"date"`
+	Amount          string             `json:"amount"`
+	Description     string             `json:"description"`
+	Category        string             `json:"category,omitempty"`
+	TransferAccount string             `json:"transfer_account,omitempty"`
+	ImportID        string             `json:"import_id,omitempty"`
+	Cleared         bool               `json:"cleared"`
+	Approved        bool               `json:"approved"`
+	Match           *matchedImportView `json:"match,omitempty"`
+}
+
+// matchedImportView is the imported transaction a transaction was matched
+// with.
+type matchedImportView struct {
+	ImportID    string `json:"import_id,omitempty"`
+	Date        string `json:"date"`
+	Description string `json:"description"`
+}
+
+func newTransactionView(t *budgeting.Transaction) transactionView {
+	v := transactionView{
+		ID:          t.ID(),
+		Account:     t.Account().Name(),
+		Date:        t.Date().Format(dateLayout),
+		Amount:      amount(t.Amount()),
+		Description: t.Description(),
+		ImportID:    t.ImportID(),
+		Cleared:     t.Cleared(),
+		Approved:    t.Approved(),
+	}
+
+	if c := t.Category(); c != nil {
+		v.Category = c.Name()
+	}
+	if m := t.Match(); m != nil {
+		v.Match = &matchedImportView{
+			ImportID:    m.ImportID,
+			Date:        m.Date.Format(dateLayout),
+			Description: m.Description,
+		}
+	}
+	if a := t.TransferAccount(); a != nil {
+		v.TransferAccount = a.Name()
+	}
+
+	return v
+}
+
+type monthView struct {
+	Month      string              `json:"month"`
+	TBB        string              `json:"tbb"`
+	Categories []monthCategoryView `json:"categories"`
+}
+
+type monthCategoryView struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Group      string `json:"group,omitempty"`
+	Budgeted   string `json:"budgeted"`
+	Activities string `json:"activities"`
+	Available  string `json:"available"`
+}
+
+func newMonthView(b *budgeting.Budget, month budgeting.YearMonth) monthView {
+	v := monthView{
+		Month:      month.String(),
+		TBB:        amount(b.TBB(month)),
+		Categories: []monthCategoryView{},
+	}
+
+	tbb := b.TBBCategory()
+	for _, c := range b.Categories() {
+		if c.Equal(tbb) {
+			continue
+		}
+		v.Categories = append(v.Categories, monthCategoryView{
+			ID:         c.ID(),
+			Name:       c.Name(),
+			Group:      c.Group(),
+			Budgeted:   amount(b.Budgeted(month, c)),
+			Activities: amount(b.Activities(month, c)),
+			Available:  amount(b.Available(month, c)),
+		})
+	}
+
+	return v
+}
+
+func amount(d decimal.Decimal) string {
+	return d.StringFixed(2)
+}
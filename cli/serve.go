@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"github.com/hasyimibhar/budget-app/api"
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/hasyimibhar/budget-app/users"
+)
+
+func runServe(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("serve", stderr)
+	dir := fs.String("store", storeDir(), "directory containing the stored budgets")
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	usersFile := fs.String("users", "", "file containing the users and their sessions (default <store>/.users.json)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, err := storage.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	if *usersFile == "" {
+		// Files starting with a dot aren't budgets to the store.
+		*usersFile = filepath.Join(*dir, ".users.json")
+	}
+	us, err := users.NewFileStore(*usersFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "serving budgets from %s on http://%s\n", *dir, *addr)
+	return http.ListenAndServe(*addr, api.NewServer(s, users.NewService(us)))
+}
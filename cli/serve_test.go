@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServe_BadAddress(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "budget-app-cli")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(1, Run([]string{"serve", "-store", dir, "-addr", "localhost:-1"}, &stdout, &stderr))
+	assert.Contains(stderr.String(), "serve: ")
+}
@@ -0,0 +1,352 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+var transactionCommands = map[string]command{
+	"list": {
+		summary: "list transactions, optionally of one account, category or month",
+		run:     runTransactionList,
+	},
+	"add": {
+		summary: "add a transaction: add -account ACCOUNT -amount AMOUNT [flags]",
+		run:     runTransactionAdd,
+	},
+	"edit": {
+		summary: "change a transaction: edit [flags] TRANSACTION",
+		run:     runTransactionEdit,
+	},
+	"delete": {
+		summary: "delete a transaction: delete TRANSACTION",
+		run:     runTransactionDelete,
+	},
+	"approve": {
+		summary: "approve imported transactions: approve [-category CATEGORY] [-reject-match] TRANSACTION... or approve -all",
+		run:     runTransactionApprove,
+	},
+}
+
+func transactionTable(transactions ...*budgeting.Transaction) ([]transactionView, *table) {
+	views := []transactionView{}
+	t := newTable("ID", "DATE", "ACCOUNT", "CATEGORY", "AMOUNT", "DESCRIPTION", "REVIEW")
+	for _, tr := range transactions {
+		v := newTransactionView(tr)
+		views = append(views, v)
+
+		category := v.Category
+		if v.TransferAccount != "" {
+			category = "transfer: " + v.TransferAccount
+		}
+		review := ""
+		switch {
+		case v.Match != nil:
+			review = "matched " + v.Match.Date + " " + v.Match.Description
+		case !v.Approved:
+			review = "unapproved"
+		}
+		t.add(v.ID, v.Date, v.Account, category, v.Amount, v.Description, review)
+	}
+	return views, t
+}
+
+func runTransactionList(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("transaction list", stderr)
+	f := addBudgetFlags(fs)
+	account := fs.String("account", "", "only list transactions of this account")
+	category := fs.String("category", "", "only list transactions of this category")
+	month := fs.String("month", "", "only list transactions of this month, like 2006-01")
+	unapproved := fs.Bool("unapproved", false, "only list transactions to review")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	_, b, err := f.open()
+	if err != nil {
+		return err
+	}
+
+	transactions := b.Transactions()
+	if *account != "" {
+		a, err := findAccount(b, *account)
+		if err != nil {
+			return err
+		}
+		transactions = a.Transactions()
+	}
+
+	var c *budgeting.Category
+	if *category != "" {
+		if c, err = findCategory(b, *category); err != nil {
+			return err
+		}
+	}
+
+	var m budgeting.YearMonth
+	if *month != "" {
+		if m, err = parseMonth(*month); err != nil {
+			return err
+		}
+	}
+
+	filtered := []*budgeting.Transaction{}
+	for _, t := range transactions {
+		if c != nil && !c.Equal(t.Category()) {
+			continue
+		}
+		if *month != "" && !budgeting.YearMonthFromTime(t.Date()).Equal(m) {
+			continue
+		}
+		if *unapproved && t.Approved() {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+
+	views, t := transactionTable(filtered...)
+	return f.output(stdout).print(views, t)
+}
+
+func runTransactionAdd(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("transaction add", stderr)
+	f := addBudgetFlags(fs)
+	account := fs.String("account", "", "account of the transaction (required)")
+	amountFlag := fs.String("amount", "", "amount of the transaction, negative for spending (required)")
+	date := fs.String("date", today(), "date of the transaction")
+	description := fs.String("description", "", "description of the transaction")
+	category := fs.String("category", "", "category of the transaction")
+	transfer := fs.String("transfer", "", "account the money is transferred to or from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *account == "" || *amountFlag == "" || fs.NArg() != 0 {
+		return fmt.Errorf("usage: transaction add -account ACCOUNT -amount AMOUNT [flags]")
+	}
+
+	amount, err := parseAmount(*amountFlag)
+	if err != nil {
+		return err
+	}
+	d, err := parseDate(*date)
+	if err != nil {
+		return err
+	}
+
+	var transaction *budgeting.Transaction
+	_, err = f.update(func(b *budgeting.Budget) error {
+		a, err := findAccount(b, *account)
+		if err != nil {
+			return err
+		}
+
+		var c *budgeting.Category
+		if *category != "" {
+			if c, err = findCategory(b, *category); err != nil {
+				return err
+			}
+		}
+
+		var rel *budgeting.Account
+		if *transfer != "" {
+			if rel, err = findAccount(b, *transfer); err != nil {
+				return err
+			}
+		}
+
+		transaction, err = a.AddTransaction(d, amount, *description, c, rel)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	views, t := transactionTable(transaction)
+	return f.output(stdout).print(views[0], t)
+}
+
+func runTransactionEdit(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("transaction edit", stderr)
+	f := addBudgetFlags(fs)
+	fs.String("amount", "", "new amount")
+	fs.String("date", "", "new date")
+	fs.String("description", "", "new description")
+	fs.String("category", "", `new category, or "" to remove it`)
+	fs.Bool("cleared", false, "whether the bank has cleared the transaction")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: transaction edit [flags] TRANSACTION")
+	}
+
+	// Only the flags given are changed, even if given as "".
+	changes := map[string]string{}
+	fs.Visit(func(fl *flag.Flag) {
+		changes[fl.Name] = fl.Value.String()
+	})
+
+	var transaction *budgeting.Transaction
+	_, err := f.update(func(b *budgeting.Budget) error {
+		t, err := b.Transaction(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		if v, ok := changes["date"]; ok {
+			d, err := parseDate(v)
+			if err != nil {
+				return err
+			}
+			if err := t.SetDate(d); err != nil {
+				return err
+			}
+		}
+		if v, ok := changes["amount"]; ok {
+			amount, err := parseAmount(v)
+			if err != nil {
+				return err
+			}
+			if err := t.SetAmount(amount); err != nil {
+				return err
+			}
+		}
+		if v, ok := changes["description"]; ok {
+			if err := t.SetDescription(v); err != nil {
+				return err
+			}
+		}
+		if v, ok := changes["cleared"]; ok {
+			if err := t.SetCleared(v == "true"); err != nil {
+				return err
+			}
+		}
+		if v, ok := changes["category"]; ok {
+			var c *budgeting.Category
+			if v != "" {
+				if c, err = findCategory(b, v); err != nil {
+					return err
+				}
+			}
+			if err := t.SetCategory(c); err != nil {
+				return err
+			}
+		}
+
+		transaction = t
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	views, t := transactionTable(transaction)
+	return f.output(stdout).print(views[0], t)
+}
+
+func runTransactionDelete(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("transaction delete", stderr)
+	f := addBudgetFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: transaction delete [flags] TRANSACTION")
+	}
+
+	_, err := f.update(func(b *budgeting.Budget) error {
+		t, err := b.Transaction(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		return t.Account().DeleteTransaction(t)
+	})
+	return err
+}
+
+type reviewedView struct {
+	Approved []transactionView `json:"approved"`
+
+	// Added are the imported transactions added back by rejecting their
+	// matches, which are still to be reviewed.
+	Added []transactionView `json:"added"`
+}
+
+func runTransactionApprove(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("transaction approve", stderr)
+	f := addBudgetFlags(fs)
+	all := fs.Bool("all", false, "approve every unapproved transaction")
+	category := fs.String("category", "", "category to give the transactions")
+	reject := fs.Bool("reject-match", false, "add back the imported transactions the transactions were matched with, instead of accepting the matches")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *all == (fs.NArg() > 0) {
+		return fmt.Errorf("usage: transaction approve [-category CATEGORY] [-reject-match] TRANSACTION... or approve -all")
+	}
+
+	var approved, added []*budgeting.Transaction
+	_, err := f.update(func(b *budgeting.Budget) error {
+		approved, added = nil, nil
+
+		var transactions []*budgeting.Transaction
+		for _, id := range fs.Args() {
+			t, err := b.Transaction(id)
+			if err != nil {
+				return err
+			}
+			transactions = append(transactions, t)
+		}
+		if *all {
+			for _, t := range b.Transactions() {
+				if !t.Approved() {
+					transactions = append(transactions, t)
+				}
+			}
+		}
+
+		var c *budgeting.Category
+		if *category != "" {
+			var err error
+			if c, err = findCategory(b, *category); err != nil {
+				return err
+			}
+		}
+
+		for _, t := range transactions {
+			if *reject {
+				imported, err := t.RejectMatch()
+				if err != nil {
+					return fmt.Errorf("transaction %s: %v", t.ID(), err)
+				}
+				added = append(added, imported)
+			}
+			if c != nil {
+				if err := t.SetCategory(c); err != nil {
+					return fmt.Errorf("transaction %s: %v", t.ID(), err)
+				}
+			}
+			if err := t.Approve(); err != nil {
+				return fmt.Errorf("transaction %s: %v", t.ID(), err)
+			}
+			approved = append(approved, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	views, t := transactionTable(approved...)
+	addedViews, addedTable := transactionTable(added...)
+	o := f.output(stdout)
+	if o.json {
+		return o.print(reviewedView{Approved: views, Added: addedViews}, nil)
+	}
+	t.rows = append(t.rows, addedTable.rows...)
+	return o.print(nil, t)
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionCommands(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	run(t, "budget", "create", "Home")
+	run(t, "account", "add", "-balance", "100", "-date", "2018-01-01", "Checking")
+	run(t, "account", "add", "-date", "2018-01-01", "Wallet")
+	run(t, "category", "add", "Food")
+
+	var lunch transactionView
+	runJSON(t, &lunch, "transaction", "add", "-json", "-account", "checking", "-amount", "-12.50", "-date", "2018-01-03", "-category", "food", "-description", "Lunch")
+	assert.Equal("Checking", lunch.Account)
+	assert.Equal("Food", lunch.Category)
+	assert.Equal("-12.50", lunch.Amount)
+
+	var transfer transactionView
+	runJSON(t, &transfer, "transaction", "add", "-json", "-account", "Checking", "-amount", "-20", "-date", "2018-02-01", "-transfer", "Wallet")
+	assert.Equal("Wallet", transfer.TransferAccount)
+
+	assert.Contains(runFails(t, "transaction", "add", "-account", "Checking"), "usage: transaction add")
+
+	var transactions []transactionView
+	runJSON(t, &transactions, "transaction", "list", "-json", "-account", "Wallet")
+	assert.Len(transactions, 2)
+	assert.Equal("20.00", transactions[1].Amount)
+	runJSON(t, &transactions, "transaction", "list", "-json", "-category", "Food")
+	assert.Equal([]transactionView{lunch}, transactions)
+	runJSON(t, &transactions, "transaction", "list", "-json", "-month", "2018-01", "-account", "Checking")
+	assert.Len(transactions, 2)
+	assert.Contains(run(t, "transaction", "list"), "transfer: Wallet")
+
+	var edited transactionView
+	runJSON(t, &edited, "transaction", "edit", "-json", "-amount", "-15", "-category", "", lunch.ID)
+	assert.Equal("-15.00", edited.Amount)
+	assert.Equal("", edited.Category)
+	assert.Equal("Lunch", edited.Description)
+	assert.False(edited.Cleared)
+	runJSON(t, &edited, "transaction", "edit", "-json", "-cleared", lunch.ID)
+	assert.True(edited.Cleared)
+
+	run(t, "transaction", "delete", lunch.ID)
+	assert.Contains(runFails(t, "transaction", "delete", lunch.ID), "transaction not found")
+}
+
+func TestTransactionApprove(t *testing.T) {
+	assert := assert.New(t)
+	defer useTestStore(t)()
+
+	run(t, "budget", "create", "Home")
+	run(t, "account", "add", "-balance", "100", "-date", "2018-01-01", "Checking")
+	run(t, "category", "add", "Food")
+
+	var shop transactionView
+	runJSON(t, &shop, "transaction", "add", "-json", "-account", "Checking", "-amount", "-42.10", "-date", "2018-01-04", "-category", "Food", "-description", "Supermarket")
+	assert.True(shop.Approved)
+
+	file := filepath.Join(os.Getenv("BUDGET_STORE"), "statement.csv")
+	assert.Nil(ioutil.WriteFile(file, []byte("2018-01-05,SUPERMARKET LONDON,-42.10\n2018-01-06,Cafe,-5.00\n"), 0600))
+	run(t, "import", "csv", "-account", "Checking", "-date", "1", "-description", "2", "-amount", "3", file)
+
+	out := run(t, "transaction", "list", "-unapproved")
+	assert.Contains(out, "matched 2018-01-05 SUPERMARKET LONDON")
+	assert.Contains(out, "unapproved")
+
+	var queue []transactionView
+	runJSON(t, &queue, "transaction", "list", "-json", "-unapproved")
+	assert.Len(queue, 2)
+	assert.Equal(shop.ID, queue[0].ID)
+	cafe := queue[1]
+
+	assert.Contains(runFails(t, "transaction", "approve", "-reject-match", cafe.ID), "isn't matched")
+	assert.Contains(runFails(t, "transaction", "approve"), "usage: transaction approve")
+
+	var reviewed reviewedView
+	runJSON(t, &reviewed, "transaction", "approve", "-json", "-reject-match", shop.ID)
+	assert.Nil(reviewed.Approved[0].Match)
+	assert.Equal("SUPERMARKET LONDON", reviewed.Added[0].Description)
+	assert.False(reviewed.Added[0].Approved)
+
+	runJSON(t, &reviewed, "transaction", "approve", "-json", "-all", "-category", "Food")
+	assert.Len(reviewed.Approved, 2)
+	assert.Equal("Food", reviewed.Approved[1].Category)
+
+	runJSON(t, &queue, "transaction", "list", "-json", "-unapproved")
+	assert.Len(queue, 0)
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/hasyimibhar/budget-app/tui"
+)
+
+func runTUI(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("tui", stderr)
+	dir := fs.String("store", storeDir(), "directory containing the stored budgets")
+	budget := fs.String("budget", "", "ID or name of the budget (default the only stored budget)")
+	month := fs.String("month", now().Format("2006-01"), "month to start at")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: tui [flags]")
+	}
+
+	m, err := parseMonth(*month)
+	if err != nil {
+		return err
+	}
+
+	s, err := storage.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+	b, err := findBudget(s, *budget)
+	if err != nil {
+		return err
+	}
+
+	model, err := tui.New(s, b.ID(), m)
+	if err != nil {
+		return err
+	}
+	return tui.Run(model, os.Stdin, stdout)
+}
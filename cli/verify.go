@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hasyimibhar/budget-app/storage"
+)
+
+func runVerify(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("verify", stderr)
+	dir := fs.String("store", storeDir(), "directory containing the stored budgets")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, err := storage.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	ids := fs.Args()
+	if len(ids) == 0 {
+		if ids, err = s.List(); err != nil {
+			return err
+		}
+	}
+
+	broken := 0
+	for _, id := range ids {
+		b, err := s.Load(id)
+		if err != nil {
+			broken++
+			fmt.Fprintf(stdout, "%s: error: %v\n", id, err)
+			continue
+		}
+
+		violations := b.Verify()
+		if len(violations) == 0 {
+			fmt.Fprintf(stdout, "%s: ok\n", id)
+			continue
+		}
+
+		broken++
+		fmt.Fprintf(stdout, "%s: %d violation(s)\n", id, len(violations))
+		for _, v := range violations {
+			fmt.Fprintf(stdout, "  %s\n", v)
+		}
+	}
+
+	if broken > 0 {
+		return fmt.Errorf("%d of %d budget(s) failed verification", broken, len(ids))
+	}
+	return nil
+}
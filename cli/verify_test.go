@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "budget-app-cli")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	s, err := storage.NewFileStore(dir)
+	assert.Nil(err)
+
+	b := budgeting.NewBudget("My Budget")
+	b.AddAccount("Savings", decimal.New(10000, -2), time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Nil(s.Save(b, 0))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(0, Run([]string{"verify", "-store", dir}, &stdout, &stderr), stderr.String())
+	assert.Equal(b.ID()+": ok\n", stdout.String())
+
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{}`), 0600))
+
+	stdout.Reset()
+	stderr.Reset()
+	assert.Equal(1, Run([]string{"verify", "-store", dir, "broken"}, &stdout, &stderr))
+	assert.Contains(stdout.String(), "broken: error: ")
+	assert.Contains(stderr.String(), "1 of 1 budget(s) failed verification")
+}
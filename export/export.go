@@ -0,0 +1,3 @@
+// Package export writes the accounts and budgets of a budget in the formats
+// of other tools, and as CSV and JSON for spreadsheets and audits.
+package export
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+// Filter chooses what is exported. Its zero value exports everything.
+type Filter struct {
+	// Accounts limits transactions to those of the accounts, if any are
+	// given. Budget months aren't kept per account, so it doesn't limit
+	// them.
+	Accounts []*budgeting.Account
+
+	// Categories limits transactions and budget months to the categories,
+	// if any are given.
+	Categories []*budgeting.Category
+
+	// From and To limit the export to the days between them, both
+	// included, unless they are zero. Budget months are exported whole.
+	From, To time.Time
+}
+
+func (f Filter) account(a *budgeting.Account) bool {
+	if len(f.Accounts) == 0 {
+		return true
+	}
+	for _, other := range f.Accounts {
+		if other.ID() == a.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) category(c *budgeting.Category) bool {
+	if len(f.Categories) == 0 {
+		return true
+	}
+	for _, other := range f.Categories {
+		if other.Equal(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) date(d time.Time) bool {
+	if !f.From.IsZero() && d.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && d.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// months returns the months of the budget between From and To. Without
+// them, it returns those from the budget's first transaction or budgeted
+// amount to its last.
+func (f Filter) months(b *budgeting.Budget) []budgeting.YearMonth {
+	first, last, ok := b.Months()
+	if !f.From.IsZero() {
+		first, ok = budgeting.YearMonthFromTime(f.From), true
+	}
+	if !f.To.IsZero() {
+		last = budgeting.YearMonthFromTime(f.To)
+	}
+	if !ok {
+		return nil
+	}
+
+	months := []budgeting.YearMonth{}
+	for m := first; !after(m, last); m = m.NextMonth() {
+		months = append(months, m)
+	}
+	return months
+}
+
+func after(m, other budgeting.YearMonth) bool {
+	return m.Year > other.Year || m.Year == other.Year && m.Month > other.Month
+}
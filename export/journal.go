@@ -0,0 +1,415 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+)
+
+// JournalFormat is the format of a plain-text accounting journal.
+type JournalFormat string
+
+// Journal formats.
+const (
+	Ledger    JournalFormat = "ledger"
+	HLedger   JournalFormat = "hledger"
+	Beancount JournalFormat = "beancount"
+)
+
+var (
+	// ErrNoCurrency is returned when writing a beancount journal without a
+	// currency, which beancount amounts can't do without.
+	ErrNoCurrency = fmt.Errorf("beancount journals need a currency")
+
+	// ErrNoVirtualPostings is returned when writing envelopes to a beancount
+	// journal, as beancount has no virtual postings.
+	ErrNoVirtualPostings = fmt.Errorf("beancount has no virtual postings to write envelopes with")
+)
+
+// Names of the journal accounts which aren't accounts or categories of the
+// budget.
+const (
+	openingBalances = "Equity:Opening Balances"
+	income          = "Income"
+	uncategorized   = "Expenses:Uncategorized"
+	envelopes       = "Budget"
+)
+
+// JournalOptions tells how to write a journal.
+type JournalOptions struct {
+	Format JournalFormat
+
+	// Currency is written after every amount, if given.
+	Currency string
+
+	// Liabilities are the accounts which are debts, like credit cards.
+	// They are written under Liabilities rather than Assets.
+	Liabilities []*budgeting.Account
+
+	// Envelopes writes the budget as virtual postings to accounts under
+	// Budget: one per category, whose balance is what is available in it,
+	// and one for To Be Budgeted. Income fills To Be Budgeted, budgeting
+	// moves money from it to the categories on the first of the month, and
+	// spending empties the categories.
+	Envelopes bool
+}
+
+// Journal writes the budget as a plain-text accounting journal. Accounts
+// become asset or liability accounts, categories become expense accounts
+// named after their group and name, and income to be budgeted comes from
+// an Income account, except starting balances, which come from equity.
+// Transfers are written once, with a posting to each account. Cleared
+// transactions are marked as such.
+func Journal(w io.Writer, b *budgeting.Budget, o JournalOptions) error {
+	switch o.Format {
+	case Ledger, HLedger:
+	case Beancount:
+		if o.Currency == "" {
+			return ErrNoCurrency
+		}
+		if o.Envelopes {
+			return ErrNoVirtualPostings
+		}
+	default:
+		return fmt.Errorf("unknown journal format %q", o.Format)
+	}
+
+	j := &journal{
+		options:     o,
+		tbb:         b.TBBCategory(),
+		liabilities: map[string]bool{},
+		opened:      map[string]time.Time{},
+
+		startingBalances: map[string]bool{},
+		names:            map[string]string{},
+		named:            map[string]bool{},
+	}
+	for _, a := range o.Liabilities {
+		j.liabilities[a.ID()] = true
+	}
+	for _, a := range b.Accounts() {
+		if t := a.StartingBalance(); t != nil {
+			j.startingBalances[t.ID()] = true
+		}
+	}
+
+	for _, t := range b.Transactions() {
+		j.addTransaction(t)
+	}
+	if o.Envelopes {
+		j.addBudgeted(b)
+	}
+
+	// Budgeting on the first of a month comes before spending that day.
+	sort.SliceStable(j.entries, func(i, k int) bool {
+		return j.entries[i].date.Before(j.entries[k].date)
+	})
+
+	bw := bufio.NewWriter(w)
+	j.write(bw, b.Name())
+	return bw.Flush()
+}
+
+type journal struct {
+	options     JournalOptions
+	tbb         *budgeting.Category
+	liabilities map[string]bool
+
+	// startingBalances are the IDs of the transactions accounts were
+	// opened with.
+	startingBalances map[string]bool
+
+	// names are the journal account names given to the names of the
+	// budget, and named those already given.
+	names map[string]string
+	named map[string]bool
+
+	entries []journalEntry
+
+	// opened is the date each account is first used on, in the order of
+	// accounts.
+	opened   map[string]time.Time
+	accounts []string
+}
+
+type journalEntry struct {
+	date        time.Time
+	cleared     bool
+	approved    bool
+	description string
+	postings    []posting
+}
+
+type posting struct {
+	account string
+	amount  decimal.Decimal
+
+	// virtual postings are written in parentheses, unbalanced, or in
+	// brackets, balanced among themselves.
+	virtual string
+}
+
+func (j *journal) addTransaction(t *budgeting.Transaction) {
+	e := journalEntry{
+		date:        t.Date(),
+		cleared:     t.Cleared(),
+		approved:    t.Approved(),
+		description: t.Description(),
+	}
+	a, amount := t.Account(), t.Amount()
+
+	if rel := t.TransferAccount(); rel != nil {
+		// Both sides of a transfer are transactions; the one the money
+		// leaves is written.
+		if amount.IsPositive() || amount.IsZero() && a.ID() > rel.ID() {
+			return
+		}
+		e.postings = append(e.postings, j.posting(j.accountName(rel), amount.Neg(), ""))
+		e.postings = append(e.postings, j.posting(j.accountName(a), amount, ""))
+		j.entries = append(j.entries, e)
+		return
+	}
+
+	c := t.Category()
+	counter, envelope := uncategorized, ""
+	switch {
+	case c != nil && c.Equal(j.tbb):
+		counter, envelope = income, envelopes+":To Be Budgeted"
+		if j.startingBalances[t.ID()] {
+			// Accounts opened empty have nothing to write.
+			if amount.IsZero() {
+				return
+			}
+			counter = openingBalances
+		}
+	case c != nil:
+		counter, envelope = j.categoryName("Expenses", c), j.categoryName(envelopes, c)
+	}
+
+	e.postings = append(e.postings, j.posting(counter, amount.Neg(), ""))
+	e.postings = append(e.postings, j.posting(j.accountName(a), amount, ""))
+	if j.options.Envelopes && envelope != "" {
+		e.postings = append(e.postings, j.posting(envelope, amount, "("))
+	}
+	j.entries = append(j.entries, e)
+}
+
+// addBudgeted adds an entry on the first of every month moving what is
+// budgeted from To Be Budgeted to the categories.
+func (j *journal) addBudgeted(b *budgeting.Budget) {
+	for _, m := range (Filter{}).months(b) {
+		e := journalEntry{
+			date:        time.Date(m.Year, m.Month, 1, 0, 0, 0, 0, time.UTC),
+			cleared:     true,
+			approved:    true,
+			description: "Budget " + m.String(),
+		}
+
+		total := decimal.Decimal{}
+		for _, c := range b.Categories() {
+			budgeted := b.Budgeted(m, c)
+			if c.Equal(j.tbb) || budgeted.IsZero() {
+				continue
+			}
+			e.postings = append(e.postings, j.posting(j.categoryName(envelopes, c), budgeted, "["))
+			total = total.Add(budgeted)
+		}
+
+		if len(e.postings) > 0 {
+			e.postings = append(e.postings, j.posting(envelopes+":To Be Budgeted", total.Neg(), "["))
+			j.entries = append(j.entries, e)
+		}
+	}
+}
+
+func (j *journal) posting(account string, amount decimal.Decimal, virtual string) posting {
+	return posting{account: j.name(account), amount: amount, virtual: virtual}
+}
+
+func (j *journal) accountName(a *budgeting.Account) string {
+	if j.liabilities[a.ID()] {
+		return "Liabilities:" + a.Name()
+	}
+	return "Assets:" + a.Name()
+}
+
+func (j *journal) categoryName(root string, c *budgeting.Category) string {
+	if c.Group() != "" {
+		return root + ":" + c.Group() + ":" + c.Name()
+	}
+	return root + ":" + c.Name()
+}
+
+// name makes an account name valid in the journal format. Colons are kept,
+// making categories like "Food:Groceries" subaccounts. Names which become
+// the same, like "Food & Drink" and "Food-Drink" in beancount, are told
+// apart by a number.
+func (j *journal) name(name string) string {
+	if n, ok := j.names[name]; ok {
+		return n
+	}
+
+	n := j.sanitize(name)
+	for i := 2; j.named[n]; i++ {
+		n = fmt.Sprintf("%s-%d", j.sanitize(name), i)
+	}
+	j.names[name] = n
+	j.named[n] = true
+	return n
+}
+
+func (j *journal) sanitize(name string) string {
+	if j.options.Format != Beancount {
+		// Two spaces end an account name in ledger.
+		return strings.Join(strings.Fields(name), " ")
+	}
+
+	// Beancount account names are made of capitalized words of letters,
+	// digits and dashes.
+	parts := []string{}
+	for _, part := range strings.Split(name, ":") {
+		var sb strings.Builder
+		dash := false
+		for _, r := range part {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				if dash && sb.Len() > 0 {
+					sb.WriteRune('-')
+				}
+				sb.WriteRune(r)
+				dash = false
+			} else {
+				dash = true
+			}
+		}
+
+		s := []rune(sb.String())
+		switch {
+		case len(s) == 0:
+			s = []rune("Unnamed")
+		case unicode.IsLetter(s[0]):
+			s[0] = unicode.ToUpper(s[0])
+		case !unicode.IsDigit(s[0]):
+			s = append([]rune("X"), s...)
+		}
+		parts = append(parts, string(s))
+	}
+	return strings.Join(parts, ":")
+}
+
+func (j *journal) write(w io.Writer, name string) {
+	for _, e := range j.entries {
+		for _, p := range e.postings {
+			if _, ok := j.opened[p.account]; !ok {
+				j.opened[p.account] = e.date
+				j.accounts = append(j.accounts, p.account)
+			}
+		}
+	}
+
+	switch j.options.Format {
+	case Beancount:
+		fmt.Fprintf(w, "option \"title\" %s\n", beancountString(name))
+		fmt.Fprintf(w, "option \"operating_currency\" %s\n\n", beancountString(j.options.Currency))
+		for _, a := range j.accounts {
+			fmt.Fprintf(w, "%s open %s %s\n", j.opened[a].Format(dateLayout), a, j.options.Currency)
+		}
+	case HLedger:
+		fmt.Fprintf(w, "; %s\n\n", name)
+		for _, a := range j.accounts {
+			if t := hledgerType(a); t != "" {
+				fmt.Fprintf(w, "account %s  ; type: %s\n", a, t)
+			} else {
+				fmt.Fprintf(w, "account %s\n", a)
+			}
+		}
+	default:
+		fmt.Fprintf(w, "; %s\n\n", name)
+		for _, a := range j.accounts {
+			fmt.Fprintf(w, "account %s\n", a)
+		}
+	}
+
+	width := 0
+	for _, a := range j.accounts {
+		if len(a) > width {
+			width = len(a)
+		}
+	}
+
+	for _, e := range j.entries {
+		fmt.Fprintln(w)
+		j.writeEntry(w, e, width+2)
+	}
+}
+
+func (j *journal) writeEntry(w io.Writer, e journalEntry, width int) {
+	description := strings.Join(strings.Fields(e.description), " ")
+	switch j.options.Format {
+	case Beancount:
+		// Transactions to review are flagged.
+		flag := "*"
+		if !e.approved {
+			flag = "!"
+		}
+		fmt.Fprintf(w, "%s %s %s\n", e.date.Format(dateLayout), flag, beancountString(description))
+	default:
+		date := e.date.Format(dateLayout)
+		if j.options.Format == Ledger {
+			date = e.date.Format("2006/01/02")
+		}
+		if e.cleared {
+			date += " *"
+		}
+		fmt.Fprintf(w, "%s %s\n", date, description)
+	}
+
+	for _, p := range e.postings {
+		account := p.account
+		switch p.virtual {
+		case "(":
+			account = "(" + account + ")"
+		case "[":
+			account = "[" + account + "]"
+		}
+		fmt.Fprintf(w, "    %-*s%s\n", width, account, j.amount(p.amount))
+	}
+}
+
+func (j *journal) amount(d decimal.Decimal) string {
+	s := d.StringFixed(2)
+	if d.Exponent() < -2 {
+		s = d.String()
+	}
+	if j.options.Currency != "" {
+		s += " " + j.options.Currency
+	}
+	return s
+}
+
+// hledgerType returns the hledger account type of the journal account.
+func hledgerType(account string) string {
+	switch strings.SplitN(account, ":", 2)[0] {
+	case "Assets":
+		return "Asset"
+	case "Liabilities":
+		return "Liability"
+	case "Equity":
+		return "Equity"
+	case "Income":
+		return "Revenue"
+	case "Expenses":
+		return "Expense"
+	}
+	return ""
+}
+
+func beancountString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var postingSeparator = regexp.MustCompile(`\s{2,}`)
+
+// readJournal sums the postings of a journal per account, the way ledger,
+// hledger and beancount would, and checks that every entry balances.
+func readJournal(t *testing.T, journal string) map[string]decimal.Decimal {
+	assert := assert.New(t)
+
+	balances := map[string]decimal.Decimal{}
+	var real, balanced decimal.Decimal
+	check := func() {
+		assert.True(real.IsZero(), "entry doesn't balance by %s", real)
+		assert.True(balanced.IsZero(), "virtual postings don't balance by %s", balanced)
+		real, balanced = decimal.Decimal{}, decimal.Decimal{}
+	}
+
+	s := bufio.NewScanner(strings.NewReader(journal))
+	for s.Scan() {
+		line := s.Text()
+		if !strings.HasPrefix(line, " ") {
+			check()
+			continue
+		}
+
+		fields := postingSeparator.Split(strings.TrimSpace(line), 2)
+		assert.Len(fields, 2, line)
+		amount, err := decimal.NewFromString(strings.Fields(fields[1])[0])
+		assert.Nil(err)
+
+		account := fields[0]
+		switch account[0] {
+		case '(':
+		case '[':
+			balanced = balanced.Add(amount)
+		default:
+			real = real.Add(amount)
+		}
+		balances[account] = balances[account].Add(amount)
+	}
+	check()
+	return balances
+}
+
+func TestJournal(t *testing.T) {
+	assert := assert.New(t)
+
+	b, checking, wallet, _ := testBudget()
+
+	for _, format := range []JournalFormat{Ledger, HLedger, Beancount} {
+		var buf bytes.Buffer
+		assert.Nil(Journal(&buf, b, JournalOptions{
+			Format:      format,
+			Currency:    "USD",
+			Liabilities: []*budgeting.Account{wallet},
+		}))
+
+		balances := readJournal(t, buf.String())
+		assert.Equal(checking.Balance().String(), balances["Assets:Checking"].String(), format)
+		assert.Equal(wallet.Balance().String(), balances["Liabilities:Wallet"].String(), format)
+		assert.Equal("-1000", balances["Equity:Opening-Balances"].Add(balances["Equity:Opening Balances"]).String(), format)
+		assert.Equal("46.6", balances["Expenses:Everyday:Food"].String(), format)
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(Journal(&buf, b, JournalOptions{Format: Beancount, Currency: "USD"}))
+	assert.Contains(buf.String(), "2018-01-01 open Assets:Checking USD\n")
+	assert.Contains(buf.String(), "2018-01-05 ! \"Weekly, shop\"\n")
+
+	buf.Reset()
+	assert.Nil(Journal(&buf, b, JournalOptions{Format: Ledger}))
+	assert.Contains(buf.String(), "2018/01/05 * Weekly, shop\n")
+	assert.Contains(buf.String(), "2018/01/06 Cash\n    Assets:Wallet            50.00\n    Assets:Checking          -50.00\n")
+
+	assert.Equal(ErrNoCurrency, Journal(&buf, b, JournalOptions{Format: Beancount}))
+	assert.Equal(ErrNoVirtualPostings, Journal(&buf, b, JournalOptions{Format: Beancount, Currency: "USD", Envelopes: true}))
+	assert.NotNil(Journal(&buf, b, JournalOptions{Format: "gnucash"}))
+}
+
+func TestJournal_StartingBalances(t *testing.T) {
+	assert := assert.New(t)
+
+	b, checking, _, _ := testBudget()
+	checking.Transactions()[0].SetDescription("Opened with")
+	checking.AddTransaction(date(2018, 1, 31), dec("200"), "Starting balance", b.TBBCategory(), nil)
+
+	var buf bytes.Buffer
+	assert.Nil(Journal(&buf, b, JournalOptions{Format: Ledger}))
+
+	balances := readJournal(t, buf.String())
+	assert.Equal("-1000", balances["Equity:Opening Balances"].String())
+	assert.Equal("-200", balances["Income"].String())
+}
+
+func TestJournal_NameCollisions(t *testing.T) {
+	assert := assert.New(t)
+
+	b, checking, _, _ := testBudget()
+	drinks, _ := b.AddCategory("Food & Drink")
+	snacks, _ := b.AddCategory("Food-Drink")
+	checking.AddTransaction(date(2018, 1, 7), dec("-3"), "Coffee", drinks, nil)
+	checking.AddTransaction(date(2018, 1, 8), dec("-2"), "Crisps", snacks, nil)
+
+	var buf bytes.Buffer
+	assert.Nil(Journal(&buf, b, JournalOptions{Format: Beancount, Currency: "USD"}))
+
+	balances := readJournal(t, buf.String())
+	assert.Equal("3", balances["Expenses:Food-Drink"].String())
+	assert.Equal("2", balances["Expenses:Food-Drink-2"].String())
+	assert.Contains(buf.String(), "2018-01-08 open Expenses:Food-Drink-2 USD\n")
+}
+
+func TestJournal_Envelopes(t *testing.T) {
+	assert := assert.New(t)
+
+	b, checking, _, food := testBudget()
+	february := budgeting.YearMonth{Year: 2018, Month: 2}
+
+	for _, format := range []JournalFormat{Ledger, HLedger} {
+		var buf bytes.Buffer
+		assert.Nil(Journal(&buf, b, JournalOptions{Format: format, Envelopes: true}))
+
+		balances := readJournal(t, buf.String())
+		assert.Equal(checking.Balance().String(), balances["Assets:Checking"].String(), format)
+		assert.Equal(b.Available(february, food).String(), balances["(Budget:Everyday:Food)"].Add(balances["[Budget:Everyday:Food]"]).String(), format)
+		assert.Equal(b.TBB(february).String(), balances["(Budget:To Be Budgeted)"].Add(balances["[Budget:To Be Budgeted]"]).String(), format)
+	}
+}
@@ -0,0 +1,80 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+// monthRecord is a month of the budget as it is exported.
+type monthRecord struct {
+	Month      string                `json:"month"`
+	TBB        string                `json:"tbb"`
+	Categories []monthCategoryRecord `json:"categories"`
+}
+
+type monthCategoryRecord struct {
+	Group      string `json:"category_group"`
+	Category   string `json:"category"`
+	Budgeted   string `json:"budgeted"`
+	Activities string `json:"activities"`
+	Available  string `json:"available"`
+}
+
+var monthHeader = []string{"month", "category_group", "category", "budgeted", "activities", "available"}
+
+// months returns a record of every month the filter keeps, with the
+// categories it keeps. "To Be Budgeted" is the TBB of the month rather than
+// a category.
+func months(b *budgeting.Budget, f Filter) []monthRecord {
+	tbb := b.TBBCategory()
+	categories := []*budgeting.Category{}
+	for _, c := range b.Categories() {
+		if !c.Equal(tbb) && f.category(c) {
+			categories = append(categories, c)
+		}
+	}
+
+	records := []monthRecord{}
+	for _, m := range f.months(b) {
+		r := monthRecord{
+			Month:      m.String(),
+			TBB:        b.TBB(m).StringFixed(2),
+			Categories: []monthCategoryRecord{},
+		}
+		for _, c := range categories {
+			r.Categories = append(r.Categories, monthCategoryRecord{
+				Group:      c.Group(),
+				Category:   c.Name(),
+				Budgeted:   b.Budgeted(m, c).StringFixed(2),
+				Activities: b.Activities(m, c).StringFixed(2),
+				Available:  b.Available(m, c).StringFixed(2),
+			})
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+// MonthsCSV writes what is budgeted, spent and available in every category
+// and month the filter keeps as CSV, with a row per category and month.
+func MonthsCSV(w io.Writer, b *budgeting.Budget, f Filter) error {
+	cw := csv.NewWriter(w)
+	cw.Write(monthHeader)
+	for _, m := range months(b, f) {
+		for _, c := range m.Categories {
+			cw.Write([]string{m.Month, c.Group, c.Category, c.Budgeted, c.Activities, c.Available})
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// MonthsJSON writes the months the filter keeps as a JSON array, each with
+// its To Be Budgeted and what is budgeted, spent and available in the
+// categories the filter keeps.
+func MonthsJSON(w io.Writer, b *budgeting.Budget, f Filter) error {
+	return writeJSON(w, months(b, f))
+}
@@ -0,0 +1,42 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonthsCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	b, _, _, _ := testBudget()
+
+	var buf bytes.Buffer
+	assert.Nil(MonthsCSV(&buf, b, Filter{}))
+	assert.Equal(`month,category_group,category,budgeted,activities,available
+2018-01,Everyday,Food,300.00,-42.10,257.90
+2018-01,,Rent,0.00,0.00,0.00
+2018-02,Everyday,Food,0.00,-4.50,253.40
+2018-02,,Rent,0.00,0.00,0.00
+`, buf.String())
+}
+
+func TestMonthsJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	b, _, _, food := testBudget()
+
+	var buf bytes.Buffer
+	assert.Nil(MonthsJSON(&buf, b, Filter{Categories: []*budgeting.Category{food}, From: date(2018, 2, 10), To: date(2018, 3, 1)}))
+
+	var records []monthRecord
+	assert.Nil(json.Unmarshal(buf.Bytes(), &records))
+	assert.Len(records, 2)
+	assert.Equal("2018-02", records[0].Month)
+	assert.Equal("700.00", records[0].TBB)
+	assert.Equal([]monthCategoryRecord{{Group: "Everyday", Category: "Food", Budgeted: "0.00", Activities: "-4.50", Available: "253.40"}}, records[0].Categories)
+	assert.Equal("2018-03", records[1].Month)
+}
@@ -0,0 +1,43 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+// qifDateLayout is the date format of Quicken and most apps reading QIF.
+const qifDateLayout = "01/02/2006"
+
+// QIF writes the transactions of the account as a QIF bank section, named
+// after the account so that apps importing it know where it goes.
+// Transfers name the other account in brackets, like "[Wallet]".
+func QIF(w io.Writer, a *budgeting.Account) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "!Account\nN%s\nTBank\n^\n!Type:Bank\n", qifText(a.Name()))
+	for _, t := range a.Transactions() {
+		fmt.Fprintf(bw, "D%s\n", t.Date().Format(qifDateLayout))
+		fmt.Fprintf(bw, "T%s\n", t.Amount().StringFixed(2))
+		if d := t.Description(); d != "" {
+			fmt.Fprintf(bw, "P%s\n", qifText(d))
+		}
+
+		if rel := t.TransferAccount(); rel != nil {
+			fmt.Fprintf(bw, "L[%s]\n", qifText(rel.Name()))
+		} else if c := t.Category(); c != nil {
+			fmt.Fprintf(bw, "L%s\n", qifText(c.Name()))
+		}
+		fmt.Fprintln(bw, "^")
+	}
+
+	return bw.Flush()
+}
+
+// qifText makes text fit on a QIF line.
+func qifText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/importer"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func date(year, month, day int) time.Time {
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestQIF(t *testing.T) {
+	assert := assert.New(t)
+
+	b := budgeting.NewBudget("My Budget")
+	checking, _ := b.AddAccount("Checking", dec("1000"), date(2018, 1, 1))
+	wallet, _ := b.AddAccount("Wallet", dec("0"), date(2018, 1, 1))
+	food, _ := b.AddCategory("Food")
+	checking.AddTransaction(date(2018, 1, 5), dec("-42.1"), "Weekly\nshop", food, nil)
+	checking.AddTransaction(date(2018, 1, 6), dec("-50"), "", nil, wallet)
+
+	var buf bytes.Buffer
+	assert.Nil(QIF(&buf, checking))
+	assert.Equal(`!Account
+NChecking
+TBank
+^
+!Type:Bank
+D01/01/2018
+T1000.00
+PStarting balance
+LTo Be Budgeted
+^
+D01/05/2018
+T-42.10
+PWeekly shop
+LFood
+^
+D01/06/2018
+T-50.00
+L[Wallet]
+^
+`, buf.String())
+
+	// Importing the export into another budget gives back the account.
+	other := budgeting.NewBudget("Other")
+	copied, _ := other.AddAccount("Checking", dec("0"), date(2018, 1, 1))
+	other.AddAccount("Wallet", dec("0"), date(2018, 1, 1))
+
+	statements, err := importer.ReadQIF(&buf, false)
+	assert.Nil(err)
+	assert.Equal("Checking", statements[0].Account)
+	r := importer.Import(copied, statements[0])
+	assert.Equal("3 imported, 0 skipped, 0 failed", r.Summary())
+	assert.True(checking.Balance().Equal(copied.Balance()))
+	assert.True(b.TBB(budgeting.YearMonth{Year: 2018, Month: time.January}).Equal(other.TBB(budgeting.YearMonth{Year: 2018, Month: time.January})))
+	assert.Equal("Food", r.Imported[1].Category().Name())
+}
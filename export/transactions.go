@@ -0,0 +1,100 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+// dateLayout is the format of dates in CSV and JSON exports.
+const dateLayout = "2006-01-02"
+
+// transactionRecord is a transaction as it is exported.
+type transactionRecord struct {
+	ID              string `json:"id"`
+	Date            string `json:"date"`
+	Account         string `json:"account"`
+	Group           string `json:"category_group"`
+	Category        string `json:"category"`
+	TransferAccount string `json:"transfer_account"`
+	Description     string `json:"description"`
+	Amount          string `json:"amount"`
+	Cleared         bool   `json:"cleared"`
+	Approved        bool   `json:"approved"`
+	ImportID        string `json:"import_id"`
+}
+
+var transactionHeader = []string{
+	"id", "date", "account", "category_group", "category", "transfer_account",
+	"description", "amount", "cleared", "approved", "import_id",
+}
+
+func (r transactionRecord) fields() []string {
+	return []string{
+		r.ID, r.Date, r.Account, r.Group, r.Category, r.TransferAccount,
+		r.Description, r.Amount, strconv.FormatBool(r.Cleared), strconv.FormatBool(r.Approved), r.ImportID,
+	}
+}
+
+// transactions returns the records of the transactions of every account
+// which the filter keeps, ordered by date. Both sides of transfers are
+// included, each with the other account as its transfer account.
+func transactions(b *budgeting.Budget, f Filter) []transactionRecord {
+	records := []transactionRecord{}
+	for _, t := range b.Transactions() {
+		c := t.Category()
+		if !f.account(t.Account()) || !f.date(t.Date()) {
+			continue
+		}
+		if len(f.Categories) > 0 && (c == nil || !f.category(c)) {
+			continue
+		}
+
+		r := transactionRecord{
+			ID:          t.ID(),
+			Date:        t.Date().Format(dateLayout),
+			Account:     t.Account().Name(),
+			Description: t.Description(),
+			Amount:      t.Amount().StringFixed(2),
+			Cleared:     t.Cleared(),
+			Approved:    t.Approved(),
+			ImportID:    t.ImportID(),
+		}
+		if c != nil {
+			r.Group, r.Category = c.Group(), c.Name()
+		}
+		if rel := t.TransferAccount(); rel != nil {
+			r.TransferAccount = rel.Name()
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+// TransactionsCSV writes the transactions of the budget which the filter
+// keeps as CSV, with a header row.
+func TransactionsCSV(w io.Writer, b *budgeting.Budget, f Filter) error {
+	cw := csv.NewWriter(w)
+	cw.Write(transactionHeader)
+	for _, r := range transactions(b, f) {
+		cw.Write(r.fields())
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// TransactionsJSON writes the transactions of the budget which the filter
+// keeps as a JSON array.
+func TransactionsJSON(w io.Writer, b *budgeting.Budget, f Filter) error {
+	return writeJSON(w, transactions(b, f))
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
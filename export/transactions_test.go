@@ -0,0 +1,66 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/stretchr/testify/assert"
+)
+
+// testBudget is a budget of two months with a transfer, an imported
+// transaction and a budgeted category.
+func testBudget() (b *budgeting.Budget, checking, wallet *budgeting.Account, food *budgeting.Category) {
+	b = budgeting.NewBudget("My Budget")
+	checking, _ = b.AddAccount("Checking", dec("1000"), date(2018, 1, 1))
+	wallet, _ = b.AddAccount("Wallet", dec("0"), date(2018, 1, 1))
+	food, _ = b.AddCategory("Food", budgeting.WithGroup("Everyday"))
+	b.AddCategory("Rent")
+
+	b.SetBudgeted(budgeting.YearMonth{Year: 2018, Month: 1}, food, dec("300"))
+	checking.AddTransaction(date(2018, 1, 5), dec("-42.1"), "Weekly, shop", food, nil,
+		budgeting.WithImportID("1"), budgeting.WithCleared(), budgeting.WithUnapproved())
+	checking.AddTransaction(date(2018, 1, 6), dec("-50"), "Cash", nil, wallet)
+	wallet.AddTransaction(date(2018, 2, 2), dec("-4.5"), "Cafe", food, nil)
+	return b, checking, wallet, food
+}
+
+func TestTransactionsCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	b, checking, _, _ := testBudget()
+
+	var buf bytes.Buffer
+	assert.Nil(TransactionsCSV(&buf, b, Filter{Accounts: []*budgeting.Account{checking}, From: date(2018, 1, 2)}))
+
+	ids := []string{}
+	for _, tr := range checking.Transactions()[1:] {
+		ids = append(ids, tr.ID())
+	}
+	assert.Equal("id,date,account,category_group,category,transfer_account,description,amount,cleared,approved,import_id\n"+
+		ids[0]+`,2018-01-05,Checking,Everyday,Food,,"Weekly, shop",-42.10,true,false,1`+"\n"+
+		ids[1]+",2018-01-06,Checking,,,Wallet,Cash,-50.00,false,true,\n", buf.String())
+}
+
+func TestTransactionsJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	b, _, _, food := testBudget()
+
+	var buf bytes.Buffer
+	assert.Nil(TransactionsJSON(&buf, b, Filter{Categories: []*budgeting.Category{food}, To: date(2018, 1, 31)}))
+
+	var records []transactionRecord
+	assert.Nil(json.Unmarshal(buf.Bytes(), &records))
+	assert.Len(records, 1)
+	assert.Equal("Weekly, shop", records[0].Description)
+	assert.Equal("Everyday", records[0].Group)
+	assert.True(records[0].Cleared)
+
+	buf.Reset()
+	assert.Nil(TransactionsJSON(&buf, b, Filter{}))
+	assert.Nil(json.Unmarshal(buf.Bytes(), &records))
+	assert.Len(records, 6)
+	assert.Equal("Checking", records[4].TransferAccount)
+}
@@ -6,4 +6,7 @@ require (
 	github.com/satori/go.uuid v1.2.0
 	github.com/shopspring/decimal v0.0.0-20180709203117-cd690d0c9e24
 	github.com/stretchr/testify v1.2.2
+	golang.org/x/crypto v0.0.0-20211117183948-ae814b36b871
+	golang.org/x/sys v0.7.0 // indirect
+	golang.org/x/term v0.0.0-20220526004731-065cf7ba2467
 )
@@ -0,0 +1,315 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReadCAMT reads an ISO 20022 camt.053 bank statement. There is a statement
+// for every account in the file, with its opening and closing balances.
+//
+// Transactions are dated with their booking dates, and have their value
+// dates too. Entries pending at the bank are uncleared, and those given
+// for information only are skipped. The bank's reference of an entry
+// becomes its import ID.
+func ReadCAMT(r io.Reader) ([]*Statement, error) {
+	d := xml.NewDecoder(r)
+	start, err := rootElement(d)
+	if err != nil || start.Name.Local != "Document" {
+		return nil, fmt.Errorf("not a camt.053 file")
+	}
+
+	var doc camtDocument
+	if err := d.DecodeElement(&doc, &start); err != nil {
+		return nil, err
+	}
+
+	statements := []*Statement{}
+	for _, stmt := range doc.Statements {
+		statements = append(statements, readCAMTStatement(stmt))
+	}
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("no statement in the camt.053 file")
+	}
+
+	return statements, nil
+}
+
+// rootElement reads up to the start of the root element of an XML file.
+func rootElement(d *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+// The elements of a camt.053 file which are read. Names don't have a
+// namespace, so that every version of camt.053 is read.
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	IBAN     string        `xml:"Acct>Id>IBAN"`
+	Other    string        `xml:"Acct>Id>Othr>Id"`
+	Balances []camtBalance `xml:"Bal"`
+	Entries  []camtEntry   `xml:"Ntry"`
+}
+
+type camtBalance struct {
+	camtAmount
+	Type string    `xml:"Tp>CdOrPrtry>Cd"`
+	Date *camtDate `xml:"Dt"`
+
+	line int
+}
+
+func (b *camtBalance) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type balance camtBalance
+	line, _ := d.InputPos()
+	err := d.DecodeElement((*balance)(b), &start)
+	b.line = line
+	return err
+}
+
+type camtEntry struct {
+	camtAmount
+	Status         camtStatus    `xml:"Sts"`
+	Booked         *camtDate     `xml:"BookgDt"`
+	Value          *camtDate     `xml:"ValDt"`
+	Reference      string        `xml:"AcctSvcrRef"`
+	EntryReference string        `xml:"NtryRef"`
+	Info           string        `xml:"AddtlNtryInf"`
+	Details        []camtDetails `xml:"NtryDtls>TxDtls"`
+
+	line int
+}
+
+func (e *camtEntry) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type entry camtEntry
+	line, _ := d.InputPos()
+	err := d.DecodeElement((*entry)(e), &start)
+	e.line = line
+	return err
+}
+
+// camtAmount is the amount of an entry or balance, which is negative when
+// it is a debit. The indicator of a reversal is already the opposite of
+// the entry it reverses.
+type camtAmount struct {
+	Amount    string `xml:"Amt"`
+	Indicator string `xml:"CdtDbtInd"`
+}
+
+// camtStatus is the status of an entry, which is the text of Sts up to
+// camt.053.001.06 and a code in it after.
+type camtStatus struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+// camtDate holds either a date or a date and time.
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtDetails struct {
+	Reference  string    `xml:"Refs>AcctSvcrRef"`
+	Debtor     camtParty `xml:"RltdPties>Dbtr"`
+	Creditor   camtParty `xml:"RltdPties>Cdtr"`
+	Remittance []string  `xml:"RmtInf>Ustrd"`
+	Info       string    `xml:"AddtlTxInf"`
+}
+
+// camtParty is a party of a transaction, whose name is in a Pty element
+// since camt.053.001.08.
+type camtParty struct {
+	Name      string `xml:"Nm"`
+	PartyName string `xml:"Pty>Nm"`
+}
+
+func readCAMTStatement(stmt camtStatement) *Statement {
+	s := &Statement{Account: stmt.IBAN}
+	if s.Account == "" {
+		s.Account = stmt.Other
+	}
+
+	var previous *Balance
+	for _, bal := range stmt.Balances {
+		amount, amountErr := bal.parse()
+		date, dateErr := bal.Date.parse()
+		if amountErr != nil || dateErr != nil {
+			s.fail(bal.line, "invalid balance")
+			continue
+		}
+
+		// Statements open with the balance at the start of their first
+		// day, or the previous closing balance, and close with the balance
+		// at the end of their last day.
+		switch bal.Type {
+		case "OPBD":
+			s.Opening = &Balance{Date: date.AddDate(0, 0, -1), Amount: amount}
+		case "PRCD":
+			previous = &Balance{Date: date, Amount: amount}
+		case "CLBD":
+			s.Balance = &Balance{Date: date, Amount: amount}
+		}
+	}
+	if s.Opening == nil {
+		s.Opening = previous
+	}
+
+	for _, ntry := range stmt.Entries {
+		switch status := ntry.Status.String(); status {
+		case "", "BOOK", "PDNG":
+			t, err := readCAMTEntry(ntry)
+			if err != nil {
+				s.fail(ntry.line, "%v", err)
+				continue
+			}
+			t.Uncleared = status == "PDNG"
+			s.Transactions = append(s.Transactions, t)
+		default:
+			s.skip(ntry.line, "entry with status %s", status)
+		}
+	}
+
+	return s
+}
+
+func (s camtStatus) String() string {
+	if s.Code != "" {
+		return s.Code
+	}
+	return strings.TrimSpace(s.Text)
+}
+
+func readCAMTEntry(ntry camtEntry) (Transaction, error) {
+	amount, err := ntry.parse()
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	var booked, value time.Time
+	if ntry.Booked != nil {
+		if booked, err = ntry.Booked.parse(); err != nil {
+			return Transaction{}, err
+		}
+	}
+	if ntry.Value != nil {
+		if value, err = ntry.Value.parse(); err != nil {
+			return Transaction{}, err
+		}
+	}
+	if booked.IsZero() {
+		// Pending entries may not be booked yet.
+		booked = value
+	}
+	if booked.IsZero() {
+		return Transaction{}, fmt.Errorf("no booking date")
+	}
+
+	id := ntry.Reference
+	if id == "" {
+		id = ntry.EntryReference
+	}
+
+	// Entries of several transactions, such as batches of payments, are
+	// described by the entry alone.
+	description := ""
+	if len(ntry.Details) == 1 {
+		description = ntry.Details[0].describe(amount.IsNegative())
+		if id == "" {
+			id = ntry.Details[0].Reference
+		}
+	}
+	if description == "" {
+		description = strings.Join(strings.Fields(ntry.Info), " ")
+	}
+
+	return Transaction{
+		Line:        ntry.line,
+		Date:        booked,
+		ValueDate:   value,
+		Amount:      amount,
+		Description: description,
+		ImportID:    id,
+	}, nil
+}
+
+// describe describes a transaction by the other party and what the
+// payment was for.
+func (tx camtDetails) describe(debit bool) string {
+	party := tx.Debtor
+	if debit {
+		party = tx.Creditor
+	}
+
+	parts := []string{}
+	name := party.Name
+	if name == "" {
+		name = party.PartyName
+	}
+	if name != "" {
+		parts = append(parts, name)
+	}
+	for _, ustrd := range tx.Remittance {
+		if ustrd != "" {
+			parts = append(parts, ustrd)
+		}
+	}
+	if len(parts) == 0 && tx.Info != "" {
+		parts = append(parts, tx.Info)
+	}
+
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+func (a camtAmount) parse() (decimal.Decimal, error) {
+	d, err := decimal.NewFromString(strings.TrimSpace(a.Amount))
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("invalid amount %q", a.Amount)
+	}
+
+	switch a.Indicator {
+	case "CRDT":
+	case "DBIT":
+		d = d.Neg()
+	default:
+		return decimal.Decimal{}, fmt.Errorf("invalid credit or debit indicator %q", a.Indicator)
+	}
+
+	return d, nil
+}
+
+// parse parses the date, keeping only the date of a date and time.
+func (dt *camtDate) parse() (time.Time, error) {
+	if dt == nil {
+		return time.Time{}, fmt.Errorf("no date")
+	}
+
+	s := dt.Date
+	if s == "" {
+		s = dt.DateTime
+	}
+	if len(s) < 10 {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+
+	d, err := time.Parse("2006-01-02", s[:10])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/stretchr/testify/assert"
+)
+
+const camt053 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <GrpHdr><MsgId>MSG1</MsgId><CreDtTm>2018-02-01T06:00:00</CreDtTm></GrpHdr>
+    <Stmt>
+      <Id>STMT1</Id>
+      <Acct><Id><IBAN>DE89370400440532013000</IBAN></Id><Ccy>EUR</Ccy></Acct>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">1000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+        <Dt><Dt>2018-01-01</Dt></Dt>
+      </Bal>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">2457.90</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+        <Dt><Dt>2018-01-31</Dt></Dt>
+      </Bal>
+      <Ntry>
+        <Amt Ccy="EUR">42.10</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2018-01-05</Dt></BookgDt>
+        <ValDt><Dt>2018-01-04</Dt></ValDt>
+        <AcctSvcrRef>2018010501</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <RltdPties><Cdtr><Nm>Supermarket</Nm></Cdtr></RltdPties>
+          <RmtInf><Ustrd>Weekly shop</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">1500.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts><Cd>PDNG</Cd></Sts>
+        <ValDt><DtTm>2018-01-06T09:30:00+01:00</DtTm></ValDt>
+        <NtryDtls><TxDtls>
+          <Refs><AcctSvcrRef>2018010601</AcctSvcrRef></Refs>
+          <RltdPties><Dbtr><Pty><Nm>Employer &amp; Co</Nm></Pty></Dbtr></RltdPties>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">5.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>INFO</Sts>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">abc</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2018-01-07</Dt></BookgDt>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>
+`
+
+func TestReadCAMT(t *testing.T) {
+	assert := assert.New(t)
+
+	statements, err := ReadCAMT(strings.NewReader(camt053))
+	assert.Nil(err)
+	assert.Len(statements, 1)
+
+	s := statements[0]
+	assert.Equal("DE89370400440532013000", s.Account)
+	assert.Len(s.Transactions, 2)
+	assert.Equal(Transaction{
+		Line:        18,
+		Date:        date(2018, 1, 5),
+		ValueDate:   date(2018, 1, 4),
+		Amount:      dec("-42.10"),
+		Description: "Supermarket Weekly shop",
+		ImportID:    "2018010501",
+	}, s.Transactions[0])
+
+	income := s.Transactions[1]
+	assert.Equal(date(2018, 1, 6), income.Date)
+	assert.True(dec("1500").Equal(income.Amount))
+	assert.Equal("Employer & Co", income.Description)
+	assert.Equal("2018010601", income.ImportID)
+	assert.True(income.Uncleared)
+
+	assert.Equal([]Problem{{Line: 40, Reason: "entry with status INFO"}}, s.Skipped)
+	assert.Equal([]Problem{{Line: 45, Reason: `invalid amount "abc"`}}, s.Failed)
+	assert.Equal(date(2017, 12, 31), s.Opening.Date)
+	assert.True(dec("1000").Equal(s.Opening.Amount))
+	assert.Equal(date(2018, 1, 31), s.Balance.Date)
+	assert.True(dec("2457.90").Equal(s.Balance.Amount))
+
+	_, err = ReadCAMT(strings.NewReader(ofxSGML))
+	assert.EqualError(err, "not a camt.053 file")
+}
+
+func TestReadCAMT_Reversal(t *testing.T) {
+	assert := assert.New(t)
+
+	in := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+  <BkToCstmrStmt><Stmt>
+    <Acct><Id><Othr><Id>0532013000</Id></Othr></Id></Acct>
+    <Ntry>
+      <NtryRef>R1</NtryRef>
+      <Amt Ccy="EUR">42.10</Amt>
+      <CdtDbtInd>CRDT</CdtDbtInd>
+      <RvslInd>true</RvslInd>
+      <Sts><Cd>BOOK</Cd></Sts>
+      <BookgDt><Dt>2018-01-09</Dt></BookgDt>
+      <AddtlNtryInf>Return of   direct debit</AddtlNtryInf>
+    </Ntry>
+    <Ntry>
+      <NtryRef>R2</NtryRef>
+      <Amt Ccy="EUR">15.00</Amt>
+      <CdtDbtInd>DBIT</CdtDbtInd>
+      <RvslInd>true</RvslInd>
+      <Sts><Cd>BOOK</Cd></Sts>
+      <BookgDt><Dt>2018-01-10</Dt></BookgDt>
+      <AddtlNtryInf>Return of a transfer received</AddtlNtryInf>
+    </Ntry>
+  </Stmt></BkToCstmrStmt>
+</Document>
+`
+	statements, err := ReadCAMT(strings.NewReader(in))
+	assert.Nil(err)
+	assert.Len(statements, 1)
+
+	// A returned direct debit is a credit, and a returned credit a debit.
+	s := statements[0]
+	assert.Equal("0532013000", s.Account)
+	assert.Equal([]Transaction{{
+		Line:        5,
+		Date:        date(2018, 1, 9),
+		Amount:      dec("42.10"),
+		Description: "Return of direct debit",
+		ImportID:    "R1",
+	}, {
+		Line:        14,
+		Date:        date(2018, 1, 10),
+		Amount:      dec("-15.00"),
+		Description: "Return of a transfer received",
+		ImportID:    "R2",
+	}}, s.Transactions)
+}
+
+func TestImport_OpeningBalance(t *testing.T) {
+	assert := assert.New(t)
+
+	b := budgeting.NewBudget("My Budget")
+	a, _ := b.AddAccount("Checking", dec("900"), date(2017, 12, 1))
+
+	statements, _ := ReadCAMT(strings.NewReader(camt053))
+	statements[0].UseValueDates()
+	r := Import(a, statements[0])
+	assert.Equal("2 imported, 1 skipped, 1 failed", r.Summary())
+	assert.Equal(date(2018, 1, 4), r.Imported[0].Date())
+	assert.False(r.Imported[1].Cleared())
+
+	assert.Equal(date(2017, 12, 31), r.Opening.Date)
+	assert.True(dec("100").Equal(r.Opening.Difference()))
+	assert.True(dec("100").Equal(r.Reconciliation.Difference()))
+}
@@ -0,0 +1,355 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Profile describes the CSV layout of a bank's exports.
+//
+// Columns are given by their name in the header row or, without one, by
+// their number counting from 1.
+type Profile struct {
+	Name string `json:"name"`
+
+	// Delimiter separates the fields of a row. It is a comma unless given.
+	Delimiter string `json:"delimiter,omitempty"`
+
+	// SkipLines is the number of rows before the header, or before the
+	// first transaction without a header, such as the account details some
+	// banks start with. Empty lines don't count.
+	SkipLines int `json:"skip_lines,omitempty"`
+
+	// Header tells whether the first row names the columns.
+	Header bool `json:"header"`
+
+	// Date is the column of the transaction date, written as DateFormat.
+	Date string `json:"date"`
+
+	// DateFormat is made of YYYY, YY, MM, M, DD and D for the year, month
+	// and day, such as "DD/MM/YYYY". It is YYYY-MM-DD unless given.
+	DateFormat string `json:"date_format,omitempty"`
+
+	// Amount is the column of signed amounts, negative for money leaving
+	// the account. Banks without one have Inflow and Outflow columns
+	// instead, for money entering and leaving the account.
+	Amount  string `json:"amount,omitempty"`
+	Inflow  string `json:"inflow,omitempty"`
+	Outflow string `json:"outflow,omitempty"`
+
+	// Negate flips the sign of amounts, for exports (usually of credit
+	// cards) where spending is positive.
+	Negate bool `json:"negate,omitempty"`
+
+	// DecimalSeparator is "." unless given. ThousandsSeparator, if any, is
+	// ignored in amounts.
+	DecimalSeparator   string `json:"decimal_separator,omitempty"`
+	ThousandsSeparator string `json:"thousands_separator,omitempty"`
+
+	// Description are the columns making up the description, joined by
+	// spaces, such as the payee and a memo.
+	Description []string `json:"description,omitempty"`
+}
+
+// Validate checks that the profile says where to find dates and amounts.
+func (p Profile) Validate() error {
+	if p.Date == "" {
+		return fmt.Errorf("profile %q: no date column", p.Name)
+	}
+	if p.Amount == "" && p.Inflow == "" && p.Outflow == "" {
+		return fmt.Errorf("profile %q: no amount, inflow or outflow column", p.Name)
+	}
+	if p.Amount != "" && (p.Inflow != "" || p.Outflow != "") {
+		return fmt.Errorf("profile %q: amount column can't be combined with inflow and outflow columns", p.Name)
+	}
+	if len([]rune(p.Delimiter)) > 1 {
+		return fmt.Errorf("profile %q: delimiter must be a single character", p.Name)
+	}
+	return nil
+}
+
+// columns maps the columns of a profile to field indexes.
+type columns struct {
+	date, amount, inflow, outflow int
+	description                   []int
+}
+
+// ReadCSV reads the rows of a CSV export laid out as the profile says.
+// Blank rows and rows without a date or amount, such as totals, are
+// skipped.
+//
+// CSV exports don't give transactions an ID, so each row is given one made
+// of its date, amount and description, and how many rows before it have
+// the same. Importing overlapping exports thus only adds the new rows.
+func ReadCSV(r io.Reader, p Profile) (*Statement, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	layout := dateLayout(p.DateFormat)
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	if p.Delimiter != "" {
+		cr.Comma = []rune(p.Delimiter)[0]
+	}
+
+	s := &Statement{}
+	seen := map[string]int{}
+	var cols *columns
+	for n := 1; ; n++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if pe, ok := err.(*csv.ParseError); ok && n > p.SkipLines {
+				s.fail(pe.StartLine, "%v", pe.Err)
+				continue
+			}
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		if n <= p.SkipLines {
+			continue
+		}
+
+		if cols == nil {
+			var header []string
+			if p.Header {
+				header = record
+			}
+			if cols, err = p.columns(header); err != nil {
+				return nil, err
+			}
+			if p.Header {
+				continue
+			}
+		}
+
+		if blank(record) {
+			s.skip(line, "blank row")
+			continue
+		}
+
+		t, err := p.transaction(record, cols, layout)
+		if err == errNotTransaction {
+			s.skip(line, "no date or amount")
+			continue
+		}
+		if err != nil {
+			s.fail(line, "%v", err)
+			continue
+		}
+
+		t.Line = line
+		key := fmt.Sprintf("%s\x00%s\x00%s", t.Date.Format("2006-01-02"), t.Amount, t.Description)
+		seen[key]++
+		t.ImportID = csvImportID(key, seen[key])
+		s.Transactions = append(s.Transactions, t)
+	}
+
+	return s, nil
+}
+
+// csvImportID is the ID of the nth row of a CSV export with the same date,
+// amount and description.
+func csvImportID(key string, n int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", key, n)))
+	return "csv:" + hex.EncodeToString(sum[:8])
+}
+
+var errNotTransaction = fmt.Errorf("not a transaction")
+
+func (p Profile) transaction(record []string, cols *columns, layout string) (Transaction, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	// Totals and other summary rows have a label instead of a date.
+	date := field(cols.date)
+	if !strings.ContainsAny(date, "0123456789") {
+		return Transaction{}, errNotTransaction
+	}
+
+	var amount decimal.Decimal
+	if cols.amount >= 0 {
+		if field(cols.amount) == "" {
+			return Transaction{}, errNotTransaction
+		}
+		a, err := p.parseAmount(field(cols.amount))
+		if err != nil {
+			return Transaction{}, err
+		}
+		amount = a
+	} else {
+		in, out := field(cols.inflow), field(cols.outflow)
+		if in == "" && out == "" {
+			return Transaction{}, errNotTransaction
+		}
+		if in != "" {
+			a, err := p.parseAmount(in)
+			if err != nil {
+				return Transaction{}, err
+			}
+			amount = amount.Add(a)
+		}
+		if out != "" {
+			a, err := p.parseAmount(out)
+			if err != nil {
+				return Transaction{}, err
+			}
+			// Some banks sign outflows, others don't.
+			amount = amount.Sub(a.Abs())
+		}
+	}
+	if p.Negate {
+		amount = amount.Neg()
+	}
+
+	d, err := time.Parse(layout, date)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("invalid date %q, want one like %s", date, p.dateFormat())
+	}
+
+	parts := []string{}
+	for _, i := range cols.description {
+		if f := field(i); f != "" {
+			parts = append(parts, f)
+		}
+	}
+
+	return Transaction{
+		Date:        d,
+		Amount:      amount,
+		Description: strings.Join(parts, " "),
+	}, nil
+}
+
+// parseAmount parses an amount written with the profile's separators. It
+// also accepts amounts in parentheses, which accountants use for negative
+// amounts, and currency symbols.
+func (p Profile) parseAmount(s string) (decimal.Decimal, error) {
+	orig := s
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	if p.ThousandsSeparator != "" {
+		s = strings.Replace(s, p.ThousandsSeparator, "", -1)
+	}
+	if sep := p.DecimalSeparator; sep != "" && sep != "." {
+		s = strings.Replace(s, sep, ".", -1)
+	}
+	s = strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' || r == '.' || r == '-' || r == '+' {
+			return r
+		}
+		if r == ' ' || r == '\u00a0' || strings.ContainsRune("$€£¥", r) || r >= 'A' && r <= 'Z' {
+			return -1
+		}
+		return r
+	}, s)
+
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("invalid amount %q", orig)
+	}
+	if negative {
+		d = d.Neg()
+	}
+	return d, nil
+}
+
+func (p Profile) columns(header []string) (*columns, error) {
+	find := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+
+		if header == nil {
+			n, err := strconv.Atoi(name)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("profile %q: without a header, columns are numbered from 1, not %q", p.Name, name)
+			}
+			return n - 1, nil
+		}
+
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), name) {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("profile %q: no column %q in header %q", p.Name, name, strings.Join(header, ","))
+	}
+
+	c := &columns{}
+	var err error
+	if c.date, err = find(p.Date); err != nil {
+		return nil, err
+	}
+	if c.amount, err = find(p.Amount); err != nil {
+		return nil, err
+	}
+	if c.inflow, err = find(p.Inflow); err != nil {
+		return nil, err
+	}
+	if c.outflow, err = find(p.Outflow); err != nil {
+		return nil, err
+	}
+	for _, name := range p.Description {
+		i, err := find(name)
+		if err != nil {
+			return nil, err
+		}
+		c.description = append(c.description, i)
+	}
+	return c, nil
+}
+
+func (p Profile) dateFormat() string {
+	if p.DateFormat == "" {
+		return "YYYY-MM-DD"
+	}
+	return p.DateFormat
+}
+
+// dateLayout turns a date format like DD/MM/YYYY into a layout for time.Parse.
+func dateLayout(format string) string {
+	if format == "" {
+		format = "YYYY-MM-DD"
+	}
+
+	r := strings.NewReplacer(
+		"YYYY", "2006",
+		"YY", "06",
+		"MM", "01",
+		"M", "1",
+		"DD", "02",
+		"D", "2",
+	)
+	return r.Replace(format)
+}
+
+func blank(record []string) bool {
+	for _, f := range record {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
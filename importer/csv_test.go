@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCSV_Header(t *testing.T) {
+	assert := assert.New(t)
+
+	in := `Account: 12345678
+Date,Payee,Memo,Amount
+2018-01-05,Supermarket,Weekly shop,-42.10
+2018-01-06,Employer,,"1,500.00"
+
+2018-01-07,Cafe,,abc
+Total,,,1457.90
+`
+	p := Profile{
+		SkipLines:          1,
+		Header:             true,
+		Date:               "date",
+		Amount:             "Amount",
+		ThousandsSeparator: ",",
+		Description:        []string{"Payee", "Memo"},
+	}
+
+	s, err := ReadCSV(strings.NewReader(in), p)
+	assert.Nil(err)
+	assert.Len(s.Transactions, 2)
+	assert.Equal(Transaction{Line: 3, Date: date(2018, 1, 5), Amount: dec("-42.10"), Description: "Supermarket Weekly shop", ImportID: "csv:85b12b9b891d11d9"}, s.Transactions[0])
+	assert.True(dec("1500").Equal(s.Transactions[1].Amount))
+	assert.Equal("Employer", s.Transactions[1].Description)
+	assert.Equal([]Problem{{Line: 6, Reason: `invalid amount "abc"`}}, s.Failed)
+	assert.Equal([]Problem{{Line: 7, Reason: "no date or amount"}}, s.Skipped)
+}
+
+func TestReadCSV_InflowOutflow(t *testing.T) {
+	assert := assert.New(t)
+
+	in := "05/01/2018;Supermarkt;12,50;\n" +
+		"06/01/2018;Gehalt;;1.500,00\n" +
+		";;;\n" +
+		"07/01/2018;Rückbuchung;(3,00);\n"
+	p := Profile{
+		Delimiter:          ";",
+		Date:               "1",
+		DateFormat:         "DD/MM/YYYY",
+		Outflow:            "3",
+		Inflow:             "4",
+		DecimalSeparator:   ",",
+		ThousandsSeparator: ".",
+		Description:        []string{"2"},
+	}
+
+	s, err := ReadCSV(strings.NewReader(in), p)
+	assert.Nil(err)
+	assert.Len(s.Transactions, 3)
+	assert.True(dec("-12.50").Equal(s.Transactions[0].Amount))
+	assert.Equal(date(2018, 1, 5), s.Transactions[0].Date)
+	assert.True(dec("1500").Equal(s.Transactions[1].Amount))
+	assert.True(dec("-3").Equal(s.Transactions[2].Amount))
+	assert.Equal([]Problem{{Line: 3, Reason: "blank row"}}, s.Skipped)
+}
+
+func TestReadCSV_Negate(t *testing.T) {
+	assert := assert.New(t)
+
+	p := Profile{Date: "1", DateFormat: "M/D/YY", Amount: "2", Negate: true}
+	s, err := ReadCSV(strings.NewReader("1/5/18,$42.10\n1/6/18,-10\n"), p)
+	assert.Nil(err)
+	assert.Equal(date(2018, 1, 5), s.Transactions[0].Date)
+	assert.True(dec("-42.10").Equal(s.Transactions[0].Amount))
+	assert.True(dec("10").Equal(s.Transactions[1].Amount))
+}
+
+func TestReadCSV_BadProfile(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ReadCSV(strings.NewReader(""), Profile{Name: "bank", Date: "1"})
+	assert.EqualError(err, `profile "bank": no amount, inflow or outflow column`)
+
+	_, err = ReadCSV(strings.NewReader("Date,Amount\n"), Profile{Name: "bank", Header: true, Date: "Date", Amount: "Value"})
+	assert.EqualError(err, `profile "bank": no column "Value" in header "Date,Amount"`)
+
+	_, err = ReadCSV(strings.NewReader("2018-01-01,1\n"), Profile{Name: "bank", Date: "Date", Amount: "2"})
+	assert.EqualError(err, `profile "bank": without a header, columns are numbered from 1, not "Date"`)
+}
+
+func TestReadCSV_ImportTwice(t *testing.T) {
+	assert := assert.New(t)
+
+	b := budgeting.NewBudget("My Budget")
+	a, _ := b.AddAccount("Checking", dec("100"), date(2018, 1, 1))
+
+	p := Profile{Date: "1", Amount: "2", Description: []string{"3"}}
+	in := "2018-01-05,-3.20,Bakery\n2018-01-05,-3.20,Bakery\n2018-01-06,-42.10,Supermarket\n"
+	s, _ := ReadCSV(strings.NewReader(in), p)
+	assert.NotEqual(s.Transactions[0].ImportID, s.Transactions[1].ImportID)
+	assert.Equal("3 imported, 0 skipped, 0 failed", Import(a, s).Summary())
+
+	// The transactions imported the first time aren't matched the second.
+	s, _ = ReadCSV(strings.NewReader(in), p)
+	r := Import(a, s)
+	assert.Equal("0 imported, 3 skipped, 0 failed", r.Summary())
+	assert.Empty(r.Matched)
+
+	// Overlapping exports only add the new rows.
+	s, _ = ReadCSV(strings.NewReader(in+"2018-01-05,-3.20,Bakery\n2018-01-07,-5,Cafe\n"), p)
+	assert.Equal("2 imported, 3 skipped, 0 failed", Import(a, s).Summary())
+	assert.Len(a.Transactions(), 6)
+}
@@ -0,0 +1,530 @@
+package importer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+)
+
+// Types of GnuCash accounts. Bank, cash and credit card accounts become
+// accounts, expense accounts categories, and income and equity is to be
+// budgeted. The other types have no equivalent.
+const (
+	gnucashBank    = "BANK"
+	gnucashCash    = "CASH"
+	gnucashCredit  = "CREDIT"
+	gnucashExpense = "EXPENSE"
+	gnucashIncome  = "INCOME"
+	gnucashEquity  = "EQUITY"
+	gnucashRoot    = "ROOT"
+)
+
+// gnucashUnmapped names the elements of a book which aren't imported.
+var gnucashUnmapped = map[string]string{
+	"PRICEDB":               "prices",
+	"SCHEDXACTION":          "scheduled transactions",
+	"BUDGET":                "budgets",
+	"GNCBILLTERM":           "billing terms",
+	"GNCCUSTOMER":           "customers",
+	"GNCEMPLOYEE":           "employees",
+	"GNCENTRY":              "invoice entries",
+	"GNCINVOICE":            "invoices",
+	"GNCJOB":                "jobs",
+	"GNCORDER":              "orders",
+	"GNCTAXTABLE":           "tax tables",
+	"GNCVENDOR":             "vendors",
+	"TEMPLATE-TRANSACTIONS": "",
+	"ACCOUNT":               "",
+	"TRANSACTION":           "",
+	"COMMODITY":             "",
+	"COUNT-DATA":            "",
+	"ID":                    "",
+	"SLOTS":                 "",
+}
+
+// GnuCashBook is what was read from a GnuCash book.
+type GnuCashBook struct {
+	// Statements are the transactions of every bank, cash and credit card
+	// account, in the order of the book. Transactions between several of
+	// them are only in the statement of the account the money left.
+	Statements []*Statement
+
+	// Skipped are the parts of the book which have no equivalent, and
+	// Failed those which couldn't be read.
+	Skipped []Problem
+	Failed  []Problem
+
+	// opened is the date of the first transaction of every account, which
+	// may be in the statement of another.
+	opened map[*Statement]time.Time
+}
+
+func (g *GnuCashBook) skip(line int, format string, args ...interface{}) {
+	g.Skipped = append(g.Skipped, Problem{Line: line, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (g *GnuCashBook) fail(line int, format string, args ...interface{}) {
+	g.Failed = append(g.Failed, Problem{Line: line, Reason: fmt.Sprintf(format, args...)})
+}
+
+// gnucashAccount is an account of a GnuCash book. Names don't have a
+// namespace, as GnuCash gives every kind of element its own.
+type gnucashAccount struct {
+	ID     string `xml:"id"`
+	Name   string `xml:"name"`
+	Kind   string `xml:"type"`
+	Parent string `xml:"parent"`
+
+	line int
+	used bool
+
+	// statement is the statement of bank, cash and credit card accounts.
+	statement *Statement
+}
+
+// gnucashTransactionElement is a transaction of a GnuCash book.
+type gnucashTransactionElement struct {
+	ID          string                `xml:"id"`
+	Posted      string                `xml:"date-posted>date"`
+	Description string                `xml:"description"`
+	Splits      []gnucashSplitElement `xml:"splits>split"`
+
+	line int
+}
+
+func (t *gnucashTransactionElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type transaction gnucashTransactionElement
+	line, _ := d.InputPos()
+	err := d.DecodeElement((*transaction)(t), &start)
+	t.line = line
+	return err
+}
+
+// gnucashSplitElement is a split of a GnuCash transaction.
+type gnucashSplitElement struct {
+	Account    string `xml:"account"`
+	Memo       string `xml:"memo"`
+	Value      string `xml:"value"`
+	Quantity   string `xml:"quantity"`
+	Reconciled string `xml:"reconciled-state"`
+
+	line int
+}
+
+func (sp *gnucashSplitElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type split gnucashSplitElement
+	line, _ := d.InputPos()
+	err := d.DecodeElement((*split)(sp), &start)
+	sp.line = line
+	return err
+}
+
+// ReadGnuCash reads a GnuCash book saved as XML, compressed or not.
+//
+// Expense accounts become categories, named after the account with the
+// parent accounts below the top expense account as their group, like
+// "Auto" and "Fuel" for Expenses:Auto:Fuel. Deeper accounts are named
+// like "Fuel:Diesel". Transactions with several splits become transfers
+// between accounts and split transactions. Money moved to and from other
+// accounts, such as stocks and loans, is left uncategorized.
+//
+// The GUIDs of transactions become their import IDs. Unreconciled
+// transactions are uncleared, and voided ones skipped.
+func ReadGnuCash(r io.Reader) (*GnuCashBook, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	// Books can be large, so the elements of the book are decoded one at
+	// a time.
+	d := xml.NewDecoder(r)
+	if start, err := rootElement(d); err != nil || start.Name.Local != "gnc-v2" {
+		return nil, fmt.Errorf("not a GnuCash book")
+	}
+	if err := gnucashBook(d); err != nil {
+		return nil, err
+	}
+
+	g := &GnuCashBook{Statements: []*Statement{}, opened: map[*Statement]time.Time{}}
+	accounts := []*gnucashAccount{}
+	transactions := []gnucashTransactionElement{}
+
+	// Elements of the same kind are reported once, after the accounts.
+	unmapped := []Problem{}
+	reported := map[string]bool{}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := tok.(xml.EndElement); ok {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		line, _ := d.InputPos()
+		switch name := strings.ToUpper(start.Name.Local); name {
+		case "ACCOUNT":
+			a := &gnucashAccount{line: line}
+			if err := d.DecodeElement(a, &start); err != nil {
+				return nil, err
+			}
+			a.Name = strings.TrimSpace(a.Name)
+			accounts = append(accounts, a)
+		case "TRANSACTION":
+			var t gnucashTransactionElement
+			if err := d.DecodeElement(&t, &start); err != nil {
+				return nil, err
+			}
+			transactions = append(transactions, t)
+		default:
+			what, ok := gnucashUnmapped[name]
+			if !ok {
+				what = strings.ToLower(name) + " elements"
+			}
+			if what != "" && !reported[name] {
+				unmapped = append(unmapped, Problem{Line: line, Reason: fmt.Sprintf("GnuCash %s aren't imported", what)})
+				reported[name] = true
+			}
+			if err := d.Skip(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	byID := map[string]*gnucashAccount{}
+	for _, a := range accounts {
+		byID[a.ID] = a
+	}
+
+	// Accounts are named after their parents when several have the same
+	// name.
+	names := map[string]int{}
+	for _, a := range accounts {
+		if a.mapsToAccount() {
+			names[strings.ToLower(a.Name)]++
+		}
+	}
+	for _, a := range accounts {
+		if a.mapsToAccount() {
+			name := a.Name
+			if names[strings.ToLower(name)] > 1 {
+				name = strings.Join(gnucashPath(byID, a), ":")
+			}
+			a.statement = &Statement{Account: name}
+		}
+	}
+
+	for _, t := range transactions {
+		readGnuCashTransaction(g, byID, t)
+	}
+
+	for _, a := range accounts {
+		switch {
+		case a.statement != nil && !a.used:
+			g.skip(a.line, "%s has no transactions", a.statement.Account)
+		case a.statement != nil:
+			g.Statements = append(g.Statements, a.statement)
+		case a.used && !a.mapsToAccount() && !a.mapsToBudget():
+			g.skip(a.line, "%s is a %s account, which has no equivalent: money moved to and from it is uncategorized",
+				strings.Join(gnucashPath(byID, a), ":"), strings.ToLower(a.Kind))
+		}
+	}
+	g.Skipped = append(g.Skipped, unmapped...)
+
+	return g, nil
+}
+
+// gnucashBook reads up to the start of the book, skipping what comes
+// before it.
+func gnucashBook(d *xml.Decoder) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if tok.Name.Local == "book" {
+				return nil
+			}
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return fmt.Errorf("no book in the GnuCash file")
+		}
+	}
+}
+
+func (a *gnucashAccount) mapsToAccount() bool {
+	return a.Kind == gnucashBank || a.Kind == gnucashCash || a.Kind == gnucashCredit
+}
+
+func (a *gnucashAccount) mapsToBudget() bool {
+	return a.Kind == gnucashExpense || a.Kind == gnucashIncome || a.Kind == gnucashEquity || a.Kind == gnucashRoot
+}
+
+// gnucashPath returns the names of the account and its parents, from the
+// top, without the root account.
+func gnucashPath(byID map[string]*gnucashAccount, a *gnucashAccount) []string {
+	path := []string{}
+	for ; a != nil && a.Kind != gnucashRoot; a = byID[a.Parent] {
+		path = append([]string{a.Name}, path...)
+	}
+	return path
+}
+
+// gnucashCategory returns the group and name of the category of an expense
+// account.
+func gnucashCategory(byID map[string]*gnucashAccount, a *gnucashAccount) (string, string) {
+	names := []string{}
+	for ; a != nil && a.Kind == gnucashExpense; a = byID[a.Parent] {
+		names = append([]string{a.Name}, names...)
+	}
+
+	// The top expense account, usually Expenses, only holds the others.
+	if len(names) > 1 {
+		names = names[1:]
+	}
+	if len(names) == 1 {
+		return "", names[0]
+	}
+	return names[0], strings.Join(names[1:], ":")
+}
+
+// gnucashSplit is a split of a GnuCash transaction.
+type gnucashSplit struct {
+	account *gnucashAccount
+	memo    string
+
+	// value is in the currency of the transaction, and quantity in that
+	// of the account.
+	value, quantity decimal.Decimal
+	reconciled      string
+}
+
+func readGnuCashTransaction(g *GnuCashBook, byID map[string]*gnucashAccount, trn gnucashTransactionElement) {
+	splits := []gnucashSplit{}
+	for _, e := range trn.Splits {
+		a := byID[e.Account]
+		if a == nil {
+			g.fail(e.line, "no account %q", e.Account)
+			return
+		}
+		value, err := parseGnuCashAmount(e.Value)
+		if err != nil {
+			g.fail(e.line, "invalid value %q", e.Value)
+			return
+		}
+		quantity, err := parseGnuCashAmount(e.Quantity)
+		if err != nil {
+			g.fail(e.line, "invalid quantity %q", e.Quantity)
+			return
+		}
+		a.used = true
+		splits = append(splits, gnucashSplit{
+			account:    a,
+			memo:       strings.TrimSpace(e.Memo),
+			value:      value,
+			quantity:   quantity,
+			reconciled: e.Reconciled,
+		})
+	}
+
+	// The transaction goes in the statement of the account the money left,
+	// as the first of its splits on an account; the other accounts get
+	// their side as transfers from it.
+	main := -1
+	for i, sp := range splits {
+		if sp.account.statement != nil && (main < 0 || splits[main].quantity.IsPositive() && sp.quantity.IsNegative()) {
+			main = i
+		}
+	}
+	if main < 0 {
+		return
+	}
+	s := splits[main].account.statement
+
+	date, err := parseGnuCashDate(trn.Posted)
+	if err != nil {
+		s.fail(trn.line, "%v", err)
+		return
+	}
+	if splits[main].reconciled == "v" {
+		s.skip(trn.line, "voided transaction")
+		return
+	}
+	for _, sp := range splits {
+		if other := sp.account.statement; other != nil {
+			if opened, ok := g.opened[other]; !ok || date.Before(opened) {
+				g.opened[other] = date
+			}
+		}
+	}
+
+	t := Transaction{
+		Line:        trn.line,
+		Date:        date,
+		Amount:      splits[main].quantity,
+		Description: strings.TrimSpace(trn.Description),
+		ImportID:    trn.ID,
+		Uncleared:   splits[main].reconciled == "n",
+	}
+	if memo := splits[main].memo; memo != "" && memo != t.Description {
+		t.Description = strings.TrimSpace(t.Description + " " + memo)
+	}
+
+	currencies := false
+	for i, sp := range splits {
+		// The quantities of stocks are numbers of shares.
+		if sp.account.statement != nil || sp.account.mapsToBudget() {
+			currencies = currencies || !sp.value.Equal(sp.quantity)
+		}
+		if i == main {
+			continue
+		}
+
+		part := Split{Amount: sp.value.Neg(), Memo: sp.memo}
+		switch a := sp.account; {
+		case a.statement != nil:
+			part.Transfer = a.statement.Account
+		case a.Kind == gnucashExpense:
+			part.Group, part.Category = gnucashCategory(byID, a)
+		case a.Kind == gnucashIncome:
+			part.Income = true
+		case a.Kind == gnucashEquity && len(splits) == 2:
+			// A transfer to the account itself is an opening balance.
+			part.Transfer = s.Account
+		case a.Kind == gnucashEquity:
+			// Equity among other splits isn't the plain opening entry, but
+			// money to be budgeted all the same.
+			part.Income = true
+		}
+		t.Splits = append(t.Splits, part)
+	}
+	if currencies {
+		g.skip(trn.line, "the transaction is in several currencies: its amounts are imported unconverted, and what doesn't add up is uncategorized")
+	}
+
+	// Transactions with a single other split are a plain transaction.
+	if len(t.Splits) == 1 {
+		sp := t.Splits[0]
+		t.Splits = nil
+		t.Category, t.Group, t.Transfer, t.Income = sp.Category, sp.Group, sp.Transfer, sp.Income
+		if sp.Memo != "" && !strings.Contains(t.Description, sp.Memo) {
+			t.Description = strings.TrimSpace(t.Description + " " + sp.Memo)
+		}
+	}
+
+	s.Transactions = append(s.Transactions, t)
+}
+
+// parseGnuCashAmount parses the fractions GnuCash writes amounts as, like
+// -4210/100.
+func parseGnuCashAmount(s string) (decimal.Decimal, error) {
+	num, den := s, "1"
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		num, den = s[:i], s[i+1:]
+	}
+
+	n, err := decimal.NewFromString(num)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("invalid amount %q", s)
+	}
+	d, err := decimal.NewFromString(den)
+	if err != nil || d.IsZero() {
+		return decimal.Decimal{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	// Amounts of money are in cents or the like, and keep their digits.
+	if strings.Trim(den[1:], "0") == "" && den[0] == '1' {
+		return n.Shift(-int32(len(den) - 1)), nil
+	}
+	return n.Div(d), nil
+}
+
+// parseGnuCashDate parses a timestamp like 2018-01-05 10:59:00 +0000,
+// keeping only the date. Older versions of GnuCash wrote midnight in the
+// time zone of the computer, which is the date of the transaction.
+func parseGnuCashDate(s string) (time.Time, error) {
+	if len(s) < 10 {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+
+	d, err := time.Parse("2006-01-02", s[:10])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return d, nil
+}
+
+// ImportGnuCash adds the bank, cash and credit card accounts of a GnuCash
+// book and their transactions to the budget. Every account is created, with
+// the first transaction from equity as its starting balance; accounts which
+// already exist fail. Categories are matched by group and name, and created
+// if missing. Transactions are added approved, as they were in GnuCash.
+func ImportGnuCash(b *budgeting.Budget, book *GnuCashBook) *MigrationReport {
+	r := &MigrationReport{
+		Accounts:     []*budgeting.Account{},
+		Categories:   []*budgeting.Category{},
+		Transactions: []*Report{},
+		Skipped:      append([]Problem{}, book.Skipped...),
+		Failed:       append([]Problem{}, book.Failed...),
+		Differences:  []Difference{},
+	}
+	before := map[string]bool{}
+	for _, c := range b.Categories() {
+		before[c.ID()] = true
+	}
+
+	// Every account has to exist before transfers between them are added.
+	accounts := make([]*budgeting.Account, len(book.Statements))
+	for i, s := range book.Statements {
+		a, err := createAccount(b, s, book.opened[s], func(first Transaction) bool {
+			return strings.EqualFold(first.Transfer, s.Account) && len(first.Splits) == 0
+		})
+		if err != nil {
+			r.Failed = append(r.Failed, Problem{Line: firstLine(s), Reason: err.Error()})
+			continue
+		}
+		accounts[i] = a
+		r.Accounts = append(r.Accounts, a)
+	}
+
+	// The accounts are new, so there is nothing to match, and the side of
+	// a transfer added from another account mustn't be taken for one of
+	// their own transactions.
+	for i, s := range book.Statements {
+		if accounts[i] != nil {
+			r.Transactions = append(r.Transactions, Import(accounts[i], s, Approved(), NoMatching()))
+		}
+	}
+
+	for _, c := range b.Categories() {
+		if !before[c.ID()] {
+			r.Categories = append(r.Categories, c)
+		}
+	}
+	sort.SliceStable(r.Skipped, func(i, j int) bool { return r.Skipped[i].Line < r.Skipped[j].Line })
+	return r
+}
@@ -0,0 +1,232 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/stretchr/testify/assert"
+)
+
+func gnucashAccountXML(id, name, kind, parent string) string {
+	xml := `<gnc:account version="2.0.0">
+  <act:name>` + name + `</act:name>
+  <act:id type="guid">` + id + `</act:id>
+  <act:type>` + kind + `</act:type>
+  <act:commodity><cmdty:space>CURRENCY</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+`
+	if parent != "" {
+		xml += `  <act:parent type="guid">` + parent + "</act:parent>\n"
+	}
+	return xml + "</gnc:account>\n"
+}
+
+// gnucashTransactionXML writes a transaction with splits like
+// "checking -42.10 c", of an account, an amount and a reconciled state.
+func gnucashTransactionXML(id, date, description string, splits ...string) string {
+	xml := `<gnc:transaction version="2.0.0">
+  <trn:id type="guid">` + id + `</trn:id>
+  <trn:currency><cmdty:space>CURRENCY</cmdty:space><cmdty:id>EUR</cmdty:id></trn:currency>
+  <trn:date-posted><ts:date>` + date + ` 10:59:00 +0000</ts:date></trn:date-posted>
+  <trn:description>` + description + `</trn:description>
+  <trn:splits>
+`
+	for i, sp := range splits {
+		fields := strings.Fields(sp)
+		d := dec(fields[1])
+		quantity := d.Mul(dec("100")).String() + "/100"
+		if len(fields) > 3 {
+			quantity = fields[3]
+		}
+		xml += fmt.Sprintf(`    <trn:split>
+      <split:id type="guid">%s-%d</split:id>
+      <split:reconciled-state>%s</split:reconciled-state>
+      <split:value>%s/100</split:value>
+      <split:quantity>%s</split:quantity>
+      <split:account type="guid">%s</split:account>
+    </trn:split>
+`, id, i, fields[2], d.Mul(dec("100")), quantity, fields[0])
+	}
+	return xml + "  </trn:splits>\n</gnc:transaction>\n"
+}
+
+func gnucashBookXML() string {
+	return `<?xml version="1.0" encoding="utf-8" ?>
+<gnc-v2
+     xmlns:gnc="http://www.gnucash.org/XML/gnc"
+     xmlns:act="http://www.gnucash.org/XML/act"
+     xmlns:trn="http://www.gnucash.org/XML/trn">
+<gnc:count-data cd:type="book">1</gnc:count-data>
+<gnc:book version="2.0.0">
+<book:id type="guid">book</book:id>
+<gnc:commodity version="2.0.0"><cmdty:space>CURRENCY</cmdty:space><cmdty:id>EUR</cmdty:id></gnc:commodity>
+<gnc:pricedb version="1"></gnc:pricedb>
+` + gnucashAccountXML("root", "Root Account", "ROOT", "") +
+		gnucashAccountXML("assets", "Assets", "ASSET", "root") +
+		gnucashAccountXML("checking", "Checking", "BANK", "assets") +
+		gnucashAccountXML("savings", "Savings", "BANK", "assets") +
+		gnucashAccountXML("old", "Old bank", "BANK", "assets") +
+		gnucashAccountXML("broker", "Broker", "STOCK", "assets") +
+		gnucashAccountXML("liabilities", "Liabilities", "LIABILITY", "root") +
+		gnucashAccountXML("visa", "Visa", "CREDIT", "liabilities") +
+		gnucashAccountXML("expenses", "Expenses", "EXPENSE", "root") +
+		gnucashAccountXML("groceries", "Groceries", "EXPENSE", "expenses") +
+		gnucashAccountXML("auto", "Auto", "EXPENSE", "expenses") +
+		gnucashAccountXML("fuel", "Fuel", "EXPENSE", "auto") +
+		gnucashAccountXML("income", "Income", "INCOME", "root") +
+		gnucashAccountXML("salary", "Salary", "INCOME", "income") +
+		gnucashAccountXML("equity", "Equity", "EQUITY", "root") +
+		gnucashAccountXML("opening", "Opening Balances", "EQUITY", "equity") +
+		gnucashTransactionXML("t1", "2018-01-01", "Opening Balance", "checking 1000 y", "opening -1000 n") +
+		gnucashTransactionXML("t2", "2018-01-05", "Supermarket", "groceries 42.10 n", "checking -42.10 c") +
+		gnucashTransactionXML("t3", "2018-01-06", "Gas station &amp; shop", "checking -100 n", "fuel 60 n", "groceries 40 n") +
+		gnucashTransactionXML("t4", "2017-12-20", "Savings", "savings 200 c", "checking -200 c") +
+		gnucashTransactionXML("t5", "2018-01-25", "Salary", "checking 1500 c", "salary -1500 n") +
+		gnucashTransactionXML("t6", "2018-01-26", "Shares", "checking -300 c", "broker 300 n 3/1") +
+		gnucashTransactionXML("t7", "2018-01-27", "Savings with fee", "checking -105 c", "savings 100 c", "expenses 5 n") +
+		gnucashTransactionXML("t8", "2018-01-28", "Voided", "checking 0 v", "groceries 0 v") +
+		gnucashTransactionXML("t9", "2018-01-29", "Bakery", "visa -20 n", "groceries 20 n") +
+		gnucashTransactionXML("t10", "2018-01-30", "Dividend", "broker 10 n 0/1", "income -10 n") + `<gnc:template-transactions>
+` + gnucashTransactionXML("template", "2018-02-01", "Rent", "checking -500 n", "expenses 500 n") + `</gnc:template-transactions>
+<gnc:schedxaction version="2.0.0"><sx:name>Rent</sx:name></gnc:schedxaction>
+<gnc:budget version="2.0.0"><bgt:name>2018</bgt:name></gnc:budget>
+</gnc:book>
+</gnc-v2>
+`
+}
+
+func TestReadGnuCash(t *testing.T) {
+	assert := assert.New(t)
+
+	book, err := ReadGnuCash(strings.NewReader(gnucashBookXML()))
+	assert.Nil(err)
+	assert.Len(book.Statements, 3)
+
+	checking := book.Statements[0]
+	assert.Equal("Checking", checking.Account)
+	assert.Len(checking.Transactions, 7)
+	assert.Equal("Checking", checking.Transactions[0].Transfer)
+	shop := checking.Transactions[1]
+	assert.Equal(date(2018, 1, 5), shop.Date)
+	assert.Equal("-42.10", shop.Amount.StringFixed(2))
+	assert.Equal("Supermarket", shop.Description)
+	assert.Equal("t2", shop.ImportID)
+	assert.Equal("Groceries", shop.Category)
+	assert.False(shop.Uncleared)
+
+	split := checking.Transactions[2]
+	assert.Equal("Gas station & shop", split.Description)
+	assert.True(split.Uncleared)
+	assert.Len(split.Splits, 2)
+	assert.Equal("Auto", split.Splits[0].Group)
+	assert.Equal("Fuel", split.Splits[0].Category)
+	assert.True(dec("-60").Equal(split.Splits[0].Amount))
+	assert.Equal("Groceries", split.Splits[1].Category)
+
+	assert.Equal("Savings", checking.Transactions[3].Transfer)
+	assert.True(checking.Transactions[4].Income)
+	assert.Equal("", checking.Transactions[5].Category)
+
+	fee := checking.Transactions[6]
+	assert.Equal("Savings", fee.Splits[0].Transfer)
+	assert.True(dec("-100").Equal(fee.Splits[0].Amount))
+	assert.Equal("Expenses", fee.Splits[1].Category)
+	assert.True(dec("-5").Equal(fee.Splits[1].Amount))
+	assert.Equal("voided transaction", checking.Skipped[0].Reason)
+
+	assert.Equal("Savings", book.Statements[1].Account)
+	assert.Len(book.Statements[1].Transactions, 0)
+	assert.Equal("Visa", book.Statements[2].Account)
+
+	reasons := []string{}
+	for _, p := range book.Skipped {
+		reasons = append(reasons, p.Reason)
+	}
+	assert.Equal([]string{
+		"Old bank has no transactions",
+		"Assets:Broker is a stock account, which has no equivalent: money moved to and from it is uncategorized",
+		"GnuCash prices aren't imported",
+		"GnuCash scheduled transactions aren't imported",
+		"GnuCash budgets aren't imported",
+	}, reasons)
+	assert.Len(book.Failed, 0)
+
+	_, err = ReadGnuCash(strings.NewReader(ofxSGML))
+	assert.EqualError(err, "not a GnuCash book")
+}
+
+func TestReadGnuCash_EquityAndFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	in := `<?xml version="1.0" encoding="utf-8" ?>
+<gnc-v2>
+<gnc:book version="2.0.0">
+` + gnucashAccountXML("root", "Root Account", "ROOT", "") +
+		gnucashAccountXML("checking", "Checking", "BANK", "root") +
+		gnucashAccountXML("opening", "Opening Balances", "EQUITY", "root") +
+		gnucashAccountXML("groceries", "Groceries", "EXPENSE", "root") +
+		gnucashTransactionXML("t1", "2018-01-01", "Opening Balance", "checking 1000 y", "opening -1000 n") +
+		gnucashTransactionXML("t2", "2018-01-02", "Opening Balance less a purchase", "checking 960 y", "opening -1000 n", "groceries 40 n") +
+		gnucashTransactionXML("t3", "2018-01-03", "Supermarket", "checking -42.10 n abc", "groceries 42.10 n") + `</gnc:book>
+</gnc-v2>
+`
+	book, err := ReadGnuCash(strings.NewReader(in))
+	assert.Nil(err)
+
+	checking := book.Statements[0]
+	assert.Len(checking.Transactions, 2)
+	assert.Equal("Checking", checking.Transactions[0].Transfer)
+	assert.False(checking.Transactions[0].Income)
+
+	// Equity with other splits is to be budgeted, not an opening balance.
+	opening := checking.Transactions[1]
+	assert.Equal("", opening.Splits[0].Transfer)
+	assert.True(opening.Splits[0].Income)
+	assert.True(dec("1000").Equal(opening.Splits[0].Amount))
+	assert.Equal("Groceries", opening.Splits[1].Category)
+
+	assert.Len(book.Failed, 1)
+	assert.Equal(`invalid quantity "abc"`, book.Failed[0].Reason)
+}
+
+func TestImportGnuCash(t *testing.T) {
+	assert := assert.New(t)
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(gnucashBookXML()))
+	gz.Close()
+
+	book, err := ReadGnuCash(&compressed)
+	assert.Nil(err)
+
+	b := budgeting.NewBudget("My Budget")
+	r := ImportGnuCash(b, book)
+	assert.Len(r.Accounts, 3)
+	assert.Len(r.Failed, 0)
+
+	checking, savings, visa := r.Accounts[0], r.Accounts[1], r.Accounts[2]
+	assert.Equal("1752.9", checking.Balance().String())
+	assert.Equal("300", savings.Balance().String())
+	assert.Equal("-20", visa.Balance().String())
+
+	// Savings opens with the transfer into it, before the opening balance
+	// of Checking.
+	assert.Equal(date(2017, 12, 20), savings.Transactions()[0].Date())
+	assert.Equal(date(2017, 12, 20), checking.Transactions()[0].Date())
+	assert.True(checking.Transactions()[0].Amount().IsZero())
+
+	names := []string{}
+	for _, c := range r.Categories {
+		names = append(names, c.Group()+"/"+c.Name())
+	}
+	assert.Equal([]string{"/Groceries", "Auto/Fuel", "/Expenses"}, names)
+
+	january := budgeting.YearMonth{Year: 2018, Month: 1}
+	assert.Equal("2500", b.TBB(january).String())
+	// Split transactions are a transaction per split.
+	assert.Equal("9 imported, 1 skipped, 0 failed", r.Transactions[0].Summary())
+}
@@ -0,0 +1,536 @@
+// Package importer reads transactions from the files banks and other
+// budgeting tools export, and adds them to accounts.
+//
+// Every format is read into a Statement, which Import then adds to an
+// account, reporting what became of each of its lines.
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+)
+
+// Transaction is a transaction read from a file, before it is added to an
+// account.
+type Transaction struct {
+	// Line is where the transaction starts in the file, for reporting.
+	Line int
+
+	Date        time.Time
+	Amount      decimal.Decimal
+	Description string
+
+	// ValueDate is when the money started or stopped earning interest, if
+	// the bank says. Date is when the bank booked the transaction, which
+	// may be a few days later or earlier.
+	ValueDate time.Time
+
+	// ImportID is the ID the bank gave the transaction, if any, or the one
+	// ReadCSV made up for its row. A transaction is only imported once
+	// into an account with its ID.
+	ImportID string
+
+	// Category is the name of the category of the transaction, if any,
+	// and Group the name of its group if the file has groups.
+	Category string
+	Group    string
+
+	// Uncleared tells whether the transaction hasn't cleared the bank yet,
+	// for files of apps which track it. The transactions of bank
+	// statements have cleared.
+	Uncleared bool
+
+	// Income tells whether the transaction is income to be budgeted.
+	Income bool
+
+	// Transfer is the name of the account the money moved to or from, if
+	// the transaction is a transfer. It is the name of the account itself
+	// for opening balances.
+	Transfer string
+
+	// Splits divide the transaction between categories and transfers.
+	// Budget transactions only have one category, so every split is
+	// imported as a transaction of its own.
+	Splits []Split
+}
+
+// Split is a part of a transaction with a category or transfer of its own.
+type Split struct {
+	Amount   decimal.Decimal
+	Category string
+	Group    string
+	Transfer string
+	Memo     string
+
+	// Income tells whether the split is income to be budgeted.
+	Income bool
+}
+
+// Statement is what was read from a file.
+type Statement struct {
+	// Account is the number or name of the account at the bank or in the
+	// exporting app, if the file says.
+	Account string
+
+	Transactions []Transaction
+
+	// Skipped and Failed are the lines which weren't read as transactions,
+	// and end up in the report of the import.
+	Skipped []Problem
+	Failed  []Problem
+
+	// Balance is the balance of the account the bank reported along with
+	// the transactions, if any.
+	Balance *Balance
+
+	// Opening is the balance the bank reported before the transactions,
+	// at the end of the day before the statement, if any.
+	Opening *Balance
+}
+
+// UseValueDates dates the transactions of the statement with their value
+// dates rather than the dates the bank booked them, when it gave them.
+func (s *Statement) UseValueDates() {
+	for i, t := range s.Transactions {
+		if !t.ValueDate.IsZero() {
+			s.Transactions[i].Date = t.ValueDate
+		}
+	}
+}
+
+// Balance is the balance of an account at the end of a day.
+type Balance struct {
+	Date   time.Time
+	Amount decimal.Decimal
+}
+
+func (s *Statement) skip(line int, format string, args ...interface{}) {
+	s.Skipped = append(s.Skipped, Problem{Line: line, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (s *Statement) fail(line int, format string, args ...interface{}) {
+	s.Failed = append(s.Failed, Problem{Line: line, Reason: fmt.Sprintf(format, args...)})
+}
+
+// Problem is a line of a file which wasn't imported, and why.
+type Problem struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("line %d: %s", p.Line, p.Reason)
+}
+
+// Report tells what became of the lines of an imported file.
+type Report struct {
+	// Account is the account the file was imported into.
+	Account *budgeting.Account `json:"-"`
+
+	// Imported are the transactions added to the account.
+	Imported []*budgeting.Transaction `json:"-"`
+
+	// Matched are the transactions which were already on the account,
+	// entered by hand.
+	Matched []Match `json:"-"`
+
+	// Categories are the categories created for imported transactions.
+	Categories []*budgeting.Category `json:"-"`
+
+	// Skipped are lines which aren't transactions, such as blank lines and
+	// totals.
+	Skipped []Problem `json:"skipped"`
+
+	// Failed are lines which look like transactions but couldn't be read
+	// or added.
+	Failed []Problem `json:"failed"`
+
+	// Reconciliation compares the account with the balance the bank
+	// reported, if it did.
+	Reconciliation *Reconciliation `json:"reconciliation,omitempty"`
+
+	// Opening compares the account with the opening balance the bank
+	// reported, if it did. A difference there comes from before the
+	// statement.
+	Opening *Reconciliation `json:"opening,omitempty"`
+}
+
+// Reconciliation compares the balance of an account after an import with
+// the balance the bank reported on the same day.
+type Reconciliation struct {
+	Date time.Time `json:"date"`
+
+	// Statement is the balance the bank reported and Account is the balance
+	// of the account, both at the end of Date.
+	Statement decimal.Decimal `json:"statement"`
+	Account   decimal.Decimal `json:"account"`
+}
+
+// Difference is the amount missing from the account to match the bank:
+// an adjustment of this amount reconciles the account.
+func (r *Reconciliation) Difference() decimal.Decimal {
+	return r.Statement.Sub(r.Account)
+}
+
+// Balanced tells whether the account matches the bank.
+func (r *Reconciliation) Balanced() bool {
+	return r.Difference().IsZero()
+}
+
+func (r *Report) skip(line int, format string, args ...interface{}) {
+	r.Skipped = append(r.Skipped, Problem{Line: line, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (r *Report) fail(line int, format string, args ...interface{}) {
+	r.Failed = append(r.Failed, Problem{Line: line, Reason: fmt.Sprintf(format, args...)})
+}
+
+// Summary is a one-line summary of the report.
+func (r *Report) Summary() string {
+	if len(r.Matched) > 0 {
+		return fmt.Sprintf("%d imported, %d matched, %d skipped, %d failed",
+			len(r.Imported), len(r.Matched), len(r.Skipped), len(r.Failed))
+	}
+	return fmt.Sprintf("%d imported, %d skipped, %d failed", len(r.Imported), len(r.Skipped), len(r.Failed))
+}
+
+// Import adds the transactions of the statement to the account, except
+// those already imported into it with the same import ID, and transfers
+// already imported from the other account. Categories missing from the
+// budget are created.
+//
+// Transactions entered by hand are matched with those of the statement on
+// amount, date and description, and given their import IDs instead of
+// being added twice. Options change how closely they must match.
+//
+// Added and matched transactions are unapproved, for someone to review.
+func Import(a *budgeting.Account, s *Statement, opts ...Option) *Report {
+	im := &importRun{
+		budget:  a.Budget(),
+		account: a,
+		options: newOptions(opts),
+		report: &Report{
+			Account:    a,
+			Imported:   []*budgeting.Transaction{},
+			Matched:    []Match{},
+			Categories: []*budgeting.Category{},
+			Skipped:    append([]Problem{}, s.Skipped...),
+			Failed:     append([]Problem{}, s.Failed...),
+		},
+		imported:   map[string]bool{},
+		categories: newCategoryIndex(a.Budget()),
+		claimed:    map[*budgeting.Transaction]bool{},
+	}
+
+	for _, t := range a.Transactions() {
+		if id := t.ImportID(); id != "" {
+			im.imported[id] = true
+		} else {
+			im.candidates = append(im.candidates, t)
+		}
+	}
+	for _, t := range s.Transactions {
+		im.add(t)
+	}
+	im.report.Categories = append(im.report.Categories, im.categories.created...)
+
+	if s.Balance != nil {
+		im.report.Reconciliation = reconcile(a, *s.Balance)
+	}
+	if s.Opening != nil {
+		im.report.Opening = reconcile(a, *s.Opening)
+	}
+
+	return im.report
+}
+
+// importRun is the state of an import into an account.
+type importRun struct {
+	budget  *budgeting.Budget
+	account *budgeting.Account
+	options options
+	report  *Report
+
+	// imported are the import IDs already used in the account.
+	imported map[string]bool
+
+	// candidates are the transactions on the account before the import
+	// without an import ID, which imported ones may match.
+	candidates []*budgeting.Transaction
+
+	categories *categoryIndex
+
+	// claimed are the transactions already on the account which matched
+	// one of the import.
+	claimed map[*budgeting.Transaction]bool
+}
+
+// add adds a transaction, or one for each of its splits.
+func (im *importRun) add(t Transaction) {
+	// Split transactions are imported with the IDs of their splits.
+	if t.ImportID != "" && (im.imported[t.ImportID] || im.imported[t.ImportID+"#1"]) {
+		im.report.skip(t.Line, "already imported %s", t.ImportID)
+		return
+	}
+
+	if len(t.Splits) == 0 {
+		whole := Split{Amount: t.Amount, Category: t.Category, Group: t.Group, Transfer: t.Transfer}
+		im.addPart(t, whole, t.Income, t.Description, t.ImportID)
+		return
+	}
+
+	rest := t.Amount
+	for i, sp := range t.Splits {
+		description := t.Description
+		if sp.Memo != "" {
+			description = strings.TrimSpace(description + " " + sp.Memo)
+		}
+
+		id := ""
+		if t.ImportID != "" {
+			id = fmt.Sprintf("%s#%d", t.ImportID, i+1)
+		}
+
+		im.addPart(t, sp, sp.Income, description, id)
+		rest = rest.Sub(sp.Amount)
+	}
+
+	// Splits of some apps don't add up to the transaction.
+	if !rest.IsZero() {
+		im.addPart(t, Split{Amount: rest}, false, t.Description, "")
+	}
+	if t.ImportID != "" {
+		im.imported[t.ImportID] = true
+	}
+}
+
+func (im *importRun) addPart(t Transaction, sp Split, income bool, description, importID string) {
+	var c *budgeting.Category
+	var rel *budgeting.Account
+	amount, transfer := sp.Amount, sp.Transfer
+
+	switch {
+	case income:
+		c = im.budget.TBBCategory()
+
+	case transfer != "" && strings.EqualFold(transfer, im.account.Name()):
+		// A transfer to the account itself is how QIF writes the opening
+		// balance, which is income like starting balances.
+		c = im.budget.TBBCategory()
+
+	case transfer != "":
+		other, err := im.findAccount(transfer)
+		if err != nil {
+			im.report.fail(t.Line, "%v", err)
+			return
+		}
+
+		// Files with several accounts have both sides of transfers between
+		// them, and the side imported first has already added this one.
+		if pair := im.findTransfer(other, t.Date, amount); pair != nil {
+			if !t.Uncleared {
+				if err := pair.SetCleared(true); err != nil {
+					im.report.fail(t.Line, "%v", err)
+					return
+				}
+			}
+			im.claimed[pair] = true
+			im.report.skip(t.Line, "transfer from %s already imported", other.Name())
+			return
+		}
+		rel = other
+
+	case sp.Category != "":
+		var err error
+		if c, err = im.categories.get(sp.Group, sp.Category); err != nil {
+			im.report.fail(t.Line, "%v", err)
+			return
+		}
+	}
+
+	if match := im.findMatch(t.Date, amount, description, rel); match != nil {
+		err := match.MatchImport(budgeting.Match{ImportID: importID, Date: t.Date, Description: description})
+		if err != nil {
+			im.report.fail(t.Line, "%v", err)
+			return
+		}
+		if im.options.approved {
+			if err := match.Approve(); err != nil {
+				im.report.fail(t.Line, "%v", err)
+				return
+			}
+		}
+		if importID != "" {
+			im.imported[importID] = true
+		}
+		im.claimed[match] = true
+		im.report.Matched = append(im.report.Matched, Match{Line: t.Line, Transaction: match})
+		return
+	}
+
+	var opts []budgeting.Option
+	if importID != "" {
+		opts = append(opts, budgeting.WithImportID(importID))
+	}
+	if !t.Uncleared {
+		opts = append(opts, budgeting.WithCleared())
+	}
+	if !im.options.approved {
+		opts = append(opts, budgeting.WithUnapproved())
+	}
+
+	added, err := im.account.AddTransaction(t.Date, amount, description, c, rel, opts...)
+	if err != nil {
+		im.report.fail(t.Line, "%v", err)
+		return
+	}
+	im.report.Imported = append(im.report.Imported, added)
+	if importID != "" {
+		im.imported[importID] = true
+	}
+}
+
+func (im *importRun) findAccount(name string) (*budgeting.Account, error) {
+	var found *budgeting.Account
+	for _, a := range im.budget.Accounts() {
+		if !strings.EqualFold(a.Name(), name) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("several accounts are named %q", name)
+		}
+		found = a
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("no account %q to transfer to", name)
+	}
+	return found, nil
+}
+
+// findTransfer returns an unclaimed transfer on the account from the other
+// account with the date and amount, if any.
+func (im *importRun) findTransfer(other *budgeting.Account, date time.Time, amount decimal.Decimal) *budgeting.Transaction {
+	for _, t := range im.account.Transactions() {
+		if t.TransferAccount() == other && t.Date().Equal(date) && t.Amount().Equal(amount) && !im.claimed[t] {
+			return t
+		}
+	}
+	return nil
+}
+
+// categoryIndex finds the categories of a budget by name and group,
+// creating those the budget doesn't have.
+type categoryIndex struct {
+	budget  *budgeting.Budget
+	byName  map[string]*budgeting.Category
+	byGroup map[string]*budgeting.Category
+
+	// created are the categories created, in order.
+	created []*budgeting.Category
+}
+
+func newCategoryIndex(b *budgeting.Budget) *categoryIndex {
+	ci := &categoryIndex{
+		budget:  b,
+		byName:  map[string]*budgeting.Category{},
+		byGroup: map[string]*budgeting.Category{},
+		created: []*budgeting.Category{},
+	}
+	for _, c := range b.Categories() {
+		ci.add(c)
+	}
+	return ci
+}
+
+func (ci *categoryIndex) add(c *budgeting.Category) {
+	if key := strings.ToLower(c.Name()); ci.byName[key] == nil {
+		ci.byName[key] = c
+	}
+	if key := strings.ToLower(c.Group() + "\x00" + c.Name()); ci.byGroup[key] == nil {
+		ci.byGroup[key] = c
+	}
+}
+
+// get returns the category with the name, in the group if one is given,
+// creating it if the budget doesn't have one.
+func (ci *categoryIndex) get(group, name string) (*budgeting.Category, error) {
+	c := ci.byName[strings.ToLower(name)]
+	if group != "" {
+		c = ci.byGroup[strings.ToLower(group+"\x00"+name)]
+	}
+	if c != nil {
+		return c, nil
+	}
+
+	c, err := ci.budget.AddCategory(name, budgeting.WithGroup(group))
+	if err != nil {
+		return nil, err
+	}
+	ci.add(c)
+	ci.created = append(ci.created, c)
+	return c, nil
+}
+
+func reconcile(a *budgeting.Account, bal Balance) *Reconciliation {
+	r := &Reconciliation{
+		Date:      bal.Date,
+		Statement: bal.Amount,
+	}
+
+	end := time.Date(bal.Date.Year(), bal.Date.Month(), bal.Date.Day()+1, 0, 0, 0, 0, time.UTC)
+	for _, t := range a.Transactions() {
+		if t.Date().Before(end) {
+			r.Account = r.Account.Add(t.Amount())
+		}
+	}
+
+	return r
+}
+
+// parseNumericDate parses dates written with numbers, such as 1/31/2018,
+// 01/31/18, 1/31'18 (Quicken writes an apostrophe before years after 1999)
+// and 2018-01-31. Unless the year comes first, dayFirst tells whether the
+// day comes before the month.
+func parseNumericDate(s string, dayFirst bool) (time.Time, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsDigit(r) })
+	if len(fields) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+
+	n := make([]int, 3)
+	for i, f := range fields {
+		n[i], _ = strconv.Atoi(f)
+	}
+
+	var year, month, day int
+	switch {
+	case len(fields[0]) == 4:
+		year, month, day = n[0], n[1], n[2]
+	case dayFirst:
+		day, month, year = n[0], n[1], n[2]
+	default:
+		month, day, year = n[0], n[1], n[2]
+	}
+
+	if len(fields[2]) <= 2 && len(fields[0]) != 4 {
+		switch {
+		case strings.Contains(s, "'"), year < 70:
+			year += 2000
+		default:
+			year += 1900
+		}
+	}
+
+	d := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if d.Month() != time.Month(month) || d.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,44 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func date(year, month, day int) time.Time {
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestImport(t *testing.T) {
+	assert := assert.New(t)
+
+	b := budgeting.NewBudget("My Budget")
+	a, _ := b.AddAccount("Checking", dec("100"), date(2018, 1, 1))
+
+	s := &Statement{
+		Transactions: []Transaction{
+			{Line: 2, Date: date(2018, 1, 5), Amount: dec("-42.10"), Description: "Weekly shop"},
+			{Line: 3, Date: date(2018, 1, 6), Amount: dec("-5"), Description: "Coffee"},
+		},
+		Skipped: []Problem{{Line: 4, Reason: "blank row"}},
+	}
+
+	r := Import(a, s)
+	assert.Equal("2 imported, 1 skipped, 0 failed", r.Summary())
+	assert.Equal("Weekly shop", r.Imported[0].Description())
+	assert.True(r.Imported[0].Cleared())
+	assert.True(dec("52.90").Equal(a.Balance()))
+
+	a.Close()
+	r = Import(a, s, NoMatching())
+	assert.Equal("0 imported, 1 skipped, 2 failed", r.Summary())
+	assert.Equal(Problem{Line: 3, Reason: "account is closed"}, r.Failed[1])
+}
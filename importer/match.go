@@ -0,0 +1,170 @@
+package importer
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+)
+
+// Default matching of imported transactions with those entered by hand.
+const (
+	DefaultMatchDays       = 3
+	DefaultMatchSimilarity = 0.5
+)
+
+// Option customizes how Import adds the transactions of a statement and
+// matches them with those already on the account.
+type Option func(*options)
+
+type options struct {
+	days       int
+	similarity float64
+	approved   bool
+}
+
+// Approved adds the transactions approved rather than for someone to
+// review, such as when moving a budget over from another app.
+func Approved() Option {
+	return func(o *options) {
+		o.approved = true
+	}
+}
+
+// MatchDays sets how many days apart the dates of an imported transaction
+// and of one entered by hand may be for them to match. Banks often date
+// transactions a few days after they were made.
+func MatchDays(days int) Option {
+	return func(m *options) {
+		m.days = days
+	}
+}
+
+// MatchSimilarity sets how similar the descriptions of an imported
+// transaction and of one entered by hand must be for them to match, from 0,
+// matching on amount and date alone, to 1.
+func MatchSimilarity(similarity float64) Option {
+	return func(m *options) {
+		m.similarity = similarity
+	}
+}
+
+// NoMatching turns matching off, adding every transaction without an
+// import ID already on the account.
+func NoMatching() Option {
+	return func(m *options) {
+		m.days = -1
+	}
+}
+
+func newOptions(opts []Option) options {
+	m := options{days: DefaultMatchDays, similarity: DefaultMatchSimilarity}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	return m
+}
+
+// Match is a transaction of a statement which was already on the account.
+type Match struct {
+	// Line is where the transaction is in the file.
+	Line int
+
+	// Transaction is the transaction on the account, which now has the
+	// import ID of the file's and is unapproved until the match is.
+	Transaction *budgeting.Transaction
+}
+
+// findMatch returns the transaction on the account which the imported one
+// is most likely to be, if any: one without an import ID, with the same
+// amount, dated at most the matching days apart and with a similar enough
+// description. A transfer only matches transfers with the same account.
+func (im *importRun) findMatch(date time.Time, amount decimal.Decimal, description string, rel *budgeting.Account) *budgeting.Transaction {
+	if im.options.days < 0 {
+		return nil
+	}
+
+	var best *budgeting.Transaction
+	var bestScore float64
+	var bestDays int
+	for _, t := range im.candidates {
+		if im.claimed[t] || !t.Amount().Equal(amount) {
+			continue
+		}
+		if rel != nil && t.TransferAccount() != rel {
+			continue
+		}
+
+		days := daysApart(t.Date(), date)
+		if days > im.options.days {
+			continue
+		}
+
+		score := similarity(t.Description(), description)
+		if score < im.options.similarity {
+			continue
+		}
+
+		if best == nil || score > bestScore || score == bestScore && days < bestDays {
+			best, bestScore, bestDays = t, score, days
+		}
+	}
+	return best
+}
+
+func daysApart(a, b time.Time) int {
+	d := int(a.Sub(b).Hours() / 24)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// similarity is the share of the letter pairs of the shorter description
+// which are in the other, ignoring case, spaces and punctuation. A payee
+// typed by hand is usually a part of the bank's description.
+func similarity(a, b string) float64 {
+	x, y := letterPairs(a), letterPairs(b)
+	nx, ny := 0, 0
+	for _, n := range x {
+		nx += n
+	}
+	for _, n := range y {
+		ny += n
+	}
+	if nx == 0 || ny == 0 {
+		return 0
+	}
+
+	common := 0
+	for pair, n := range x {
+		if m := y[pair]; m < n {
+			common += m
+		} else {
+			common += n
+		}
+	}
+
+	shorter := nx
+	if ny < nx {
+		shorter = ny
+	}
+	return float64(common) / float64(shorter)
+}
+
+func letterPairs(s string) map[string]int {
+	letters := []rune{}
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			letters = append(letters, r)
+		}
+	}
+
+	pairs := map[string]int{}
+	for i := 1; i < len(letters); i++ {
+		pairs[string(letters[i-1:i+1])]++
+	}
+	return pairs
+}
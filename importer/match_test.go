@@ -0,0 +1,66 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport_Matching(t *testing.T) {
+	assert := assert.New(t)
+
+	b := budgeting.NewBudget("My Budget")
+	a, _ := b.AddAccount("Checking", dec("100"), date(2018, 1, 1))
+	wallet, _ := b.AddAccount("Wallet", dec("0"), date(2018, 1, 1))
+	groceries, _ := b.AddCategory("Groceries")
+
+	shop, _ := a.AddTransaction(date(2018, 1, 4), dec("-42.10"), "Supermarket", groceries, nil)
+	cafe, _ := a.AddTransaction(date(2018, 1, 6), dec("-5"), "Cafe", nil, nil)
+	withdrawal, _ := a.AddTransaction(date(2018, 1, 6), dec("-20"), "Cash", nil, wallet)
+
+	s := &Statement{
+		Transactions: []Transaction{
+			{Line: 1, Date: date(2018, 1, 6), Amount: dec("-42.10"), Description: "SUPERMARKET 1234 LONDON", ImportID: "1"},
+			{Line: 2, Date: date(2018, 1, 6), Amount: dec("-5"), Description: "Bakery", ImportID: "2"},
+			{Line: 3, Date: date(2018, 1, 12), Amount: dec("-5"), Description: "Cafe", ImportID: "3"},
+			{Line: 4, Date: date(2018, 1, 7), Amount: dec("-20"), Description: "ATM Cash", ImportID: "4"},
+		},
+	}
+
+	r := Import(a, s)
+	assert.Equal("2 imported, 2 matched, 0 skipped, 0 failed", r.Summary())
+	assert.Equal([]Match{{Line: 1, Transaction: shop}, {Line: 4, Transaction: withdrawal}}, r.Matched)
+	assert.Equal("1", shop.ImportID())
+	assert.Equal("Groceries", shop.Category().Name())
+	assert.Equal("", cafe.ImportID())
+	assert.False(shop.Approved())
+	assert.Equal(date(2018, 1, 6), shop.Match().Date)
+	assert.False(r.Imported[0].Approved())
+
+	// The import IDs given to the matched transactions win the next time.
+	r = Import(a, s)
+	assert.Equal("0 imported, 4 skipped, 0 failed", r.Summary())
+
+	// A transaction only matches once.
+	a.AddTransaction(date(2018, 1, 20), dec("-9"), "Pharmacy", nil, nil)
+	r = Import(a, &Statement{Transactions: []Transaction{
+		{Line: 1, Date: date(2018, 1, 20), Amount: dec("-9"), Description: "Pharmacy"},
+		{Line: 2, Date: date(2018, 1, 20), Amount: dec("-9"), Description: "Pharmacy"},
+	}})
+	assert.Equal("1 imported, 1 matched, 0 skipped, 0 failed", r.Summary())
+
+	r = Import(a, &Statement{Transactions: []Transaction{
+		{Line: 1, Date: date(2018, 1, 9), Amount: dec("-5"), Description: "Coffee shop"},
+	}}, MatchDays(3), MatchSimilarity(0))
+	assert.Equal(cafe, r.Matched[0].Transaction)
+}
+
+func TestSimilarity(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(1.0, similarity("Supermarket", "SUPERMARKET 1234 LONDON"))
+	assert.Equal(1.0, similarity("cafe", "CAFE NERO"))
+	assert.Equal(0.0, similarity("", "Cafe"))
+	assert.True(similarity("Weekly shop", "Supermarket") < DefaultMatchSimilarity)
+}
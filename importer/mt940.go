@@ -0,0 +1,316 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReadMT940 reads SWIFT MT940 statements. There is a statement for every
+// account in the file: banks split the statements of an account by day or
+// by length, and they are joined, opening with the balance of the first and
+// closing with the balance of the last.
+//
+// Transactions are dated with their entry dates, and have their value dates
+// too. The bank's reference of a transaction becomes its import ID, or the
+// account owner's reference if the bank gave none. Descriptions are read
+// from the information to account owner that follows, including the
+// structured one of German banks.
+func ReadMT940(r io.Reader) ([]*Statement, error) {
+	fields, err := readMT940Fields(r)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []*Statement{}
+	byAccount := map[string]*Statement{}
+	var s *Statement
+	var last *Transaction
+	for _, f := range fields {
+		if f.tag != "25" && s == nil {
+			continue
+		}
+
+		switch f.tag {
+		case "25":
+			account := strings.TrimSpace(f.value)
+			if s = byAccount[account]; s == nil {
+				s = &Statement{Account: account}
+				byAccount[account] = s
+				statements = append(statements, s)
+			}
+			last = nil
+
+		case "60F", "60M":
+			// Statements in several parts open with an intermediate
+			// balance after the first.
+			if s.Opening != nil {
+				continue
+			}
+			bal, err := parseMT940Balance(f.value)
+			if err != nil {
+				s.fail(f.line, "%v", err)
+				continue
+			}
+			s.Opening = &bal
+
+		case "62F", "62M":
+			bal, err := parseMT940Balance(f.value)
+			if err != nil {
+				s.fail(f.line, "%v", err)
+				continue
+			}
+			s.Balance = &bal
+
+		case "61":
+			last = nil
+			t, err := parseMT940Line(f.value)
+			if err != nil {
+				s.fail(f.line, "%v", err)
+				continue
+			}
+			t.Line = f.line
+			s.Transactions = append(s.Transactions, t)
+			last = &s.Transactions[len(s.Transactions)-1]
+
+		case "86":
+			if last != nil {
+				if description := mt940Description(f.value); description != "" {
+					last.Description = description
+				}
+			}
+			last = nil
+		}
+	}
+
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("not an MT940 file")
+	}
+	return statements, nil
+}
+
+// mt940Field is a field of an MT940 message, like :61:, whose value may
+// span several lines.
+type mt940Field struct {
+	tag   string
+	value string
+	line  int
+}
+
+// readMT940Fields reads the fields of the messages of a file, without the
+// SWIFT headers and trailers some banks wrap them in.
+func readMT940Fields(r io.Reader) ([]mt940Field, error) {
+	fields := []mt940Field{}
+	var current *mt940Field
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		if strings.HasPrefix(text, "{") {
+			// The text block of a message starts after {4:, on the same
+			// line as the headers.
+			i := strings.Index(text, "{4:")
+			if i < 0 {
+				current = nil
+				continue
+			}
+			text = text[i+len("{4:"):]
+		}
+		if text == "-" || strings.HasPrefix(text, "-}") {
+			current = nil
+			continue
+		}
+
+		if strings.HasPrefix(text, ":") {
+			if end := strings.Index(text[1:], ":"); end > 0 {
+				fields = append(fields, mt940Field{tag: text[1 : end+1], value: text[end+2:], line: line})
+				current = &fields[len(fields)-1]
+				continue
+			}
+		}
+		if current != nil && text != "" {
+			current.value += "\n" + text
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// parseMT940Balance parses a balance like C180131EUR1457,90.
+func parseMT940Balance(s string) (Balance, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 11 || s[0] != 'C' && s[0] != 'D' {
+		return Balance{}, fmt.Errorf("invalid balance %q", s)
+	}
+
+	date, err := time.Parse("060102", s[1:7])
+	if err != nil {
+		return Balance{}, fmt.Errorf("invalid balance %q", s)
+	}
+	amount, err := parseMT940Amount(s[10:])
+	if err != nil {
+		return Balance{}, err
+	}
+	if s[0] == 'D' {
+		amount = amount.Neg()
+	}
+
+	return Balance{Date: date, Amount: amount}, nil
+}
+
+// parseMT940Line parses a statement line like
+// 1801050105D42,10NTRFNONREF//2018010501, made of the value date, the
+// entry date, whether it is a debit or a credit, the amount, the type of
+// transaction and the references of the account owner and of the bank.
+// Supplementary details may follow on the next line.
+func parseMT940Line(s string) (Transaction, error) {
+	lines := strings.SplitN(s, "\n", 2)
+	rest := lines[0]
+	invalid := fmt.Errorf("invalid statement line %q", rest)
+
+	if len(rest) < 6 {
+		return Transaction{}, invalid
+	}
+	value, err := time.Parse("060102", rest[:6])
+	if err != nil {
+		return Transaction{}, invalid
+	}
+	rest = rest[6:]
+
+	// The entry date has no year, so it is the value date's, unless they
+	// are on both sides of a new year.
+	entry := value
+	if len(rest) >= 4 && isDigits(rest[:4]) {
+		d, err := time.Parse("0102", rest[:4])
+		if err != nil {
+			return Transaction{}, invalid
+		}
+		year := value.Year()
+		switch {
+		case d.Month() == time.December && value.Month() == time.January:
+			year--
+		case d.Month() == time.January && value.Month() == time.December:
+			year++
+		}
+		entry = time.Date(year, d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+		rest = rest[4:]
+	}
+
+	// Reversals of credits take money from the account, and reversals of
+	// debits give it back.
+	negative := false
+	switch {
+	case strings.HasPrefix(rest, "RC"), strings.HasPrefix(rest, "D"):
+		negative = true
+	case strings.HasPrefix(rest, "RD"), strings.HasPrefix(rest, "C"):
+	default:
+		return Transaction{}, invalid
+	}
+	if rest[0] == 'R' {
+		rest = rest[2:]
+	} else {
+		rest = rest[1:]
+	}
+
+	// The third letter of the currency code may come before the amount.
+	if len(rest) > 0 && unicode.IsLetter(rune(rest[0])) {
+		rest = rest[1:]
+	}
+
+	end := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsDigit(r) && r != ',' })
+	if end < 0 {
+		end = len(rest)
+	}
+	amount, err := parseMT940Amount(rest[:end])
+	if err != nil {
+		return Transaction{}, err
+	}
+	if negative {
+		amount = amount.Neg()
+	}
+	rest = rest[end:]
+
+	// The type of transaction, like NTRF, comes before the references.
+	if len(rest) >= 4 {
+		rest = rest[4:]
+	}
+	owner, bank := rest, ""
+	if i := strings.Index(rest, "//"); i >= 0 {
+		owner, bank = rest[:i], rest[i+2:]
+	}
+
+	id := strings.TrimSpace(bank)
+	if owner = strings.TrimSpace(owner); id == "" && owner != "NONREF" {
+		id = owner
+	}
+
+	description := ""
+	if len(lines) > 1 {
+		description = strings.TrimSpace(lines[1])
+	}
+
+	return Transaction{
+		Date:        entry,
+		ValueDate:   value,
+		Amount:      amount,
+		Description: description,
+		ImportID:    id,
+	}, nil
+}
+
+// parseMT940Amount parses an amount, written with a decimal comma and no
+// sign.
+func parseMT940Amount(s string) (decimal.Decimal, error) {
+	d, err := decimal.NewFromString(strings.TrimSuffix(strings.Replace(s, ",", ".", 1), "."))
+	if err != nil || strings.HasPrefix(s, "-") {
+		return decimal.Decimal{}, fmt.Errorf("invalid amount %q", s)
+	}
+	return d, nil
+}
+
+// mt940Description reads the information to account owner. German banks
+// structure it in subfields like ?20 after a code of three digits: the
+// name of the other party is in ?32 and ?33, and what the payment is for
+// in ?20 to ?29. Other banks write free text.
+func mt940Description(s string) string {
+	if len(s) > 4 && isDigits(s[:3]) && s[3] == '?' {
+		subfields := map[string]string{}
+		for _, sub := range strings.Split(strings.Replace(s[4:], "\n", "", -1), "?") {
+			if len(sub) >= 2 {
+				subfields[sub[:2]] += sub[2:]
+			}
+		}
+
+		name := subfields["32"] + subfields["33"]
+		purpose := ""
+		for i := 20; i <= 29; i++ {
+			purpose += subfields[fmt.Sprint(i)]
+		}
+		if name == "" && purpose == "" {
+			purpose = subfields["00"]
+		}
+		return strings.Join(strings.Fields(name+" "+purpose), " ")
+	}
+
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
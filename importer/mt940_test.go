@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const mt940 = `{1:F01BANKDEFFXXXX0000000000}{2:O9401200180201BANKDEFFXXXX00000000001802011200N}{4:
+:20:STARTUMSE
+:25:10020030/1234567890
+:28C:1/1
+:60F:C171231EUR1000,00
+:61:1801050105D42,10NMSCNONREF//2018010501
+:86:106?00KARTENZAHLUNG?20Weekly?21 shop?32SUPERMARKET
+:61:1801020103CR1500,NTRFSALARY-JAN
+Employer transfer
+:61:18010801080D1,00NMSC
+:62M:C180105EUR2457,90
+-}
+{1:F01BANKDEFFXXXX0000000000}{2:O9401200180201BANKDEFFXXXX00000000001802011200N}{4:
+:20:STARTUMSE
+:25:10020030/1234567890
+:28C:1/2
+:60M:C180105EUR2457,90
+:61:1712311231RC9,99NMSC//2018010901
+:86:Refund reversed
+ by the shop
+:62F:C180131EUR2447,91
+-}
+{5:{CHK:123456789ABC}}
+`
+
+func TestReadMT940(t *testing.T) {
+	assert := assert.New(t)
+
+	statements, err := ReadMT940(strings.NewReader(mt940))
+	assert.Nil(err)
+	assert.Len(statements, 1)
+
+	s := statements[0]
+	assert.Equal("10020030/1234567890", s.Account)
+	assert.Len(s.Transactions, 3)
+	assert.Equal(Transaction{
+		Line:        6,
+		Date:        date(2018, 1, 5),
+		ValueDate:   date(2018, 1, 5),
+		Amount:      dec("-42.10"),
+		Description: "SUPERMARKET Weekly shop",
+		ImportID:    "2018010501",
+	}, s.Transactions[0])
+
+	salary := s.Transactions[1]
+	assert.Equal(date(2018, 1, 3), salary.Date)
+	assert.Equal(date(2018, 1, 2), salary.ValueDate)
+	assert.True(dec("1500").Equal(salary.Amount))
+	assert.Equal("Employer transfer", salary.Description)
+	assert.Equal("SALARY-JAN", salary.ImportID)
+
+	reversal := s.Transactions[2]
+	assert.Equal(date(2017, 12, 31), reversal.Date)
+	assert.True(dec("-9.99").Equal(reversal.Amount))
+	assert.Equal("Refund reversed by the shop", reversal.Description)
+
+	assert.Equal([]Problem{{Line: 10, Reason: `invalid statement line "18010801080D1,00NMSC"`}}, s.Failed)
+	assert.Equal(date(2017, 12, 31), s.Opening.Date)
+	assert.True(dec("1000").Equal(s.Opening.Amount))
+	assert.Equal(date(2018, 1, 31), s.Balance.Date)
+	assert.True(dec("2447.91").Equal(s.Balance.Amount))
+
+	_, err = ReadMT940(strings.NewReader(ofxSGML))
+	assert.EqualError(err, "not an MT940 file")
+}
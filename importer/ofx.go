@@ -0,0 +1,274 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReadOFX reads an OFX or QFX file, in either the SGML format of OFX 1.x or
+// the XML format of OFX 2.x. There is a statement for every bank and credit
+// card account in the file, with the ledger balance if the bank reported it.
+// The FITID of every transaction becomes its import ID.
+func ReadOFX(r io.Reader) ([]*Statement, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	root := parseOFX(data)
+	ofx := root.child("OFX")
+	if ofx == nil {
+		return nil, fmt.Errorf("not an OFX file")
+	}
+
+	statements := []*Statement{}
+	for _, stmt := range append(ofx.find("STMTRS"), ofx.find("CCSTMTRS")...) {
+		statements = append(statements, readOFXStatement(stmt))
+	}
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("no bank or credit card statement in the OFX file")
+	}
+
+	return statements, nil
+}
+
+func readOFXStatement(stmt *ofxElement) *Statement {
+	s := &Statement{}
+
+	for _, from := range []string{"BANKACCTFROM", "CCACCTFROM"} {
+		if acct := stmt.child(from); acct != nil {
+			s.Account = acct.value("ACCTID")
+		}
+	}
+
+	for _, trn := range stmt.find("STMTTRN") {
+		t, err := readOFXTransaction(trn)
+		if err != nil {
+			s.fail(trn.line, "%v", err)
+			continue
+		}
+		s.Transactions = append(s.Transactions, t)
+	}
+
+	if bal := stmt.child("LEDGERBAL"); bal != nil {
+		amount, amountErr := parseOFXAmount(bal.value("BALAMT"))
+		date, dateErr := parseOFXDate(bal.value("DTASOF"))
+		if amountErr != nil || dateErr != nil {
+			s.fail(bal.line, "invalid ledger balance")
+		} else {
+			s.Balance = &Balance{Date: date, Amount: amount}
+		}
+	}
+
+	return s
+}
+
+func readOFXTransaction(trn *ofxElement) (Transaction, error) {
+	posted := trn.value("DTPOSTED")
+	if posted == "" {
+		posted = trn.value("DTUSER")
+	}
+	date, err := parseOFXDate(posted)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	amount, err := parseOFXAmount(trn.value("TRNAMT"))
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	// Banks give the payee either as NAME or in a PAYEE aggregate, and put
+	// the rest of the details in MEMO.
+	name := trn.value("NAME")
+	if payee := trn.child("PAYEE"); name == "" && payee != nil {
+		name = payee.value("NAME")
+	}
+	parts := []string{}
+	if name != "" {
+		parts = append(parts, name)
+	}
+	if memo := trn.value("MEMO"); memo != "" && !strings.Contains(name, memo) {
+		parts = append(parts, memo)
+	}
+
+	return Transaction{
+		Line:        trn.line,
+		Date:        date,
+		Amount:      amount,
+		Description: strings.Join(parts, " "),
+		ImportID:    trn.value("FITID"),
+	}, nil
+}
+
+// parseOFXDate parses an OFX datetime like 20180105120000.000[-5:EST],
+// keeping only the date.
+func parseOFXDate(s string) (time.Time, error) {
+	if len(s) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+
+	d, err := time.Parse("20060102", s[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return d, nil
+}
+
+// parseOFXAmount parses an OFX amount, which some banks write with a
+// decimal comma.
+func parseOFXAmount(s string) (decimal.Decimal, error) {
+	d, err := decimal.NewFromString(strings.Replace(strings.TrimPrefix(s, "+"), ",", ".", 1))
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("invalid amount %q", s)
+	}
+	return d, nil
+}
+
+// ofxElement is an element of an OFX file. Elements have either children
+// or text.
+type ofxElement struct {
+	name     string
+	line     int
+	text     string
+	children []*ofxElement
+}
+
+// child returns the first child with the name, or nil.
+func (e *ofxElement) child(name string) *ofxElement {
+	for _, c := range e.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// value returns the text of the first child with the name.
+func (e *ofxElement) value(name string) string {
+	if c := e.child(name); c != nil {
+		return c.text
+	}
+	return ""
+}
+
+// find returns the descendants with the name, in the order they appear.
+func (e *ofxElement) find(name string) []*ofxElement {
+	found := []*ofxElement{}
+	for _, c := range e.children {
+		if c.name == name {
+			found = append(found, c)
+			continue
+		}
+		found = append(found, c.find(name)...)
+	}
+	return found
+}
+
+var ofxEntities = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ", "&amp;", "&")
+
+// parseOFX parses both the SGML of OFX 1.x and the XML of OFX 2.x into a
+// tree under a root element. In SGML, elements with text usually have no
+// end tag, so an element with text ends at the next tag, and an element
+// still open when its parent's end tag comes is a leaf, even if it is
+// empty. Headers, processing instructions and comments are ignored, as
+// are end tags which don't match an open element, so that any file yields
+// a tree.
+func parseOFX(data []byte) *ofxElement {
+	// OFX 1.x files are usually in Windows-1252 rather than UTF-8, which
+	// is close enough to Latin-1 for payee names.
+	if !utf8.Valid(data) {
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		data = []byte(string(runes))
+	}
+
+	root := &ofxElement{}
+	stack := []*ofxElement{root}
+	top := func() *ofxElement { return stack[len(stack)-1] }
+
+	line := 1
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '<')
+		if i < 0 {
+			i = len(data)
+		}
+		if text := strings.TrimSpace(string(data[:i])); text != "" && len(stack) > 1 {
+			top().text += ofxEntities.Replace(text)
+		}
+		line += bytes.Count(data[:i], []byte("\n"))
+		data = data[i:]
+		if len(data) == 0 {
+			break
+		}
+
+		end := []byte(">")
+		switch {
+		case bytes.HasPrefix(data, []byte("<!--")):
+			end = []byte("-->")
+		case bytes.HasPrefix(data, []byte("<?")):
+			end = []byte("?>")
+		}
+		j := bytes.Index(data, end)
+		if j < 0 {
+			break
+		}
+		tag := string(data[1:j])
+		tagLine := line
+		line += bytes.Count(data[:j], []byte("\n"))
+		data = data[j+len(end):]
+
+		if tag == "" || tag[0] == '!' || tag[0] == '?' {
+			continue
+		}
+
+		if tag[0] == '/' {
+			name := strings.ToUpper(strings.TrimSpace(tag[1:]))
+			for k := len(stack) - 1; k > 0; k-- {
+				if stack[k].name == name {
+					closeLeaves(stack[k:])
+					stack = stack[:k]
+					break
+				}
+			}
+			continue
+		}
+
+		selfClosing := strings.HasSuffix(tag, "/")
+		fields := strings.Fields(strings.TrimSuffix(tag, "/"))
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(stack) > 1 && top().text != "" {
+			stack = stack[:len(stack)-1]
+		}
+		e := &ofxElement{name: strings.ToUpper(fields[0]), line: tagLine}
+		top().children = append(top().children, e)
+		if !selfClosing {
+			stack = append(stack, e)
+		}
+	}
+
+	return root
+}
+
+// closeLeaves turns the elements opened after stack[0] into leaves as
+// stack[0] ends, since they had no end tag of their own. What they seemed
+// to contain were their siblings.
+func closeLeaves(stack []*ofxElement) {
+	for k := len(stack) - 1; k > 0; k-- {
+		parent, leaf := stack[k-1], stack[k]
+		parent.children = append(parent.children, leaf.children...)
+		leaf.children = nil
+	}
+}
@@ -0,0 +1,178 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/stretchr/testify/assert"
+)
+
+const ofxSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+ENCODING:USASCII
+CHARSET:1252
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS><DTSERVER>20180131</SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1>
+<STMTTRNRS>
+<STMTRS>
+<CURDEF>USD
+<BANKACCTFROM><BANKID>123<ACCTID>0001234<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20180101<DTEND>20180131
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20180105120000.000[-5:EST]
+<TRNAMT>-42.10
+<FITID>2018010501
+<NAME>SUPERMARKET
+<MEMO>Weekly shop
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20180106
+<TRNAMT>1500,00
+<FITID>2018010601
+<NAME>Salary &amp; bonus
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>2018
+<TRNAMT>-1
+<FITID>2018010701
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>1457.90<DTASOF>20180131</LEDGERBAL>
+</STMTRS>
+</STMTTRNRS>
+</BANKMSGSRSV1>
+</OFX>
+`
+
+const ofxXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+  <CREDITCARDMSGSRSV1>
+    <CCSTMTTRNRS>
+      <CCSTMTRS>
+        <CCACCTFROM><ACCTID>4111</ACCTID></CCACCTFROM>
+        <BANKTRANLIST>
+          <STMTTRN>
+            <TRNTYPE>DEBIT</TRNTYPE>
+            <DTPOSTED>20180110</DTPOSTED>
+            <TRNAMT>-9.99</TRNAMT>
+            <FITID>cc-1</FITID>
+            <PAYEE><NAME>Café</NAME></PAYEE>
+            <MEMO>Café</MEMO>
+          </STMTTRN>
+        </BANKTRANLIST>
+      </CCSTMTRS>
+    </CCSTMTTRNRS>
+  </CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func TestReadOFX_SGML(t *testing.T) {
+	assert := assert.New(t)
+
+	statements, err := ReadOFX(strings.NewReader(ofxSGML))
+	assert.Nil(err)
+	assert.Len(statements, 1)
+
+	s := statements[0]
+	assert.Equal("0001234", s.Account)
+	assert.Len(s.Transactions, 2)
+	assert.Equal(Transaction{Line: 16, Date: date(2018, 1, 5), Amount: dec("-42.10"), Description: "SUPERMARKET Weekly shop", ImportID: "2018010501"}, s.Transactions[0])
+	assert.True(dec("1500").Equal(s.Transactions[1].Amount))
+	assert.Equal("Salary & bonus", s.Transactions[1].Description)
+	assert.Equal([]Problem{{Line: 31, Reason: `invalid date "2018"`}}, s.Failed)
+	assert.Equal(date(2018, 1, 31), s.Balance.Date)
+	assert.True(dec("1457.90").Equal(s.Balance.Amount))
+}
+
+func TestReadOFX_XML(t *testing.T) {
+	assert := assert.New(t)
+
+	statements, err := ReadOFX(strings.NewReader(ofxXML))
+	assert.Nil(err)
+	assert.Len(statements, 1)
+
+	s := statements[0]
+	assert.Equal("4111", s.Account)
+	assert.Equal([]Transaction{{Line: 9, Date: date(2018, 1, 10), Amount: dec("-9.99"), Description: "Café", ImportID: "cc-1"}}, s.Transactions)
+	assert.Nil(s.Balance)
+
+	_, err = ReadOFX(strings.NewReader("Date,Amount\n"))
+	assert.EqualError(err, "not an OFX file")
+}
+
+func TestReadOFX_Latin1(t *testing.T) {
+	assert := assert.New(t)
+
+	in := "<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST><STMTTRN><DTPOSTED>20180105<TRNAMT>-1<NAME>Caf\xe9</STMTTRN>"
+	statements, err := ReadOFX(strings.NewReader(in))
+	assert.Nil(err)
+	assert.Equal("Café", statements[0].Transactions[0].Description)
+}
+
+func TestReadOFX_EmptyElements(t *testing.T) {
+	assert := assert.New(t)
+
+	in := `<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKACCTFROM><ACCTID>0001234</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN>
+<DTPOSTED>20180105
+<TRNAMT>-1
+<MEMO>
+<FITID>abc
+<NAME>Shop
+</STMTTRN>
+<STMTTRN>
+<DTPOSTED>20180106
+<TRNAMT>-2
+<NAME>
+<FITID>def
+<MEMO>Refund
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>-3<DTASOF>20180131</LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>
+`
+	statements, err := ReadOFX(strings.NewReader(in))
+	assert.Nil(err)
+	assert.Len(statements, 1)
+
+	s := statements[0]
+	assert.Equal("0001234", s.Account)
+	assert.Equal([]Transaction{
+		{Line: 4, Date: date(2018, 1, 5), Amount: dec("-1"), Description: "Shop", ImportID: "abc"},
+		{Line: 11, Date: date(2018, 1, 6), Amount: dec("-2"), Description: "Refund", ImportID: "def"},
+	}, s.Transactions)
+	assert.True(dec("-3").Equal(s.Balance.Amount))
+}
+
+func TestImport_Idempotent(t *testing.T) {
+	assert := assert.New(t)
+
+	b := budgeting.NewBudget("My Budget")
+	a, _ := b.AddAccount("Checking", dec("0"), date(2018, 1, 1))
+
+	statements, _ := ReadOFX(strings.NewReader(ofxSGML))
+	r := Import(a, statements[0])
+	assert.Equal("2 imported, 0 skipped, 1 failed", r.Summary())
+	assert.Equal("2018010501", r.Imported[0].ImportID())
+	assert.Equal(date(2018, 1, 31), r.Reconciliation.Date)
+	assert.True(dec("1457.90").Equal(r.Reconciliation.Account))
+	assert.True(r.Reconciliation.Balanced())
+
+	a.AddTransaction(date(2018, 2, 1), dec("-10"), "Next month", nil, nil)
+	r = Import(a, statements[0])
+	assert.Equal("0 imported, 2 skipped, 1 failed", r.Summary())
+	assert.Equal(Problem{Line: 16, Reason: "already imported 2018010501"}, r.Skipped[0])
+	assert.True(r.Reconciliation.Balanced())
+	assert.Len(a.Transactions(), 4)
+}
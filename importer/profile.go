@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Profiles are CSV profiles saved by name, such as one per bank.
+type Profiles map[string]Profile
+
+// LoadProfiles loads the profiles saved in the file at path. A missing
+// file has no profiles.
+func LoadProfiles(path string) (Profiles, error) {
+	ps := Profiles{}
+
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ps, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, &ps); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+// Save replaces the file at path with the profiles.
+func (ps Profiles) Save(path string) error {
+	raw, err := json.MarshalIndent(ps, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Names returns the names of the profiles in order.
+func (ps Profiles) Names() []string {
+	names := []string{}
+	for name := range ps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
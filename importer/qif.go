@@ -0,0 +1,187 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// qifTypes are the QIF sections of transactions on bank, cash and credit
+// card accounts, and other assets and liabilities.
+var qifTypes = map[string]bool{
+	"bank":  true,
+	"cash":  true,
+	"ccard": true,
+	"oth a": true,
+	"oth l": true,
+}
+
+// ReadQIF reads a QIF file, with a statement for every section of
+// transactions in it. Sections of other kinds, such as investments and
+// lists of categories, are ignored.
+//
+// Statements are of the account named by the !Account block before them,
+// if any. Categories are written as "L" lines and transfers as "L[Account]",
+// and the "S", "E" and "$" lines of split transactions become splits.
+//
+// QIF dates are in the order of the exporting app's language, so dayFirst
+// tells whether they are like 31/01/2018 rather than 01/31/2018.
+func ReadQIF(r io.Reader, dayFirst bool) ([]*Statement, error) {
+	statements := []*Statement{}
+	var current *Statement
+	account := ""
+	inAccount := false
+
+	var record []qifField
+	recordLine := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if text[0] == '!' {
+			header := strings.ToLower(strings.TrimSpace(text[1:]))
+			record = nil
+			inAccount = header == "account"
+			current = nil
+
+			if strings.HasPrefix(header, "type:") && qifTypes[strings.TrimSpace(header[len("type:"):])] {
+				current = &Statement{Account: account}
+				statements = append(statements, current)
+			}
+			continue
+		}
+
+		if record == nil {
+			recordLine = line
+		}
+		if text[0] != '^' {
+			record = append(record, qifField{code: text[0], value: strings.TrimSpace(text[1:])})
+			continue
+		}
+
+		switch {
+		case inAccount:
+			for _, f := range record {
+				if f.code == 'N' {
+					account = f.value
+				}
+			}
+		case current != nil:
+			t, err := readQIFTransaction(record, dayFirst)
+			if err != nil {
+				current.fail(recordLine, "%v", err)
+				break
+			}
+			t.Line = recordLine
+			current.Transactions = append(current.Transactions, t)
+		}
+		record = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("no bank, cash or credit card transactions in the QIF file")
+	}
+	return statements, nil
+}
+
+type qifField struct {
+	code  byte
+	value string
+}
+
+func readQIFTransaction(record []qifField, dayFirst bool) (Transaction, error) {
+	t := Transaction{}
+	var date, amount, payee, memo string
+	var split *Split
+
+	for _, f := range record {
+		switch f.code {
+		case 'D':
+			date = f.value
+		case 'T', 'U':
+			if amount == "" {
+				amount = f.value
+			}
+		case 'P':
+			payee = f.value
+		case 'M':
+			memo = f.value
+		case 'L':
+			t.Category, t.Transfer = qifCategory(f.value)
+		case 'S':
+			t.Splits = append(t.Splits, Split{})
+			split = &t.Splits[len(t.Splits)-1]
+			split.Category, split.Transfer = qifCategory(f.value)
+		case 'E':
+			if split != nil {
+				split.Memo = f.value
+			}
+		case '$':
+			if split == nil {
+				return Transaction{}, fmt.Errorf("split amount without a split category")
+			}
+			a, err := parseQIFAmount(f.value)
+			if err != nil {
+				return Transaction{}, err
+			}
+			split.Amount = a
+		}
+	}
+
+	if date == "" || amount == "" {
+		return Transaction{}, fmt.Errorf("no date or amount")
+	}
+
+	var err error
+	if t.Date, err = parseNumericDate(date, dayFirst); err != nil {
+		return Transaction{}, err
+	}
+	if t.Amount, err = parseQIFAmount(amount); err != nil {
+		return Transaction{}, err
+	}
+
+	t.Description = payee
+	if memo != "" && !strings.Contains(payee, memo) {
+		t.Description = strings.TrimSpace(payee + " " + memo)
+	}
+	return t, nil
+}
+
+// qifCategory reads an "L" or "S" line, which is a category or an account
+// in brackets, followed by a class after a slash.
+func qifCategory(s string) (category, transfer string) {
+	if strings.HasPrefix(s, "[") {
+		if end := strings.Index(s, "]"); end > 0 {
+			return "", strings.TrimSpace(s[1:end])
+		}
+	}
+
+	if i := strings.Index(s, "/"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s), ""
+}
+
+// parseQIFAmount parses a QIF amount, which may have commas between
+// thousands.
+func parseQIFAmount(s string) (decimal.Decimal, error) {
+	d, err := decimal.NewFromString(strings.Replace(s, ",", "", -1))
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("invalid amount %q", s)
+	}
+	return d, nil
+}
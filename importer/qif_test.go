@@ -0,0 +1,153 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/stretchr/testify/assert"
+)
+
+const qifFile = `!Option:AutoSwitch
+!Account
+NChecking
+TBank
+^
+NWallet
+TCash
+^
+!Clear:AutoSwitch
+!Account
+NChecking
+TBank
+^
+!Type:Bank
+D1/1'18
+T1,000.00
+POpening Balance
+L[Checking]
+^
+D01/05/2018
+T-100.00
+PSupermarket
+MWeekly shop
+SFood:Groceries
+$-60.00
+EFruit
+SHousehold/Home
+$-40.00
+^
+D1/6/18
+T-50.00
+PCash machine
+L[Wallet]
+^
+D13/13/2018
+T-1.00
+^
+!Account
+NWallet
+TCash
+^
+!Type:Cash
+D1/6/18
+T50.00
+PCash machine
+L[Checking]
+^
+D1/7/18
+T-4.50
+PCafe
+LEating Out
+^
+!Type:Invst
+D1/8/18
+NBuy
+^
+`
+
+func TestReadQIF(t *testing.T) {
+	assert := assert.New(t)
+
+	statements, err := ReadQIF(strings.NewReader(qifFile), false)
+	assert.Nil(err)
+	assert.Len(statements, 2)
+
+	checking := statements[0]
+	assert.Equal("Checking", checking.Account)
+	assert.Len(checking.Transactions, 3)
+	assert.Equal("Checking", checking.Transactions[0].Transfer)
+	assert.True(dec("1000").Equal(checking.Transactions[0].Amount))
+	assert.Equal(date(2018, 1, 1), checking.Transactions[0].Date)
+
+	split := checking.Transactions[1]
+	assert.Equal(20, split.Line)
+	assert.Equal("Supermarket Weekly shop", split.Description)
+	assert.Len(split.Splits, 2)
+	assert.Equal("Food:Groceries", split.Splits[0].Category)
+	assert.Equal("Fruit", split.Splits[0].Memo)
+	assert.Equal("Household", split.Splits[1].Category)
+	assert.True(dec("-40").Equal(split.Splits[1].Amount))
+
+	assert.Equal("Wallet", checking.Transactions[2].Transfer)
+	assert.Equal([]Problem{{Line: 35, Reason: `invalid date "13/13/2018"`}}, checking.Failed)
+
+	wallet := statements[1]
+	assert.Equal("Wallet", wallet.Account)
+	assert.Len(wallet.Transactions, 2)
+	assert.Equal("Eating Out", wallet.Transactions[1].Category)
+}
+
+func TestReadQIF_Dates(t *testing.T) {
+	assert := assert.New(t)
+
+	for s, want := range map[string]string{
+		"1/31/2018":  "2018-01-31",
+		"01/31/18":   "2018-01-31",
+		"1/31'05":    "2005-01-31",
+		"12/31/98":   "1998-12-31",
+		"2018-01-31": "2018-01-31",
+	} {
+		d, err := parseNumericDate(s, false)
+		assert.Nil(err, s)
+		assert.Equal(want, d.Format("2006-01-02"), s)
+	}
+
+	d, err := parseNumericDate("31.01.2018", true)
+	assert.Nil(err)
+	assert.Equal(date(2018, 1, 31), d)
+
+	_, err = parseNumericDate("31/01/2018", false)
+	assert.EqualError(err, `invalid date "31/01/2018"`)
+}
+
+func TestImport_QIF(t *testing.T) {
+	assert := assert.New(t)
+
+	b := budgeting.NewBudget("My Budget")
+	checking, _ := b.AddAccount("Checking", dec("0"), date(2018, 1, 1))
+	wallet, _ := b.AddAccount("Wallet", dec("0"), date(2018, 1, 1))
+	b.AddCategory("eating out")
+
+	statements, _ := ReadQIF(strings.NewReader(qifFile), false)
+
+	r := Import(checking, statements[0])
+	assert.Equal("4 imported, 0 skipped, 1 failed", r.Summary())
+	assert.True(r.Imported[0].Category().Equal(b.TBBCategory()))
+	assert.Equal("Supermarket Weekly shop Fruit", r.Imported[1].Description())
+	assert.Equal("Food:Groceries", r.Imported[1].Category().Name())
+	assert.True(r.Imported[3].TransferAccount() == wallet)
+	assert.Len(r.Categories, 2)
+
+	r = Import(wallet, statements[1])
+	assert.Equal("1 imported, 1 skipped, 0 failed", r.Summary())
+	assert.Equal(Problem{Line: 43, Reason: "transfer from Checking already imported"}, r.Skipped[0])
+	assert.Equal("eating out", r.Imported[0].Category().Name())
+	assert.Len(r.Categories, 0)
+
+	assert.True(dec("850").Equal(checking.Balance()))
+	assert.True(dec("45.50").Equal(wallet.Balance()))
+
+	r = Import(checking, &Statement{Transactions: []Transaction{{Line: 1, Date: date(2018, 1, 9), Amount: dec("-1"), Transfer: "Savings"}}})
+	assert.Equal([]Problem{{Line: 1, Reason: `no account "Savings" to transfer to`}}, r.Failed)
+}
@@ -0,0 +1,521 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+)
+
+// YNAB's exports come in two layouts: YNAB 4 calls category groups master
+// categories and writes "Master:Sub" in its Category column, while the
+// current YNAB calls them category groups and writes only the category in
+// its Category column.
+var (
+	ynab4Layout = ynabLayout{group: "master category", category: "sub category"}
+	nynabLayout = ynabLayout{group: "category group", category: "category"}
+)
+
+// ynabLayout names the columns of a category's group and name.
+type ynabLayout struct {
+	group, category string
+}
+
+// ynabHeader maps the lowercase column names of a YNAB export to field
+// indexes, and tells which layout it has.
+func ynabHeader(record []string, required ...string) (map[string]int, ynabLayout, error) {
+	header := map[string]int{}
+	for i, name := range record {
+		// YNAB 4 starts its exports with a byte order mark, which keeps
+		// the quotes of the first column.
+		if i == 0 && strings.HasPrefix(name, "\ufeff") {
+			name = strings.Trim(strings.TrimPrefix(name, "\ufeff"), `"`)
+		}
+		header[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	cols := nynabLayout
+	if _, ok := header[ynab4Layout.category]; ok {
+		cols = ynab4Layout
+	}
+
+	for _, name := range append(required, cols.group, cols.category) {
+		if _, ok := header[name]; !ok {
+			return nil, cols, fmt.Errorf("no %q column, is this a YNAB export?", name)
+		}
+	}
+	return header, cols, nil
+}
+
+// ynabCategory returns the group and name of a category. YNAB 4 exports
+// hidden categories in a "Hidden Categories" group, with names like
+// "Group ` Category ` 42".
+func ynabCategory(group, name string) (string, string) {
+	if strings.EqualFold(group, "Hidden Categories") {
+		if parts := strings.Split(name, "`"); len(parts) >= 2 {
+			return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		}
+	}
+	return group, name
+}
+
+// ynabIncome tells whether a category is YNAB's income: "Income" with
+// "Available this month" and "Available next month" in YNAB 4, and
+// "Inflow" with "Ready to Assign" (or "To be Budgeted") in the current YNAB.
+func ynabIncome(group string) bool {
+	return strings.EqualFold(group, "Income") || strings.EqualFold(group, "Inflow")
+}
+
+// ReadYNABRegister reads the Register export of YNAB 4 or the current YNAB,
+// with a statement for every account in it. Income becomes income to be
+// budgeted, and payees like "Transfer : Wallet" become transfers.
+//
+// Dates are in the order of the exporting app's language, so dayFirst tells
+// whether they are like 31/01/2018 rather than 01/31/2018.
+func ReadYNABRegister(r io.Reader, dayFirst bool) ([]*Statement, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	record, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %v", err)
+	}
+	header, cols, err := ynabHeader(record, "account", "date", "payee", "memo", "outflow", "inflow")
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []*Statement{}
+	byAccount := map[string]*Statement{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if pe, ok := err.(*csv.ParseError); ok {
+			return nil, fmt.Errorf("line %d: %v", pe.StartLine, pe.Err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		field := func(name string) string {
+			if i := header[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		account := field("account")
+		if account == "" {
+			return nil, fmt.Errorf("line %d: no account", line)
+		}
+		s, ok := byAccount[account]
+		if !ok {
+			s = &Statement{Account: account}
+			byAccount[account] = s
+			statements = append(statements, s)
+		}
+
+		t, err := readYNABTransaction(field, cols, dayFirst)
+		if err != nil {
+			s.fail(line, "%v", err)
+			continue
+		}
+		t.Line = line
+		s.Transactions = append(s.Transactions, t)
+	}
+
+	return statements, nil
+}
+
+func readYNABTransaction(field func(string) string, cols ynabLayout, dayFirst bool) (Transaction, error) {
+	date, err := parseNumericDate(field("date"), dayFirst)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	inflow, err := parseYNABAmount(field("inflow"))
+	if err != nil {
+		return Transaction{}, err
+	}
+	outflow, err := parseYNABAmount(field("outflow"))
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	t := Transaction{
+		Date:   date,
+		Amount: inflow.Sub(outflow),
+	}
+
+	switch strings.ToLower(field("cleared")) {
+	case "u", "uncleared":
+		t.Uncleared = true
+	}
+
+	payee := field("payee")
+	if strings.HasPrefix(payee, "Transfer : ") {
+		// Transfers to tracking accounts have a category in YNAB, which
+		// transfers can't have here.
+		t.Transfer = strings.TrimSpace(strings.TrimPrefix(payee, "Transfer : "))
+		payee = ""
+	} else {
+		group, category := ynabCategory(field(cols.group), field(cols.category))
+		switch {
+		case ynabIncome(group):
+			t.Income = true
+		case category != "":
+			t.Group, t.Category = group, category
+		}
+	}
+
+	parts := []string{}
+	for _, p := range []string{payee, field("memo")} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	t.Description = strings.Join(parts, " ")
+
+	return t, nil
+}
+
+// parseYNABAmount parses an amount of a YNAB export, written in the
+// currency format of the budget like $1,234.56 or 1.234,56 €.
+func parseYNABAmount(s string) (decimal.Decimal, error) {
+	orig := s
+	s = strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' || r == '.' || r == ',' || r == '-' {
+			return r
+		}
+		return -1
+	}, s)
+	if s == "" {
+		return decimal.Decimal{}, nil
+	}
+
+	// The last separator is the decimal separator if at most two digits
+	// follow it. Any other separator is between thousands.
+	if i := strings.LastIndexAny(s, ".,"); i >= 0 && len(s)-i-1 <= 2 {
+		s = strings.NewReplacer(".", "", ",", "").Replace(s[:i]) + "." + s[i+1:]
+	} else {
+		s = strings.NewReplacer(".", "", ",", "").Replace(s)
+	}
+
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("invalid amount %q", orig)
+	}
+	return d, nil
+}
+
+// BudgetLine is what was budgeted for a category in a month, read from a
+// budget export.
+type BudgetLine struct {
+	Line     int
+	Month    budgeting.YearMonth
+	Group    string
+	Category string
+	Budgeted decimal.Decimal
+
+	// Available is what the exporting app says is available in the
+	// category at the end of the month.
+	Available decimal.Decimal
+}
+
+// BudgetFile is what was read from a budget export.
+type BudgetFile struct {
+	Lines   []BudgetLine
+	Skipped []Problem
+	Failed  []Problem
+}
+
+func (f *BudgetFile) skip(line int, format string, args ...interface{}) {
+	f.Skipped = append(f.Skipped, Problem{Line: line, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (f *BudgetFile) fail(line int, format string, args ...interface{}) {
+	f.Failed = append(f.Failed, Problem{Line: line, Reason: fmt.Sprintf(format, args...)})
+}
+
+// ReadYNABBudget reads the Budget export of YNAB 4 or the current YNAB,
+// with a line for every category in every month.
+func ReadYNABBudget(r io.Reader) (*BudgetFile, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	record, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %v", err)
+	}
+	header, cols, err := ynabHeader(record, "month")
+	if err != nil {
+		return nil, err
+	}
+
+	budgetedColumn, availableColumn := "budgeted", "category balance"
+	if cols == nynabLayout {
+		availableColumn = "available"
+		if _, ok := header["assigned"]; ok {
+			budgetedColumn = "assigned"
+		}
+	}
+	for _, name := range []string{budgetedColumn, availableColumn} {
+		if _, ok := header[name]; !ok {
+			return nil, fmt.Errorf("no %q column, is this a YNAB export?", name)
+		}
+	}
+
+	f := &BudgetFile{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if pe, ok := err.(*csv.ParseError); ok {
+			return nil, fmt.Errorf("line %d: %v", pe.StartLine, pe.Err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		field := func(name string) string {
+			if i := header[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		group, category := ynabCategory(field(cols.group), field(cols.category))
+		if ynabIncome(group) || category == "" {
+			f.skip(line, "not a budget category")
+			continue
+		}
+
+		month, err := parseYNABMonth(field("month"))
+		if err != nil {
+			f.fail(line, "%v", err)
+			continue
+		}
+		budgeted, err := parseYNABAmount(field(budgetedColumn))
+		if err != nil {
+			f.fail(line, "%v", err)
+			continue
+		}
+		available, err := parseYNABAmount(field(availableColumn))
+		if err != nil {
+			f.fail(line, "%v", err)
+			continue
+		}
+
+		f.Lines = append(f.Lines, BudgetLine{
+			Line:      line,
+			Month:     month,
+			Group:     group,
+			Category:  category,
+			Budgeted:  budgeted,
+			Available: available,
+		})
+	}
+
+	return f, nil
+}
+
+// parseYNABMonth parses the months of YNAB's budget exports, like
+// "January 2018" or "Jan 2018".
+func parseYNABMonth(s string) (budgeting.YearMonth, error) {
+	for _, layout := range []string{"January 2006", "Jan 2006", "2006-01"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return budgeting.YearMonthFromTime(t), nil
+		}
+	}
+	return budgeting.YearMonth{}, fmt.Errorf("invalid month %q", s)
+}
+
+// MigrationReport tells what became of the exports of another app.
+type MigrationReport struct {
+	// Accounts and Categories are those created for the import.
+	Accounts   []*budgeting.Account
+	Categories []*budgeting.Category
+
+	// Transactions are the reports of importing the transactions of every
+	// account.
+	Transactions []*Report
+
+	// Budgeted is the number of budgeted amounts set. Skipped and Failed
+	// are the lines of the exports besides transactions which weren't
+	// imported, such as those of the budget export or parts of the
+	// exporting app which have no equivalent.
+	Budgeted int
+	Skipped  []Problem
+	Failed   []Problem
+
+	// Differences are the lines of the budget export whose available
+	// amount differs from the budget's after the import.
+	Differences []Difference
+}
+
+// Difference is a category whose available amount differs from what the
+// exporting app said it is.
+type Difference struct {
+	Line     int
+	Month    budgeting.YearMonth
+	Category *budgeting.Category
+
+	// Expected is what the exporting app said is available, and Available
+	// what the budget says.
+	Expected  decimal.Decimal
+	Available decimal.Decimal
+}
+
+// ImportYNAB adds the accounts and transactions of YNAB's register export
+// and the budgeted amounts of its budget export, if any, to the budget.
+// Every account of the register is created, and a "Starting Balance"
+// transaction becomes its starting balance; accounts which already exist
+// fail. Categories are matched by group and name, and created if missing.
+// Transactions are added approved, as they were in YNAB.
+//
+// Budgets which never overspent then have the same available amounts as in
+// YNAB. YNAB moves the overspending of a month out of the category and
+// from the next month's To Be Budgeted, while categories here stay
+// overspent until money is budgeted for them, so those categories and To
+// Be Budgeted differ. They are listed in the report.
+func ImportYNAB(b *budgeting.Budget, register []*Statement, budget *BudgetFile) *MigrationReport {
+	r := &MigrationReport{
+		Accounts:     []*budgeting.Account{},
+		Categories:   []*budgeting.Category{},
+		Transactions: []*Report{},
+		Skipped:      []Problem{},
+		Failed:       []Problem{},
+		Differences:  []Difference{},
+	}
+	before := map[string]bool{}
+	for _, c := range b.Categories() {
+		before[c.ID()] = true
+	}
+
+	// Every account has to exist before transfers between them are added.
+	accounts := make([]*budgeting.Account, len(register))
+	for i, s := range register {
+		a, err := createAccount(b, s, time.Time{}, func(first Transaction) bool {
+			return first.Income && strings.HasPrefix(first.Description, "Starting Balance")
+		})
+		if err != nil {
+			r.Failed = append(r.Failed, Problem{Line: firstLine(s), Reason: err.Error()})
+			continue
+		}
+		accounts[i] = a
+		r.Accounts = append(r.Accounts, a)
+	}
+
+	// Categories are created in the order of the budget export, which is
+	// YNAB's order.
+	categories := newCategoryIndex(b)
+	lines := []BudgetLine{}
+	if budget != nil {
+		r.Skipped = append(r.Skipped, budget.Skipped...)
+		r.Failed = append(r.Failed, budget.Failed...)
+		lines = budget.Lines
+	}
+	for _, l := range lines {
+		if _, err := categories.get(l.Group, l.Category); err != nil {
+			r.Failed = append(r.Failed, Problem{Line: l.Line, Reason: err.Error()})
+		}
+	}
+
+	for i, s := range register {
+		if accounts[i] != nil {
+			r.Transactions = append(r.Transactions, Import(accounts[i], s, Approved()))
+		}
+	}
+
+	// Lines whose category couldn't be created have failed already.
+	for _, l := range lines {
+		c, err := categories.get(l.Group, l.Category)
+		if err != nil || l.Budgeted.IsZero() {
+			continue
+		}
+		if err := b.SetBudgeted(l.Month, c, l.Budgeted); err != nil {
+			r.Failed = append(r.Failed, Problem{Line: l.Line, Reason: err.Error()})
+			continue
+		}
+		r.Budgeted++
+	}
+
+	for _, l := range lines {
+		c, err := categories.get(l.Group, l.Category)
+		if err != nil {
+			continue
+		}
+		if available := b.Available(l.Month, c); !available.Equal(l.Available) {
+			r.Differences = append(r.Differences, Difference{
+				Line:      l.Line,
+				Month:     l.Month,
+				Category:  c,
+				Expected:  l.Available,
+				Available: available,
+			})
+		}
+	}
+
+	for _, c := range b.Categories() {
+		if !before[c.ID()] {
+			r.Categories = append(r.Categories, c)
+		}
+	}
+	return r
+}
+
+// createAccount creates the account of the statement of another app, with
+// the first transaction as its starting balance if starting says it is one.
+// The account is opened on the date of the first transaction, or earlier if
+// opened says so. Accounts aren't imported into twice, as the exports of
+// some apps have no IDs to skip the transactions already imported.
+func createAccount(b *budgeting.Budget, s *Statement, opened time.Time, starting func(first Transaction) bool) (*budgeting.Account, error) {
+	for _, a := range b.Accounts() {
+		if strings.EqualFold(a.Name(), s.Account) {
+			return nil, fmt.Errorf("account %q already exists", s.Account)
+		}
+	}
+	if len(s.Transactions) == 0 && opened.IsZero() {
+		return nil, fmt.Errorf("account %q has no transactions", s.Account)
+	}
+
+	// The starting balance is usually the first transaction, but YNAB
+	// sorts its exports by date, most recent first, and GnuCash doesn't
+	// sort them at all.
+	sort.SliceStable(s.Transactions, func(i, j int) bool {
+		return s.Transactions[i].Date.Before(s.Transactions[j].Date)
+	})
+
+	balance, date := decimal.Decimal{}, opened
+	if len(s.Transactions) > 0 {
+		first := s.Transactions[0]
+		if date.IsZero() || first.Date.Before(date) {
+			date = first.Date
+		}
+		if first.Date.Equal(date) && starting(first) {
+			balance = first.Amount
+			s.Transactions = s.Transactions[1:]
+		}
+	}
+
+	return b.AddAccount(s.Account, balance, date)
+}
+
+func firstLine(s *Statement) int {
+	if len(s.Transactions) > 0 {
+		return s.Transactions[0].Line
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/stretchr/testify/assert"
+)
+
+const ynab4Register = `"Account","Flag","Check Number","Date","Payee","Category","Master Category","Sub Category","Memo","Outflow","Inflow","Cleared","Running Balance"
+"Checking","","","02/01/2018","Employer","Income:Available this month","Income","Available this month","","$0.00","$2,000.00","C","$2,907.90"
+"Checking","","","01/06/2018","Transfer : Wallet","","","","","$50.00","$0.00","C","$907.90"
+"Checking","","","01/05/2018","Supermarket","Everyday Expenses:Groceries","Everyday Expenses","Groceries","Weekly shop","$42.10","$0.00","C","$957.90"
+"Checking","","","01/01/2018","Starting Balance","Income:Available this month","Income","Available this month","","$0.00","$1,000.00","R","$1,000.00"
+"Wallet","","","01/06/2018","Transfer : Checking","","","","","$0.00","$50.00","C","$50.00"
+"Wallet","","","01/07/2018","Cafe","Everyday Expenses:Restaurants","Everyday Expenses","Restaurants","","$4.50","$0.00","U","$45.50"
+"Wallet","","","13/07/2018","Cafe","Everyday Expenses:Restaurants","Everyday Expenses","Restaurants","","$4.50","$0.00","U","$41.00"
+`
+
+const ynab4Budget = `"Month","Category","Master Category","Sub Category","Budgeted","Outflows","Category Balance"
+"January 2018","Everyday Expenses:Groceries","Everyday Expenses","Groceries","$300.00","-$42.10","$257.90"
+"January 2018","Everyday Expenses:Restaurants","Everyday Expenses","Restaurants","$0.00","-$4.50","-$4.50"
+"January 2018","Monthly Bills:Rent","Monthly Bills","Rent","$500.00","$0.00","$500.00"
+"February 2018","Everyday Expenses:Groceries","Everyday Expenses","Groceries","$0.00","$0.00","$257.90"
+"February 2018","Everyday Expenses:Restaurants","Everyday Expenses","Restaurants","$0.00","$0.00","$0.00"
+"February 2018","Monthly Bills:Rent","Monthly Bills","Rent","$500.00","$0.00","$1,000.00"
+"February 2018","Hidden Categories:Everyday Expenses ` + "` Old ` 12" + `","Hidden Categories","Everyday Expenses ` + "` Old ` 12" + `","$0.00","$0.00","$0.00"
+"Mars 2018","Monthly Bills:Rent","Monthly Bills","Rent","$500.00","$0.00","$1,500.00"
+`
+
+func TestImportYNAB4(t *testing.T) {
+	assert := assert.New(t)
+
+	register, err := ReadYNABRegister(strings.NewReader(ynab4Register), false)
+	assert.Nil(err)
+	assert.Len(register, 2)
+	assert.Equal([]Problem{{Line: 8, Reason: `invalid date "13/07/2018"`}}, register[1].Failed)
+
+	budget, err := ReadYNABBudget(strings.NewReader(ynab4Budget))
+	assert.Nil(err)
+	assert.Len(budget.Lines, 7)
+	assert.Equal("Everyday Expenses", budget.Lines[6].Group)
+	assert.Equal("Old", budget.Lines[6].Category)
+	assert.Equal([]Problem{{Line: 9, Reason: `invalid month "Mars 2018"`}}, budget.Failed)
+
+	b := budgeting.NewBudget("Migrated")
+	r := ImportYNAB(b, register, budget)
+	assert.Len(r.Accounts, 2)
+	assert.Equal([]string{"Groceries", "Restaurants", "Rent", "Old"}, categoryNames(r.Categories))
+	assert.Equal("Everyday Expenses", r.Categories[0].Group())
+	assert.Equal(3, r.Budgeted)
+	assert.Equal("3 imported, 0 skipped, 0 failed", r.Transactions[0].Summary())
+	assert.Equal("1 imported, 1 skipped, 1 failed", r.Transactions[1].Summary())
+
+	checking, wallet := r.Accounts[0], r.Accounts[1]
+	assert.Equal(date(2018, 1, 1), checking.Transactions()[0].Date())
+	assert.True(dec("2907.90").Equal(checking.Balance()))
+	assert.True(dec("45.50").Equal(wallet.Balance()))
+	assert.True(checking.Transactions()[2].TransferAccount() == wallet)
+	assert.True(checking.Transactions()[1].Approved())
+	assert.True(checking.Transactions()[1].Cleared())
+	assert.False(wallet.Transactions()[2].Cleared())
+
+	jan := budgeting.YearMonth{Year: 2018, Month: time.January}
+	assert.True(dec("0").Equal(b.TBB(jan)))
+	assert.True(dec("1700").Equal(b.TBB(jan.NextMonth())))
+
+	// YNAB moved the overspending of Restaurants out of it in February.
+	assert.Len(r.Differences, 1)
+	assert.Equal(6, r.Differences[0].Line)
+	assert.Equal("Restaurants", r.Differences[0].Category.Name())
+	assert.True(dec("0").Equal(r.Differences[0].Expected))
+	assert.True(dec("-4.50").Equal(r.Differences[0].Available))
+}
+
+func TestReadYNABRegister_CurrentYNAB(t *testing.T) {
+	assert := assert.New(t)
+
+	in := "\ufeff\"Account\",\"Flag\",\"Date\",\"Payee\",\"Category Group/Category\",\"Category Group\",\"Category\",\"Memo\",\"Outflow\",\"Inflow\",\"Cleared\"\n" +
+		"\"Girokonto\",\"\",\"05.01.2018\",\"Supermarkt\",\"Alltag: Essen\",\"Alltag\",\"Essen\",\"\",\"1.042,10€\",\"0,00€\",\"Cleared\"\n" +
+		"\"Girokonto\",\"\",\"06.01.2018\",\"Arbeitgeber\",\"Inflow: Ready to Assign\",\"Inflow\",\"Ready to Assign\",\"Januar\",\"0,00€\",\"2.000,00€\",\"Cleared\"\n"
+
+	register, err := ReadYNABRegister(strings.NewReader(in), true)
+	assert.Nil(err)
+	assert.Equal("Girokonto", register[0].Account)
+	assert.Equal(Transaction{Line: 2, Date: date(2018, 1, 5), Amount: dec("-1042.10"), Description: "Supermarkt", Category: "Essen", Group: "Alltag"}, register[0].Transactions[0])
+	assert.True(register[0].Transactions[1].Income)
+	assert.Equal("Arbeitgeber Januar", register[0].Transactions[1].Description)
+
+	_, err = ReadYNABRegister(strings.NewReader("Date,Amount\n"), false)
+	assert.EqualError(err, `no "account" column, is this a YNAB export?`)
+
+	budget, err := ReadYNABBudget(strings.NewReader("Month,Category Group/Category,Category Group,Category,Assigned,Activity,Available\n" +
+		"Jan 2018,Alltag: Essen,Alltag,Essen,300.00,-42.10,257.90\n" +
+		"Jan 2018,Inflow: Ready to Assign,Inflow,Ready to Assign,0.00,2000.00,0.00\n"))
+	assert.Nil(err)
+	assert.Equal([]BudgetLine{{Line: 2, Month: budgeting.YearMonth{Year: 2018, Month: time.January}, Group: "Alltag", Category: "Essen", Budgeted: dec("300.00"), Available: dec("257.90")}}, budget.Lines)
+	assert.Equal([]Problem{{Line: 3, Reason: "not a budget category"}}, budget.Skipped)
+}
+
+func categoryNames(categories []*budgeting.Category) []string {
+	names := []string{}
+	for _, c := range categories {
+		names = append(names, c.Name())
+	}
+	return names
+}
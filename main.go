@@ -1,11 +1,11 @@
 package main
 
 import (
-	"fmt"
 	"os"
+
+	"github.com/hasyimibhar/budget-app/cli"
 )
 
 func main() {
-	fmt.Println("hello, world!")
-	os.Exit(0)
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
 }
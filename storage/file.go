@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+const fileExt = ".json"
+
+// FileStore stores each budget as a JSON file in a directory.
+// Version checks on Save are only atomic within a single process,
+// so only one process should use a directory at a time.
+type FileStore struct {
+	mu       sync.Mutex
+	dir      string
+	migrator *Migrator
+}
+
+// NewFileStore creates a store backed by dir, creating the directory if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		dir:      dir,
+		migrator: DefaultMigrator,
+	}, nil
+}
+
+// Load returns the budget with the given ID.
+func (s *FileStore) Load(id string) (*budgeting.Budget, error) {
+	data, err := s.read(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(s.migrator, data)
+}
+
+// Save writes the budget to disk if the stored version matches expectedVersion.
+func (s *FileStore) Save(b *budgeting.Budget, expectedVersion int64) error {
+	data, err := encode(b, s.migrator.CurrentVersion())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.read(b.ID())
+	if err == ErrNotFound {
+		stored, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := checkVersion(s.migrator, stored, expectedVersion); err != nil {
+		return err
+	}
+
+	return s.write(b.ID(), data)
+}
+
+// Delete removes the budget with the given ID.
+func (s *FileStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// List returns the IDs of all stored budgets, sorted.
+func (s *FileStore) List() ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != fileExt {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, fileExt))
+	}
+
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// MigrationResult describes the outcome of migrating a single stored budget.
+type MigrationResult struct {
+	ID       string
+	From     int
+	To       int
+	Migrated bool
+	Err      error
+}
+
+// Migrate upgrades every stored budget to the current schema version in place.
+// If dryRun is true, the upgraded documents are computed but not written.
+// Budgets that fail to migrate are reported in their result and left untouched.
+func (s *FileStore) Migrate(dryRun bool) ([]MigrationResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []MigrationResult{}
+	for _, id := range ids {
+		result := MigrationResult{
+			ID: id,
+			To: s.migrator.CurrentVersion(),
+		}
+
+		data, err := s.read(id)
+		if err != nil {
+			result.Err = err
+			results = append(results, result)
+			continue
+		}
+
+		migrated, from, err := s.migrator.Migrate(data)
+		result.From = from
+		if err != nil {
+			result.Err = err
+			results = append(results, result)
+			continue
+		}
+
+		// Make sure the upgraded document is something the domain can load
+		// before replacing the original.
+		if _, err := decode(s.migrator, migrated); err != nil {
+			result.Err = err
+			results = append(results, result)
+			continue
+		}
+
+		if from != result.To {
+			result.Migrated = true
+			if !dryRun {
+				result.Err = s.write(id, migrated)
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (s *FileStore) path(id string) (string, error) {
+	if id == "" || strings.HasPrefix(id, ".") || strings.ContainsAny(id, `/\`) {
+		return "", ErrNotFound
+	}
+
+	return filepath.Join(s.dir, id+fileExt), nil
+}
+
+func (s *FileStore) read(id string) ([]byte, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// write replaces the file atomically so that a crash never leaves a
+// half-written budget behind.
+func (s *FileStore) write(id string, data []byte) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(s.dir, "."+id+"-")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
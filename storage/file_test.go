@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStore_SaveLoad(t *testing.T) {
+	assert := assert.New(t)
+	s := newTestFileStore(t)
+
+	b := testBudget()
+	assert.Nil(s.Save(b, 0))
+
+	ids, err := s.List()
+	assert.Nil(err)
+	assert.Equal([]string{b.ID()}, ids)
+
+	loaded, err := s.Load(b.ID())
+	assert.Nil(err)
+	assert.Equal(b.ID(), loaded.ID())
+	assert.Equal(b.Name(), loaded.Name())
+
+	jan := budgeting.YearMonth{Year: 2018, Month: time.January}
+	assert.Equal(b.TBB(jan).StringFixed(2), loaded.TBB(jan).StringFixed(2))
+
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, b.ID()+".json"))
+	assert.Nil(err)
+	version, err := s.migrator.Version(data)
+	assert.Nil(err)
+	assert.Equal(CurrentSchemaVersion, version)
+
+	assert.Nil(s.Delete(b.ID()))
+	_, err = s.Load(b.ID())
+	assert.EqualError(err, ErrNotFound.Error())
+	assert.EqualError(s.Delete(b.ID()), ErrNotFound.Error())
+}
+
+func TestFileStore_SaveConflict(t *testing.T) {
+	assert := assert.New(t)
+	s := newTestFileStore(t)
+
+	b := testBudget()
+	assert.Nil(s.Save(b, 0))
+	assert.EqualError(s.Save(b, 0), ErrConcurrentModification.Error())
+
+	expected := b.Version()
+	b.AddCategory("Rent")
+	assert.Nil(s.Save(b, expected))
+}
+
+func TestFileStore_LoadInvalidID(t *testing.T) {
+	assert := assert.New(t)
+	s := newTestFileStore(t)
+
+	for _, id := range []string{"", "../secret", ".hidden", `a\b`} {
+		_, err := s.Load(id)
+		assert.EqualError(err, ErrNotFound.Error())
+	}
+}
+
+func TestFileStore_Migrate(t *testing.T) {
+	assert := assert.New(t)
+	s := newTestFileStore(t)
+
+	m := NewMigrator(2)
+	m.Register(1, "rename title to name", func(budget map[string]interface{}) error {
+		budget["name"] = budget["title"]
+		delete(budget, "title")
+		return nil
+	})
+
+	// Write a budget as it would have looked at schema version 1.
+	b := testBudget()
+	old := storedAt(t, b, 1, func(budget map[string]interface{}) {
+		budget["title"] = budget["name"]
+		delete(budget, "name")
+	})
+	assert.Nil(s.write(b.ID(), old))
+	// Budget IDs are hex UUIDs, so this one is always listed last.
+	assert.Nil(s.write("zz-broken", []byte(`{"budget":{}}`)))
+
+	s.migrator = m
+
+	results, err := s.Migrate(true)
+	assert.Nil(err)
+	assert.Len(results, 2)
+	assert.Equal(b.ID(), results[0].ID)
+	assert.True(results[0].Migrated)
+	assert.Equal(1, results[0].From)
+	assert.Equal(2, results[0].To)
+	assert.Nil(results[0].Err)
+	assert.Equal("zz-broken", results[1].ID)
+	assert.EqualError(results[1].Err, ErrMissingSchemaVersion.Error())
+
+	// A dry run must not touch the stored document.
+	data, _ := s.read(b.ID())
+	assert.Equal(old, data)
+
+	results, err = s.Migrate(false)
+	assert.Nil(err)
+	assert.True(results[0].Migrated)
+
+	data, _ = s.read(b.ID())
+	version, _ := m.Version(data)
+	assert.Equal(2, version)
+
+	loaded, err := s.Load(b.ID())
+	assert.Nil(err)
+	assert.Equal("My Budget", loaded.Name())
+
+	results, err = s.Migrate(false)
+	assert.Nil(err)
+	assert.False(results[0].Migrated)
+}
+
+func newTestFileStore(t *testing.T) *FileStore {
+	dir, err := ioutil.TempDir("", "budget-app-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	s, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func testBudget() *budgeting.Budget {
+	b := budgeting.NewBudget("My Budget")
+	acc, _ := b.AddAccount("Savings", dec("100.00"), date(2018, 1, 1))
+	food, _ := b.AddCategory("Food")
+
+	b.SetBudgeted(budgeting.YearMonth{Year: 2018, Month: time.January}, food, dec("30.00"))
+	acc.AddTransaction(date(2018, 1, 2), dec("-5.00"), "lunch", food, nil)
+
+	return b
+}
+
+// storedAt encodes b as a document of the given schema version, applying
+// downgrade to the budget object to make it look like that version.
+func storedAt(t *testing.T, b *budgeting.Budget, version int, downgrade func(map[string]interface{})) []byte {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var budget map[string]interface{}
+	if err := json.Unmarshal(raw, &budget); err != nil {
+		t.Fatal(err)
+	}
+	downgrade(budget)
+
+	raw, _ = json.Marshal(budget)
+	data, _ := json.Marshal(document{SchemaVersion: version, Budget: raw})
+	return data
+}
+
+func date(y int, m int, d int) time.Time {
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+}
+
+func dec(s string) decimal.Decimal {
+	d, _ := decimal.NewFromString(s)
+	return d
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+// MemoryStore keeps encoded budgets in memory. It is mainly useful for tests.
+// Budgets are stored encoded, so callers never share an instance with the store.
+type MemoryStore struct {
+	mu       sync.Mutex
+	docs     map[string][]byte
+	migrator *Migrator
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		docs:     map[string][]byte{},
+		migrator: DefaultMigrator,
+	}
+}
+
+// Load returns the budget with the given ID.
+func (s *MemoryStore) Load(id string) (*budgeting.Budget, error) {
+	s.mu.Lock()
+	data, ok := s.docs[id]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return decode(s.migrator, data)
+}
+
+// Save stores the budget if the stored version matches expectedVersion.
+func (s *MemoryStore) Save(b *budgeting.Budget, expectedVersion int64) error {
+	data, err := encode(b, s.migrator.CurrentVersion())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := checkVersion(s.migrator, s.docs[b.ID()], expectedVersion); err != nil {
+		return err
+	}
+
+	s.docs[b.ID()] = data
+	return nil
+}
+
+// Delete removes the budget with the given ID.
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.docs[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(s.docs, id)
+	return nil
+}
+
+// List returns the IDs of all stored budgets, sorted.
+func (s *MemoryStore) List() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := []string{}
+	for id := range s.docs {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	return ids, nil
+}
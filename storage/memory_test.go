@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_SaveConflict(t *testing.T) {
+	assert := assert.New(t)
+	s := NewMemoryStore()
+
+	b := testBudget()
+	assert.EqualError(s.Save(b, 3), ErrConcurrentModification.Error())
+	assert.Nil(s.Save(b, 0))
+
+	tab1, err := s.Load(b.ID())
+	assert.Nil(err)
+	tab2, err := s.Load(b.ID())
+	assert.Nil(err)
+	assert.Equal(b.Version(), tab1.Version())
+
+	expected := tab1.Version()
+	tab1.AddCategory("Rent")
+	tab2.AddCategory("Groceries")
+
+	assert.Nil(s.Save(tab1, expected))
+	assert.EqualError(s.Save(tab2, expected), ErrConcurrentModification.Error())
+
+	loaded, err := s.Load(b.ID())
+	assert.Nil(err)
+	assert.Equal(tab1.Version(), loaded.Version())
+
+	// Saving an unmodified budget again is fine.
+	assert.Nil(s.Save(loaded, loaded.Version()))
+}
+
+func TestUpdate_Retry(t *testing.T) {
+	assert := assert.New(t)
+	s := NewMemoryStore()
+
+	b := testBudget()
+	assert.Nil(s.Save(b, 0))
+
+	calls := 0
+	updated, err := Update(s, b.ID(), func(b *budgeting.Budget) error {
+		calls++
+		if calls == 1 {
+			// Someone else saves while we are working on our copy.
+			other, _ := s.Load(b.ID())
+			expected := other.Version()
+			other.AddCategory("Rent")
+			assert.Nil(s.Save(other, expected))
+		}
+
+		b.AddCategory("Groceries")
+		return nil
+	})
+	assert.Nil(err)
+	assert.Equal(2, calls)
+
+	loaded, err := s.Load(b.ID())
+	assert.Nil(err)
+	assert.Equal(updated.Version(), loaded.Version())
+}
+
+func TestUpdate_GivesUp(t *testing.T) {
+	assert := assert.New(t)
+	s := NewMemoryStore()
+
+	b := testBudget()
+	assert.Nil(s.Save(b, 0))
+
+	_, err := Update(s, b.ID(), func(b *budgeting.Budget) error {
+		other, _ := s.Load(b.ID())
+		expected := other.Version()
+		other.AddCategory("Rent")
+		s.Save(other, expected)
+
+		b.AddCategory("Groceries")
+		return nil
+	})
+	assert.EqualError(err, ErrConcurrentModification.Error())
+
+	_, err = Update(s, b.ID(), func(b *budgeting.Budget) error {
+		return fmt.Errorf("invalid")
+	})
+	assert.EqualError(err, "invalid")
+
+	_, err = Update(s, "missing", func(b *budgeting.Budget) error { return nil })
+	assert.EqualError(err, ErrNotFound.Error())
+}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+var (
+	// ErrMissingSchemaVersion is returned when a stored document has no schema version.
+	ErrMissingSchemaVersion = fmt.Errorf("document has no schema version")
+
+	// ErrUnsupportedSchemaVersion is returned when a stored document was written
+	// by a newer version of the app.
+	ErrUnsupportedSchemaVersion = fmt.Errorf("document schema version is newer than supported")
+)
+
+// MigrationError is returned when a stored document cannot be upgraded
+// from one schema version to the next.
+type MigrationError struct {
+	From int
+	Err  error
+}
+
+func (e *MigrationError) Error() string {
+	return fmt.Sprintf("migrating from schema version %d: %v", e.From, e.Err)
+}
+
+// MigrateFunc upgrades a decoded budget document by exactly one schema version.
+// Numbers in the document are decoded as json.Number.
+type MigrateFunc func(budget map[string]interface{}) error
+
+// Migration is a single step in the migration registry.
+type Migration struct {
+	// From is the schema version the migration upgrades from.
+	// The document will be at version From+1 afterwards.
+	From        int
+	Description string
+	Up          MigrateFunc
+}
+
+// Migrator holds a registry of migrations and upgrades documents to its
+// current schema version.
+type Migrator struct {
+	current    int
+	migrations map[int]Migration
+}
+
+// NewMigrator creates an empty registry which upgrades documents up to current.
+func NewMigrator(current int) *Migrator {
+	return &Migrator{
+		current:    current,
+		migrations: map[int]Migration{},
+	}
+}
+
+// CurrentVersion returns the schema version documents are upgraded to.
+func (m *Migrator) CurrentVersion() int {
+	return m.current
+}
+
+// Register adds a migration from one schema version to the next.
+// It panics if a migration from that version is already registered,
+// since the registry is expected to be built once at init time.
+func (m *Migrator) Register(from int, description string, up MigrateFunc) {
+	if from < 1 || from >= m.current {
+		panic(fmt.Sprintf("storage: migration from version %d is outside the supported range", from))
+	}
+	if _, ok := m.migrations[from]; ok {
+		panic(fmt.Sprintf("storage: duplicate migration from version %d", from))
+	}
+
+	m.migrations[from] = Migration{
+		From:        from,
+		Description: description,
+		Up:          up,
+	}
+}
+
+// Migrations returns the migrations needed to upgrade a document from the
+// given version, in the order they will be applied.
+func (m *Migrator) Migrations(from int) ([]Migration, error) {
+	if from < 1 {
+		return nil, ErrMissingSchemaVersion
+	}
+	if from > m.current {
+		return nil, ErrUnsupportedSchemaVersion
+	}
+
+	steps := []Migration{}
+	for v := from; v < m.current; v++ {
+		step, ok := m.migrations[v]
+		if !ok {
+			return nil, &MigrationError{From: v, Err: fmt.Errorf("no migration registered")}
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// Version returns the schema version of a stored document.
+func (m *Migrator) Version(data []byte) (int, error) {
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, err
+	}
+	if doc.SchemaVersion < 1 {
+		return 0, ErrMissingSchemaVersion
+	}
+
+	return doc.SchemaVersion, nil
+}
+
+// Migrate upgrades a stored document to the current schema version.
+// It returns the upgraded document along with the version it was stored with.
+// Documents that are already current are returned unchanged.
+func (m *Migrator) Migrate(data []byte) ([]byte, int, error) {
+	from, err := m.Version(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	steps, err := m.Migrations(from)
+	if err != nil {
+		return nil, from, err
+	}
+	if len(steps) == 0 {
+		return data, from, nil
+	}
+
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, from, err
+	}
+
+	var budget map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(doc.Budget))
+	dec.UseNumber()
+	if err := dec.Decode(&budget); err != nil {
+		return nil, from, err
+	}
+
+	for _, step := range steps {
+		if err := step.Up(budget); err != nil {
+			return nil, from, &MigrationError{From: step.From, Err: err}
+		}
+	}
+
+	raw, err := json.Marshal(budget)
+	if err != nil {
+		return nil, from, err
+	}
+
+	out, err := json.Marshal(document{
+		SchemaVersion: m.current,
+		Budget:        raw,
+	})
+	if err != nil {
+		return nil, from, err
+	}
+
+	return out, from, nil
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testMigrator() *Migrator {
+	m := NewMigrator(3)
+
+	m.Register(1, "rename title to name", func(budget map[string]interface{}) error {
+		budget["name"] = budget["title"]
+		delete(budget, "title")
+		return nil
+	})
+	m.Register(2, "add currency", func(budget map[string]interface{}) error {
+		budget["currency"] = "MYR"
+		return nil
+	})
+
+	return m
+}
+
+func TestMigrator_Migrate(t *testing.T) {
+	assert := assert.New(t)
+	m := testMigrator()
+
+	out, from, err := m.Migrate([]byte(`{"schema_version":1,"budget":{"title":"My Budget","amount":1.10}}`))
+	assert.Nil(err)
+	assert.Equal(1, from)
+	assert.JSONEq(`{"schema_version":3,"budget":{"name":"My Budget","currency":"MYR","amount":1.10}}`, string(out))
+
+	out, from, err = m.Migrate([]byte(`{"schema_version":2,"budget":{"name":"My Budget"}}`))
+	assert.Nil(err)
+	assert.Equal(2, from)
+	assert.JSONEq(`{"schema_version":3,"budget":{"name":"My Budget","currency":"MYR"}}`, string(out))
+}
+
+func TestMigrator_MigrateCurrent(t *testing.T) {
+	assert := assert.New(t)
+	m := testMigrator()
+
+	in := []byte(`{"schema_version":3,"budget":{"name":"My Budget"}}`)
+	out, from, err := m.Migrate(in)
+	assert.Nil(err)
+	assert.Equal(3, from)
+	assert.Equal(in, out)
+}
+
+func TestMigrator_MigrateErrors(t *testing.T) {
+	assert := assert.New(t)
+	m := testMigrator()
+
+	_, _, err := m.Migrate([]byte(`{"budget":{}}`))
+	assert.EqualError(err, ErrMissingSchemaVersion.Error())
+
+	_, _, err = m.Migrate([]byte(`{"schema_version":4,"budget":{}}`))
+	assert.EqualError(err, ErrUnsupportedSchemaVersion.Error())
+
+	gap := NewMigrator(3)
+	gap.Register(2, "noop", func(map[string]interface{}) error { return nil })
+	_, _, err = gap.Migrate([]byte(`{"schema_version":1,"budget":{}}`))
+	assert.NotNil(err)
+
+	failing := NewMigrator(2)
+	failing.Register(1, "fail", func(map[string]interface{}) error { return fmt.Errorf("boom") })
+	_, _, err = failing.Migrate([]byte(`{"schema_version":1,"budget":{}}`))
+	assert.EqualError(err, "migrating from schema version 1: boom")
+}
+
+func TestMigrator_RegisterDuplicate(t *testing.T) {
+	m := testMigrator()
+
+	assert.Panics(t, func() {
+		m.Register(1, "again", func(map[string]interface{}) error { return nil })
+	})
+	assert.Panics(t, func() {
+		m.Register(3, "beyond current", func(map[string]interface{}) error { return nil })
+	})
+}
+
+func TestDefaultMigrator_Complete(t *testing.T) {
+	assert := assert.New(t)
+
+	steps, err := DefaultMigrator.Migrations(1)
+	assert.Nil(err)
+	assert.Len(steps, CurrentSchemaVersion-1)
+
+	for _, step := range steps {
+		assert.NotEmpty(step.Description)
+	}
+}
+
+func TestDefaultMigrator_AddVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	out, _, err := DefaultMigrator.Migrate([]byte(`{"schema_version":1,"budget":{"name":"My Budget"}}`))
+	assert.Nil(err)
+	assert.JSONEq(`{"schema_version":5,"budget":{"name":"My Budget","version":0}}`, string(out))
+}
+
+func TestDefaultMigrator_PairTransfers(t *testing.T) {
+	assert := assert.New(t)
+
+	in := `{"schema_version":2,"budget":{"version":3,"accounts":[
+		{"name":"Savings","transactions":[
+			{"id":"a1","date":"2018-01-01T00:00:00Z","description":"Starting balance","amount":"100","category":"tbb"},
+			{"id":"a2","date":"2018-01-02T00:00:00Z","description":"withdraw","amount":"-5","rel":1},
+			{"id":"a3","date":"2018-01-02T00:00:00Z","description":"withdraw","amount":"-5","rel":1}
+		]},
+		{"name":"Wallet","transactions":[
+			{"id":"b1","date":"2018-01-02T00:00:00Z","description":"withdraw","amount":"5","rel":0},
+			{"id":"b2","date":"2018-01-02T00:00:00Z","description":"withdraw","amount":"5.00","rel":0}
+		]}
+	]}}`
+
+	out, _, err := DefaultMigrator.Migrate([]byte(in))
+	assert.Nil(err)
+
+	var doc struct {
+		Budget struct {
+			Accounts []struct {
+				Transactions []struct {
+					ID   string `json:"id"`
+					Pair string `json:"pair"`
+				} `json:"transactions"`
+			} `json:"accounts"`
+		} `json:"budget"`
+	}
+	assert.Nil(json.Unmarshal(out, &doc))
+
+	savings := doc.Budget.Accounts[0].Transactions
+	wallet := doc.Budget.Accounts[1].Transactions
+	assert.Equal("", savings[0].Pair)
+	assert.Equal("b1", savings[1].Pair)
+	assert.Equal("b2", savings[2].Pair)
+	assert.Equal("a2", wallet[0].Pair)
+	assert.Equal("a3", wallet[1].Pair)
+
+	unmatched := `{"schema_version":2,"budget":{"version":0,"accounts":[
+		{"name":"Savings","transactions":[
+			{"id":"a1","date":"2018-01-02T00:00:00Z","description":"withdraw","amount":"-5","rel":1}
+		]},
+		{"name":"Wallet","transactions":[]}
+	]}}`
+	_, _, err = DefaultMigrator.Migrate([]byte(unmatched))
+	assert.EqualError(err, "migrating from schema version 2: transfer a1 has no matching transaction on the other account")
+}
+
+func TestDefaultMigrator_AccountIDs(t *testing.T) {
+	assert := assert.New(t)
+
+	in := `{"schema_version":3,"budget":{"version":0,"accounts":[
+		{"name":"Savings","transactions":[
+			{"id":"a1","date":"2018-01-02T00:00:00Z","description":"withdraw","amount":"-5","rel":1,"pair":"b1"}
+		]},
+		{"name":"Wallet","transactions":[
+			{"id":"b1","date":"2018-01-02T00:00:00Z","description":"withdraw","amount":"5","rel":0,"pair":"a1"}
+		]}
+	]}}`
+
+	out, _, err := DefaultMigrator.Migrate([]byte(in))
+	assert.Nil(err)
+
+	var doc struct {
+		Budget struct {
+			Accounts []struct {
+				ID           string `json:"id"`
+				Transactions []struct {
+					Rel string `json:"rel"`
+				} `json:"transactions"`
+			} `json:"accounts"`
+		} `json:"budget"`
+	}
+	assert.Nil(json.Unmarshal(out, &doc))
+
+	savings := doc.Budget.Accounts[0]
+	wallet := doc.Budget.Accounts[1]
+	assert.NotEmpty(savings.ID)
+	assert.NotEmpty(wallet.ID)
+	assert.NotEqual(savings.ID, wallet.ID)
+	assert.Equal(wallet.ID, savings.Transactions[0].Rel)
+	assert.Equal(savings.ID, wallet.Transactions[0].Rel)
+}
+
+func TestDefaultMigrator_StartingBalances(t *testing.T) {
+	assert := assert.New(t)
+
+	in := `{"schema_version":4,"budget":{"version":0,"accounts":[
+		{"id":"savings","name":"Savings","transactions":[
+			{"id":"a1","date":"2018-01-02T00:00:00Z","description":"Starting balance","amount":"-5","rel":"wallet","pair":"b1"},
+			{"id":"a2","date":"2018-01-01T00:00:00Z","description":"Starting balance","amount":"100","category":"tbb"}
+		]},
+		{"id":"wallet","name":"Wallet","transactions":[
+			{"id":"b1","date":"2018-01-02T00:00:00Z","description":"Starting balance","amount":"5","rel":"savings","pair":"a1"}
+		]}
+	]}}`
+
+	out, _, err := DefaultMigrator.Migrate([]byte(in))
+	assert.Nil(err)
+
+	var doc struct {
+		Budget struct {
+			Accounts []struct {
+				StartingBalance string `json:"starting_balance"`
+			} `json:"accounts"`
+		} `json:"budget"`
+	}
+	assert.Nil(json.Unmarshal(out, &doc))
+
+	assert.Equal("a2", doc.Budget.Accounts[0].StartingBalance)
+	assert.Equal("", doc.Budget.Accounts[1].StartingBalance)
+}
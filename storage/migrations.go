@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CurrentSchemaVersion is the schema version stamped on budgets written by
+// this version of the app. Bump it together with registering a migration from
+// the previous version in DefaultMigrator.
+const CurrentSchemaVersion = 5
+
+// DefaultMigrator upgrades stored budgets to CurrentSchemaVersion.
+var DefaultMigrator = NewMigrator(CurrentSchemaVersion)
+
+func init() {
+	DefaultMigrator.Register(1, "add budget version counter", migrateAddVersion)
+	DefaultMigrator.Register(2, "link both sides of transfers", migratePairTransfers)
+	DefaultMigrator.Register(3, "give accounts IDs and refer to them by ID", migrateAccountIDs)
+	DefaultMigrator.Register(4, "record the starting balance of accounts", migrateStartingBalances)
+}
+
+// migrateAddVersion starts the modification counter of budgets stored before
+// optimistic concurrency control existed at zero.
+func migrateAddVersion(budget map[string]interface{}) error {
+	if _, ok := budget["version"]; !ok {
+		budget["version"] = json.Number("0")
+	}
+	return nil
+}
+
+// migratePairTransfers links each side of a transfer to the other side by
+// transaction ID. Before version 3 the sides were only related by account,
+// so they are matched on date, description and opposite amount.
+func migratePairTransfers(budget map[string]interface{}) error {
+	accounts, err := objects(budget["accounts"])
+	if err != nil {
+		return fmt.Errorf("accounts: %v", err)
+	}
+
+	transactions := make([][]map[string]interface{}, len(accounts))
+	for i, a := range accounts {
+		transactions[i], err = objects(a["transactions"])
+		if err != nil {
+			return fmt.Errorf("account %d transactions: %v", i, err)
+		}
+	}
+
+	for i := range accounts {
+		for _, t := range transactions[i] {
+			if t["rel"] == nil || t["pair"] != nil {
+				continue
+			}
+
+			rel, err := index(t["rel"], len(accounts))
+			if err != nil {
+				return fmt.Errorf("transaction %v: %v", t["id"], err)
+			}
+
+			pair, err := findTransferSide(transactions[rel], i, t)
+			if err != nil {
+				return err
+			}
+
+			t["pair"] = pair["id"]
+			pair["pair"] = t["id"]
+		}
+	}
+
+	return nil
+}
+
+// migrateAccountIDs assigns an ID to every account and replaces the account
+// index transfers referred to the other account by with that ID.
+func migrateAccountIDs(budget map[string]interface{}) error {
+	accounts, err := objects(budget["accounts"])
+	if err != nil {
+		return fmt.Errorf("accounts: %v", err)
+	}
+
+	ids := make([]string, len(accounts))
+	for i, a := range accounts {
+		if id, ok := a["id"].(string); ok && id != "" {
+			ids[i] = id
+		} else {
+			ids[i] = uuid.NewV4().String()
+			a["id"] = ids[i]
+		}
+	}
+
+	for i, a := range accounts {
+		transactions, err := objects(a["transactions"])
+		if err != nil {
+			return fmt.Errorf("account %d transactions: %v", i, err)
+		}
+
+		for _, t := range transactions {
+			if t["rel"] == nil {
+				continue
+			}
+
+			rel, err := index(t["rel"], len(accounts))
+			if err != nil {
+				return fmt.Errorf("transaction %v: %v", t["id"], err)
+			}
+			t["rel"] = ids[rel]
+		}
+	}
+
+	return nil
+}
+
+// migrateStartingBalances records the transaction each account was opened
+// with. Before version 5 it was only known by being the first transaction
+// of the account described as the starting balance.
+//
+// Accounts whose starting balance was renamed or deleted are left without
+// one, as nothing else tells it apart from income: journal exports then
+// write it as income rather than as an opening balance.
+func migrateStartingBalances(budget map[string]interface{}) error {
+	accounts, err := objects(budget["accounts"])
+	if err != nil {
+		return fmt.Errorf("accounts: %v", err)
+	}
+
+	for i, a := range accounts {
+		if a["starting_balance"] != nil {
+			continue
+		}
+
+		transactions, err := objects(a["transactions"])
+		if err != nil {
+			return fmt.Errorf("account %d transactions: %v", i, err)
+		}
+
+		for _, t := range transactions {
+			if t["rel"] == nil && t["description"] == "Starting balance" {
+				a["starting_balance"] = t["id"]
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func findTransferSide(candidates []map[string]interface{}, account int, t map[string]interface{}) (map[string]interface{}, error) {
+	amount, err := decimal.NewFromString(fmt.Sprint(t["amount"]))
+	if err != nil {
+		return nil, fmt.Errorf("transaction %v: %v", t["id"], err)
+	}
+
+	for _, c := range candidates {
+		if c["rel"] == nil || c["pair"] != nil {
+			continue
+		}
+		if rel, err := index(c["rel"], -1); err != nil || rel != account {
+			continue
+		}
+		if c["date"] != t["date"] || c["description"] != t["description"] {
+			continue
+		}
+
+		other, err := decimal.NewFromString(fmt.Sprint(c["amount"]))
+		if err != nil || !other.Equal(amount.Neg()) {
+			continue
+		}
+
+		return c, nil
+	}
+
+	return nil, fmt.Errorf("transfer %v has no matching transaction on the other account", t["id"])
+}
+
+// objects asserts that v is a JSON array of objects.
+func objects(v interface{}) ([]map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an array")
+	}
+
+	out := make([]map[string]interface{}, len(list))
+	for i, item := range list {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an object at index %d", i)
+		}
+		out[i] = obj
+	}
+
+	return out, nil
+}
+
+// index asserts that v is a JSON integer in [0, n). A negative n disables the upper bound.
+func index(v interface{}, n int) (int, error) {
+	num, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("expected a number, got %v", v)
+	}
+
+	i, err := num.Int64()
+	if err != nil || i < 0 || (n >= 0 && int(i) >= n) {
+		return 0, fmt.Errorf("invalid account index %v", v)
+	}
+
+	return int(i), nil
+}
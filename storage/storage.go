@@ -0,0 +1,140 @@
+// Package storage persists budgets as versioned JSON documents.
+//
+// Every stored document is stamped with the schema version it was written
+// with. Older documents are upgraded step by step by a Migrator when they are
+// loaded, so the budgeting package only ever has to understand the current
+// schema.
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+)
+
+var (
+	// ErrNotFound is returned when the requested budget does not exist in the store.
+	ErrNotFound = fmt.Errorf("budget not found")
+
+	// ErrConcurrentModification is returned when a budget is saved on top of
+	// a version other than the one the caller expected, i.e. someone else
+	// saved the budget after the caller loaded it.
+	ErrConcurrentModification = fmt.Errorf("budget was modified concurrently")
+)
+
+// maxUpdateAttempts is how many times Update retries after a concurrent modification.
+const maxUpdateAttempts = 5
+
+// Store loads and saves budgets.
+type Store interface {
+	// Load returns the budget with the given ID, upgrading it to the current
+	// schema version if necessary.
+	Load(id string) (*budgeting.Budget, error)
+
+	// Save stores the budget, replacing the stored version of it.
+	// expectedVersion is the version the budget had when it was loaded,
+	// or 0 for a budget which has never been saved. If the stored version
+	// differs, ErrConcurrentModification is returned and nothing is written.
+	Save(b *budgeting.Budget, expectedVersion int64) error
+
+	// Delete removes the budget with the given ID.
+	Delete(id string) error
+
+	// List returns the IDs of all stored budgets.
+	List() ([]string, error)
+}
+
+// document is the envelope every budget is stored in.
+type document struct {
+	SchemaVersion int             `json:"schema_version"`
+	Budget        json.RawMessage `json:"budget"`
+}
+
+func encode(b *budgeting.Budget, version int) ([]byte, error) {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(document{
+		SchemaVersion: version,
+		Budget:        raw,
+	})
+}
+
+// Update loads the budget, applies fn to it and saves it. If someone else
+// saved the budget in the meantime, the budget is reloaded and fn is applied
+// again, up to a few times before ErrConcurrentModification is returned.
+// fn must therefore be safe to call more than once.
+func Update(s Store, id string, fn func(b *budgeting.Budget) error) (*budgeting.Budget, error) {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		b, err := s.Load(id)
+		if err != nil {
+			return nil, err
+		}
+
+		expected := b.Version()
+		if err := fn(b); err != nil {
+			return nil, err
+		}
+
+		err = s.Save(b, expected)
+		if err == ErrConcurrentModification {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return b, nil
+	}
+
+	return nil, ErrConcurrentModification
+}
+
+// checkVersion returns ErrConcurrentModification unless the stored document
+// (nil if there is none) is at the expected version.
+func checkVersion(m *Migrator, stored []byte, expected int64) error {
+	var actual int64
+	if stored != nil {
+		data, _, err := m.Migrate(stored)
+		if err != nil {
+			return err
+		}
+
+		var doc struct {
+			Budget struct {
+				Version int64 `json:"version"`
+			} `json:"budget"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		actual = doc.Budget.Version
+	}
+
+	if actual != expected {
+		return ErrConcurrentModification
+	}
+	return nil
+}
+
+func decode(m *Migrator, data []byte) (*budgeting.Budget, error) {
+	data, _, err := m.Migrate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	b := &budgeting.Budget{}
+	if err := json.Unmarshal(doc.Budget, b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
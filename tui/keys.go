@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"bufio"
+	"unicode/utf8"
+)
+
+// Key is a key pressed on the keyboard: either one of the special keys
+// below or the character typed.
+type Key string
+
+// Special keys.
+const (
+	KeyUp        Key = "up"
+	KeyDown      Key = "down"
+	KeyLeft      Key = "left"
+	KeyRight     Key = "right"
+	KeyEnter     Key = "enter"
+	KeyEscape    Key = "escape"
+	KeyBackspace Key = "backspace"
+	KeyInterrupt Key = "ctrl-c"
+)
+
+// ReadKey reads a key from a terminal in raw mode.
+func ReadKey(r *bufio.Reader) (Key, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+
+	switch c {
+	case '\r', '\n':
+		return KeyEnter, nil
+	case 127, '\b':
+		return KeyBackspace, nil
+	case 3:
+		return KeyInterrupt, nil
+	case 27:
+		return readEscape(r)
+	}
+
+	if c < utf8.RuneSelf {
+		return Key(string(rune(c))), nil
+	}
+
+	if err := r.UnreadByte(); err != nil {
+		return "", err
+	}
+	ch, _, err := r.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	return Key(string(ch)), nil
+}
+
+// readEscape reads what follows an escape character. The arrow keys send
+// "ESC [ A" and so on, while the escape key on its own sends nothing more.
+func readEscape(r *bufio.Reader) (Key, error) {
+	if r.Buffered() == 0 {
+		return KeyEscape, nil
+	}
+
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if c != '[' && c != 'O' {
+		return KeyEscape, nil
+	}
+
+	c, err = r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch c {
+	case 'A':
+		return KeyUp, nil
+	case 'B':
+		return KeyDown, nil
+	case 'C':
+		return KeyRight, nil
+	case 'D':
+		return KeyLeft, nil
+	}
+
+	// Skip the rest of sequences we don't know, such as "ESC [ 3 ~".
+	for (c < '@' || c > '~') && r.Buffered() > 0 {
+		if c, err = r.ReadByte(); err != nil {
+			return "", err
+		}
+	}
+	return KeyEscape, nil
+}
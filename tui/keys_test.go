@@ -0,0 +1,24 @@
+package tui
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadKey(t *testing.T) {
+	assert := assert.New(t)
+
+	r := bufio.NewReader(strings.NewReader("j\x1b[A\x1b[D\r\x7f\x03é\x1b[3~x\x1b"))
+	expected := []Key{"j", KeyUp, KeyLeft, KeyEnter, KeyBackspace, KeyInterrupt, "é", KeyEscape, "x", KeyEscape}
+	for _, k := range expected {
+		actual, err := ReadKey(r)
+		assert.Nil(err)
+		assert.Equal(k, actual)
+	}
+
+	_, err := ReadKey(r)
+	assert.NotNil(err)
+}
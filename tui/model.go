@@ -0,0 +1,291 @@
+// Package tui is a full-screen terminal interface to the month budget view.
+// It shows the categories of a month with what is budgeted for, spent in
+// and available in each, and lets the user budget, move money between
+// categories and look at the transactions behind the activity.
+package tui
+
+import (
+	"fmt"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/shopspring/decimal"
+)
+
+type mode int
+
+const (
+	// modeGrid moves around the month.
+	modeGrid mode = iota
+
+	// modeEdit types a new budgeted amount for the selected category.
+	modeEdit
+
+	// modeMoveTo chooses the category to move money to.
+	modeMoveTo
+
+	// modeMoveAmount types the amount of money to move.
+	modeMoveAmount
+
+	// modeActivities lists the transactions of the selected category.
+	modeActivities
+)
+
+// Model is the state of the interface. Keys are fed to it with HandleKey
+// and it is drawn with Render, which keeps it independent of the terminal.
+type Model struct {
+	store  storage.Store
+	budget *budgeting.Budget
+	month  budgeting.YearMonth
+
+	// categories are the rows of the grid, without To Be Budgeted.
+	categories []*budgeting.Category
+	row        int
+
+	mode   mode
+	input  string
+	from   *budgeting.Category
+	status string
+
+	transactions []*budgeting.Transaction
+	scroll       int
+}
+
+// New creates a model showing the month of the stored budget.
+func New(store storage.Store, budgetID string, month budgeting.YearMonth) (*Model, error) {
+	b, err := store.Load(budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Model{
+		store: store,
+		month: month,
+	}
+	m.setBudget(b)
+	return m, nil
+}
+
+func (m *Model) setBudget(b *budgeting.Budget) {
+	m.budget = b
+
+	tbb := b.TBBCategory()
+	m.categories = m.categories[:0]
+	for _, c := range b.Categories() {
+		if !c.Equal(tbb) {
+			m.categories = append(m.categories, c)
+		}
+	}
+
+	if m.row >= len(m.categories) {
+		m.row = len(m.categories) - 1
+	}
+	if m.row < 0 {
+		m.row = 0
+	}
+}
+
+// selected returns the category on the selected row, if there is one.
+func (m *Model) selected() *budgeting.Category {
+	if len(m.categories) == 0 {
+		return nil
+	}
+	return m.categories[m.row]
+}
+
+// HandleKey updates the model for a key press. It reports whether the user
+// asked to quit.
+func (m *Model) HandleKey(k Key) bool {
+	if k == KeyInterrupt {
+		return true
+	}
+
+	switch m.mode {
+	case modeGrid:
+		return m.handleGrid(k)
+	case modeEdit:
+		m.handleInput(k, m.setBudgeted)
+	case modeMoveTo:
+		m.handleMoveTo(k)
+	case modeMoveAmount:
+		m.handleInput(k, m.move)
+	case modeActivities:
+		m.handleActivities(k)
+	}
+	return false
+}
+
+func (m *Model) handleGrid(k Key) bool {
+	m.status = ""
+
+	switch k {
+	case "q":
+		return true
+	case KeyUp, "k":
+		m.moveRow(-1)
+	case KeyDown, "j":
+		m.moveRow(1)
+	case KeyLeft, "h", "p":
+		m.month = m.month.LastMonth()
+	case KeyRight, "l", "n":
+		m.month = m.month.NextMonth()
+	case KeyEnter, "e":
+		if m.selected() != nil {
+			m.mode = modeEdit
+			m.input = ""
+		}
+	case "m":
+		if c := m.selected(); c != nil {
+			m.mode = modeMoveTo
+			m.from = c
+		}
+	case "a":
+		if c := m.selected(); c != nil {
+			m.mode = modeActivities
+			m.transactions = m.activities(c)
+			m.scroll = 0
+		}
+	case "r":
+		m.reload()
+	}
+	return false
+}
+
+func (m *Model) moveRow(delta int) {
+	m.row += delta
+	if m.row < 0 {
+		m.row = 0
+	}
+	if m.row >= len(m.categories) {
+		m.row = len(m.categories) - 1
+	}
+	if m.row < 0 {
+		m.row = 0
+	}
+}
+
+// handleInput edits the amount being typed, and calls done with it once
+// Enter is pressed.
+func (m *Model) handleInput(k Key, done func(amount decimal.Decimal) error) {
+	switch k {
+	case KeyEscape:
+		m.mode = modeGrid
+		m.from = nil
+	case KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case KeyEnter:
+		amount, err := decimal.NewFromString(m.input)
+		if err != nil {
+			m.status = fmt.Sprintf("%q is not an amount", m.input)
+			return
+		}
+
+		if err := done(amount); err != nil {
+			m.status = err.Error()
+		}
+		m.mode = modeGrid
+		m.from = nil
+	default:
+		if len(k) == 1 && (k[0] >= '0' && k[0] <= '9' || k[0] == '.' || k[0] == '-') {
+			m.input += string(k)
+		}
+	}
+}
+
+func (m *Model) handleMoveTo(k Key) {
+	switch k {
+	case KeyEscape:
+		m.mode = modeGrid
+		m.from = nil
+	case KeyUp, "k":
+		m.moveRow(-1)
+	case KeyDown, "j":
+		m.moveRow(1)
+	case KeyEnter:
+		if m.selected().Equal(m.from) {
+			m.status = "choose another category to move money to"
+			return
+		}
+		m.status = ""
+		m.mode = modeMoveAmount
+		m.input = ""
+	}
+}
+
+func (m *Model) handleActivities(k Key) {
+	switch k {
+	case KeyEscape, KeyBackspace, KeyLeft, "q", "a":
+		m.mode = modeGrid
+		m.transactions = nil
+	case KeyUp, "k":
+		if m.scroll > 0 {
+			m.scroll--
+		}
+	case KeyDown, "j":
+		if m.scroll < len(m.transactions)-1 {
+			m.scroll++
+		}
+	}
+}
+
+func (m *Model) setBudgeted(amount decimal.Decimal) error {
+	id := m.selected().ID()
+	return m.update(func(b *budgeting.Budget) error {
+		c, err := b.Category(id)
+		if err != nil {
+			return err
+		}
+		return b.SetBudgeted(m.month, c, amount)
+	})
+}
+
+func (m *Model) move(amount decimal.Decimal) error {
+	fromID, toID := m.from.ID(), m.selected().ID()
+	return m.update(func(b *budgeting.Budget) error {
+		from, err := b.Category(fromID)
+		if err != nil {
+			return err
+		}
+		to, err := b.Category(toID)
+		if err != nil {
+			return err
+		}
+		return b.MoveBudgeted(m.month, from, to, amount)
+	})
+}
+
+func (m *Model) update(fn func(b *budgeting.Budget) error) error {
+	b, err := storage.Update(m.store, m.budget.ID(), fn)
+	if err != nil {
+		return err
+	}
+
+	m.setBudget(b)
+	return nil
+}
+
+// reload loads the budget again, picking up changes made elsewhere.
+func (m *Model) reload() {
+	b, err := m.store.Load(m.budget.ID())
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+
+	m.setBudget(b)
+	m.status = "reloaded"
+}
+
+// activities returns the transactions adding up to the activity of the
+// category in the month.
+func (m *Model) activities(c *budgeting.Category) []*budgeting.Transaction {
+	transactions := []*budgeting.Transaction{}
+	for _, t := range m.budget.Transactions() {
+		if c.Equal(t.Category()) && budgeting.YearMonthFromTime(t.Date()).Equal(m.month) {
+			transactions = append(transactions, t)
+		}
+	}
+	return transactions
+}
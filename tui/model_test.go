@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/hasyimibhar/budget-app/storage"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func dec(s string) decimal.Decimal {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+var january = budgeting.YearMonth{Year: 2018, Month: time.January}
+
+func newTestModel(t *testing.T) (*Model, storage.Store, *budgeting.Budget) {
+	b := budgeting.NewBudget("Home")
+	checking, _ := b.AddAccount("Checking", dec("1000"), time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	food, _ := b.AddCategory("Food")
+	b.AddCategory("Rent")
+	checking.AddTransaction(time.Date(2018, 1, 3, 0, 0, 0, 0, time.UTC), dec("-12.50"), "Lunch", food, nil)
+	checking.AddTransaction(time.Date(2018, 2, 3, 0, 0, 0, 0, time.UTC), dec("-40"), "Groceries", food, nil)
+
+	s := storage.NewMemoryStore()
+	if err := s.Save(b, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := New(s, b.ID(), january)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, s, b
+}
+
+func press(m *Model, keys ...Key) {
+	for _, k := range keys {
+		m.HandleKey(k)
+	}
+}
+
+func typeText(m *Model, s string) {
+	for _, r := range s {
+		m.HandleKey(Key(string(r)))
+	}
+}
+
+func screen(m *Model) string {
+	return strings.Join(m.Render(80, 24), "\n")
+}
+
+func TestModel_SetBudgeted(t *testing.T) {
+	assert := assert.New(t)
+	m, s, b := newTestModel(t)
+
+	out := screen(m)
+	assert.Contains(out, "January 2018")
+	assert.Contains(out, "To Be Budgeted: 1000.00")
+	assert.Contains(out, "Food")
+
+	press(m, KeyEnter)
+	typeText(m, "2x00")
+	assert.Contains(screen(m), "200_")
+	press(m, KeyBackspace)
+	typeText(m, "0.5")
+	press(m, KeyEnter)
+
+	stored, _ := s.Load(b.ID())
+	food, _ := stored.Category(m.categories[0].ID())
+	assert.Equal("200.50", stored.Budgeted(january, food).StringFixed(2))
+	assert.Contains(screen(m), "To Be Budgeted: 799.50")
+
+	// Escape cancels, and a bad amount is reported for fixing.
+	press(m, KeyEnter, "1", KeyEscape)
+	press(m, KeyEnter, "-", KeyEnter)
+	assert.Contains(screen(m), `"-" is not an amount`)
+	press(m, KeyEscape)
+	stored, _ = s.Load(b.ID())
+	assert.Equal("200.50", stored.Budgeted(january, food).StringFixed(2))
+
+	// The next month starts from nothing budgeted.
+	press(m, KeyRight)
+	assert.Contains(screen(m), "February 2018")
+	press(m, KeyLeft, KeyLeft)
+	assert.Contains(screen(m), "December 2017")
+
+	assert.True(m.HandleKey("q"))
+}
+
+func TestModel_Move(t *testing.T) {
+	assert := assert.New(t)
+	m, s, b := newTestModel(t)
+
+	press(m, KeyEnter)
+	typeText(m, "300")
+	press(m, KeyEnter)
+
+	press(m, "m", KeyEnter)
+	assert.Contains(screen(m), "choose another category")
+	press(m, KeyDown, KeyEnter)
+	typeText(m, "120")
+	assert.Contains(screen(m), "Move 120_ from Food to Rent")
+	press(m, KeyEnter)
+
+	stored, _ := s.Load(b.ID())
+	food, _ := stored.Category(m.categories[0].ID())
+	rent, _ := stored.Category(m.categories[1].ID())
+	assert.Equal("180.00", stored.Budgeted(january, food).StringFixed(2))
+	assert.Equal("120.00", stored.Budgeted(january, rent).StringFixed(2))
+	assert.Equal(modeGrid, m.mode)
+}
+
+func TestModel_Activities(t *testing.T) {
+	assert := assert.New(t)
+	m, _, _ := newTestModel(t)
+
+	press(m, "a")
+	out := screen(m)
+	assert.Contains(out, "Food activity")
+	assert.Contains(out, "Activity: -12.50")
+	assert.Contains(out, "Lunch")
+	assert.NotContains(out, "Groceries")
+
+	press(m, KeyEscape, KeyRight, "a")
+	assert.Contains(screen(m), "Groceries")
+
+	press(m, KeyEscape, KeyDown, "a")
+	assert.Contains(screen(m), "No transactions.")
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Terminal control sequences.
+const (
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+	hideCursor     = "\x1b[?25l"
+	showCursor     = "\x1b[?25h"
+	home           = "\x1b[H"
+	clearLine      = "\x1b[K"
+)
+
+// Run runs the model full-screen on the terminal until the user quits.
+func Run(m *Model, in *os.File, out io.Writer) error {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("not a terminal")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+
+	fmt.Fprint(out, enterAltScreen+hideCursor)
+	defer fmt.Fprint(out, showCursor+leaveAltScreen)
+
+	r := bufio.NewReader(in)
+	for {
+		width, height, err := term.GetSize(fd)
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+
+		lines := m.Render(width, height)
+		fmt.Fprint(out, home+strings.Join(lines, clearLine+"\r\n")+clearLine)
+
+		k, err := ReadKey(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if m.HandleKey(k) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hasyimibhar/budget-app/budgeting"
+	"github.com/shopspring/decimal"
+)
+
+// amountWidth is the width of the amount columns.
+const amountWidth = 12
+
+const (
+	reverse = "\x1b[7m"
+	bold    = "\x1b[1m"
+	reset   = "\x1b[0m"
+)
+
+// Render draws the model on a screen of the given size, one string per line.
+func (m *Model) Render(width, height int) []string {
+	if m.mode == modeActivities {
+		return m.renderActivities(width, height)
+	}
+	return m.renderGrid(width, height)
+}
+
+func (m *Model) renderGrid(width, height int) []string {
+	nameWidth := width - 3*amountWidth - 2
+	if nameWidth < 10 {
+		nameWidth = 10
+	}
+
+	lines := []string{
+		bold + spread(m.budget.Name(), monthName(m.month), width) + reset,
+		"To Be Budgeted: " + amount(m.budget.TBB(m.month)),
+		"",
+		bold + "  " + pad("CATEGORY", nameWidth) + padLeft("BUDGETED", amountWidth) + padLeft("ACTIVITY", amountWidth) + padLeft("AVAILABLE", amountWidth) + reset,
+	}
+
+	// Keep the selected row on screen, leaving room for the status and help.
+	rows := height - len(lines) - 2
+	if rows < 1 {
+		rows = 1
+	}
+	first := 0
+	if m.row >= rows {
+		first = m.row - rows + 1
+	}
+
+	for i := first; i < len(m.categories) && i < first+rows; i++ {
+		c := m.categories[i]
+
+		marker := "  "
+		if m.from != nil && c.Equal(m.from) {
+			marker = "* "
+		}
+
+		budgeted := amount(m.budget.Budgeted(m.month, c))
+		if i == m.row && m.mode == modeEdit {
+			budgeted = m.input + "_"
+		}
+
+		line := marker + pad(c.Name(), nameWidth) +
+			padLeft(budgeted, amountWidth) +
+			padLeft(amount(m.budget.Activities(m.month, c)), amountWidth) +
+			padLeft(amount(m.budget.Available(m.month, c)), amountWidth)
+		if i == m.row {
+			line = reverse + line + reset
+		}
+		lines = append(lines, line)
+	}
+
+	if len(m.categories) == 0 {
+		lines = append(lines, "  There are no categories yet.")
+	}
+
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+	return append(lines, m.status, m.help())
+}
+
+func (m *Model) renderActivities(width, height int) []string {
+	c := m.selected()
+	lines := []string{
+		bold + spread(c.Name()+" activity", monthName(m.month), width) + reset,
+		"Activity: " + amount(m.budget.Activities(m.month, c)),
+		"",
+	}
+
+	descWidth := width - len(dateLayout) - 2 - 20 - amountWidth - 2
+	if descWidth < 10 {
+		descWidth = 10
+	}
+	lines = append(lines, bold+pad("DATE", len(dateLayout)+2)+pad("ACCOUNT", 20)+padLeft("AMOUNT", amountWidth)+"  "+pad("DESCRIPTION", descWidth)+reset)
+
+	for _, t := range m.transactions[m.scroll:] {
+		if len(lines) >= height-2 {
+			break
+		}
+		lines = append(lines, pad(t.Date().Format(dateLayout), len(dateLayout)+2)+
+			pad(t.Account().Name(), 20)+
+			padLeft(amount(t.Amount()), amountWidth)+"  "+
+			pad(t.Description(), descWidth))
+	}
+
+	if len(m.transactions) == 0 {
+		lines = append(lines, "No transactions.")
+	}
+
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+	return append(lines, m.status, m.help())
+}
+
+func (m *Model) help() string {
+	switch m.mode {
+	case modeEdit:
+		return "Type the amount to budget for " + m.selected().Name() + "   enter save   esc cancel"
+	case modeMoveTo:
+		return "Move from " + m.from.Name() + " to:   ↑↓ choose   enter confirm   esc cancel"
+	case modeMoveAmount:
+		return "Move " + m.input + "_ from " + m.from.Name() + " to " + m.selected().Name() + "   enter move   esc cancel"
+	case modeActivities:
+		return "↑↓ scroll   esc back"
+	}
+	return "↑↓ category   ←→ month   enter budget   m move   a activity   r reload   q quit"
+}
+
+// dateLayout is the format of transaction dates.
+const dateLayout = "2006-01-02"
+
+func monthName(m budgeting.YearMonth) string {
+	return fmt.Sprintf("%s %d", m.Month, m.Year)
+}
+
+func amount(d decimal.Decimal) string {
+	return d.StringFixed(2)
+}
+
+// pad left-aligns s in a column of the given width, cutting it short if
+// it doesn't fit.
+func pad(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n >= width {
+		return truncate(s, width-1) + " "
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// padLeft right-aligns s in a column of the given width.
+func padLeft(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n >= width {
+		return " " + truncate(s, width-1)
+	}
+	return strings.Repeat(" ", width-n) + s
+}
+
+// spread puts left and right at either end of a line of the given width.
+func spread(left, right string, width int) string {
+	gap := width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if gap < 1 {
+		gap = 1
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
+
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
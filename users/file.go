@@ -0,0 +1,178 @@
+package users
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileStore keeps users and sessions in memory and writes all of them to a
+// single JSON file on every change. Like storage.FileStore, it should only
+// be used by one process at a time.
+type FileStore struct {
+	*MemoryStore
+
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore creates a store backed by the file at path, loading it if it exists.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		MemoryStore: NewMemoryStore(),
+		path:        path,
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// AddUser stores a new user.
+func (s *FileStore) AddUser(u User) error {
+	return s.update(func() error { return s.MemoryStore.AddUser(u) })
+}
+
+// SetRole makes the user a member of the budget with the given role.
+func (s *FileStore) SetRole(userID, budgetID string, role Role) error {
+	return s.update(func() error { return s.MemoryStore.SetRole(userID, budgetID, role) })
+}
+
+// RemoveRole removes the user from the members of the budget.
+func (s *FileStore) RemoveRole(userID, budgetID string) error {
+	return s.update(func() error { return s.MemoryStore.RemoveRole(userID, budgetID) })
+}
+
+// AddSession stores a new session.
+func (s *FileStore) AddSession(session Session) error {
+	return s.update(func() error { return s.MemoryStore.AddSession(session) })
+}
+
+// DeleteSession removes the session with the given token hash.
+func (s *FileStore) DeleteSession(tokenHash string) error {
+	return s.update(func() error { return s.MemoryStore.DeleteSession(tokenHash) })
+}
+
+// AddAPIToken stores a new API token.
+func (s *FileStore) AddAPIToken(t APIToken) error {
+	return s.update(func() error { return s.MemoryStore.AddAPIToken(t) })
+}
+
+// TouchAPIToken records when the API token with the given ID was last used.
+func (s *FileStore) TouchAPIToken(id string, lastUsed time.Time) error {
+	return s.update(func() error { return s.MemoryStore.TouchAPIToken(id, lastUsed) })
+}
+
+// DeleteAPIToken removes the API token with the given ID.
+func (s *FileStore) DeleteAPIToken(id string) error {
+	return s.update(func() error { return s.MemoryStore.DeleteAPIToken(id) })
+}
+
+// AddInvitation stores a new invitation.
+func (s *FileStore) AddInvitation(inv Invitation) error {
+	return s.update(func() error { return s.MemoryStore.AddInvitation(inv) })
+}
+
+// DeleteInvitation removes the invitation with the given ID.
+func (s *FileStore) DeleteInvitation(id string) error {
+	return s.update(func() error { return s.MemoryStore.DeleteInvitation(id) })
+}
+
+// AppendAudit adds an entry to the audit log.
+func (s *FileStore) AppendAudit(e AuditEntry) error {
+	return s.update(func() error { return s.MemoryStore.AppendAudit(e) })
+}
+
+// update applies fn to the in-memory state and writes it out. If writing
+// fails, the state on disk is reloaded so that memory doesn't get ahead of it.
+func (s *FileStore) update(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	if err := s.write(); err != nil {
+		if loadErr := s.load(); loadErr != nil {
+			return loadErr
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) load() error {
+	data := newStoreData()
+
+	raw, err := ioutil.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+		if err := upgradeOwnedBudgets(raw, data); err != nil {
+			return err
+		}
+	}
+
+	s.MemoryStore.mu.Lock()
+	s.MemoryStore.data = data
+	s.MemoryStore.mu.Unlock()
+	return nil
+}
+
+// write replaces the file atomically.
+func (s *FileStore) write() error {
+	s.MemoryStore.mu.Lock()
+	raw, err := json.Marshal(s.MemoryStore.data)
+	s.MemoryStore.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), "."+filepath.Base(s.path)+"-")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// upgradeOwnedBudgets turns the list of owned budgets users were stored with
+// before budgets could be shared into owner roles.
+func upgradeOwnedBudgets(raw []byte, data storeData) error {
+	var old struct {
+		Users map[string]struct {
+			Budgets []string `json:"budgets"`
+		} `json:"users"`
+	}
+	if err := json.Unmarshal(raw, &old); err != nil {
+		return err
+	}
+
+	for id, u := range old.Users {
+		for _, budgetID := range u.Budgets {
+			if err := data.setRole(id, budgetID, RoleOwner); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
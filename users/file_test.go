@@ -0,0 +1,92 @@
+package users
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStore_Persists(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "budget-app-users")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "users.json")
+
+	s, err := NewFileStore(path)
+	assert.Nil(err)
+
+	u := User{ID: "u1", Email: "alice@example.com", PasswordHash: []byte("hash")}
+	assert.Nil(s.AddUser(u))
+	assert.Equal(ErrEmailTaken, s.AddUser(User{ID: "u2", Email: "alice@example.com"}))
+	assert.Nil(s.SetRole("u1", "b1", RoleOwner))
+	assert.Nil(s.SetRole("u1", "b2", RoleViewer))
+	assert.Nil(s.RemoveRole("u1", "b1"))
+	assert.Equal(ErrUserNotFound, s.SetRole("u2", "b1", RoleOwner))
+	assert.Nil(s.AddInvitation(Invitation{ID: "i1", TokenHash: "h1", BudgetID: "b2", Email: "bob@example.com", Role: RoleEditor}))
+	assert.Nil(s.AppendAudit(AuditEntry{BudgetID: "b2", UserID: "u1", Action: "shared the budget"}))
+	assert.Nil(s.AddSession(Session{TokenHash: "t1", UserID: "u1", Expires: time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)}))
+	assert.Nil(s.AddAPIToken(APIToken{ID: "a1", TokenHash: "h2", UserID: "u1", Scopes: []Scope{ScopeRead}}))
+	assert.Nil(s.TouchAPIToken("a1", time.Date(2018, time.January, 2, 0, 0, 0, 0, time.UTC)))
+
+	reopened, err := NewFileStore(path)
+	assert.Nil(err)
+
+	loaded, err := reopened.UserByEmail("alice@example.com")
+	assert.Nil(err)
+	assert.Equal("u1", loaded.ID)
+	assert.Equal([]byte("hash"), loaded.PasswordHash)
+	assert.Equal(map[string]Role{"b2": RoleViewer}, loaded.Roles)
+
+	inv, err := reopened.InvitationByToken("h1")
+	assert.Nil(err)
+	assert.Equal(RoleEditor, inv.Role)
+
+	audit, err := reopened.Audit("b2")
+	assert.Nil(err)
+	assert.Len(audit, 1)
+	assert.Equal("shared the budget", audit[0].Action)
+
+	apiToken, err := reopened.APIToken("h2")
+	assert.Nil(err)
+	assert.Equal([]Scope{ScopeRead}, apiToken.Scopes)
+	assert.Equal(time.Date(2018, time.January, 2, 0, 0, 0, 0, time.UTC), apiToken.LastUsed)
+
+	session, err := reopened.Session("t1")
+	assert.Nil(err)
+	assert.Equal("u1", session.UserID)
+
+	assert.Nil(reopened.DeleteSession("t1"))
+	again, err := NewFileStore(path)
+	assert.Nil(err)
+	_, err = again.Session("t1")
+	assert.Equal(ErrSessionNotFound, err)
+}
+
+func TestFileStore_UpgradesOwnedBudgets(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "budget-app-users")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "users.json")
+
+	old := `{"users":{"u1":{"id":"u1","email":"alice@example.com","budgets":["b1","b2"]}},"sessions":{}}`
+	assert.Nil(ioutil.WriteFile(path, []byte(old), 0600))
+
+	s, err := NewFileStore(path)
+	assert.Nil(err)
+
+	u, err := s.User("u1")
+	assert.Nil(err)
+	assert.Equal(map[string]Role{"b1": RoleOwner, "b2": RoleOwner}, u.Roles)
+
+	members, err := s.Members("b1")
+	assert.Nil(err)
+	assert.Len(members, 1)
+}
@@ -0,0 +1,312 @@
+package users
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps users and sessions in memory. It is mainly useful for tests.
+type MemoryStore struct {
+	mu   sync.Mutex
+	data storeData
+}
+
+// storeData is everything a store holds. It is also the format FileStore
+// writes to disk.
+type storeData struct {
+	Users       map[string]User       `json:"users"`
+	Sessions    map[string]Session    `json:"sessions"`
+	APITokens   map[string]APIToken   `json:"api_tokens"`
+	Invitations map[string]Invitation `json:"invitations"`
+	Audit       []AuditEntry          `json:"audit"`
+}
+
+func newStoreData() storeData {
+	return storeData{
+		Users:       map[string]User{},
+		Sessions:    map[string]Session{},
+		APITokens:   map[string]APIToken{},
+		Invitations: map[string]Invitation{},
+		Audit:       []AuditEntry{},
+	}
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: newStoreData()}
+}
+
+// AddUser stores a new user.
+func (s *MemoryStore) AddUser(u User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.addUser(u)
+}
+
+// User returns the user with the given ID.
+func (s *MemoryStore) User(id string) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.data.Users[id]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return u.clone(), nil
+}
+
+// UserByEmail returns the user with the given email address.
+func (s *MemoryStore) UserByEmail(email string) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.data.Users {
+		if u.Email == email {
+			return u.clone(), nil
+		}
+	}
+	return User{}, ErrUserNotFound
+}
+
+// SetRole makes the user a member of the budget with the given role.
+func (s *MemoryStore) SetRole(userID, budgetID string, role Role) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.setRole(userID, budgetID, role)
+}
+
+// RemoveRole removes the user from the members of the budget.
+func (s *MemoryStore) RemoveRole(userID, budgetID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.setRole(userID, budgetID, "")
+}
+
+// Members returns the members of the budget, ordered by email address.
+func (s *MemoryStore) Members(budgetID string) ([]User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	members := []User{}
+	for _, u := range s.data.Users {
+		if u.Role(budgetID) != "" {
+			members = append(members, u.clone())
+		}
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].Email < members[j].Email })
+	return members, nil
+}
+
+// AddSession stores a new session.
+func (s *MemoryStore) AddSession(session Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Sessions[session.TokenHash] = session
+	return nil
+}
+
+// Session returns the session with the given token hash.
+func (s *MemoryStore) Session(tokenHash string) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.data.Sessions[tokenHash]
+	if !ok {
+		return Session{}, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// DeleteSession removes the session with the given token hash.
+func (s *MemoryStore) DeleteSession(tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.deleteSession(tokenHash)
+}
+
+// AddAPIToken stores a new API token.
+func (s *MemoryStore) AddAPIToken(t APIToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.APITokens[t.ID] = t.clone()
+	return nil
+}
+
+// APIToken returns the API token with the given token hash.
+func (s *MemoryStore) APIToken(tokenHash string) (APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.data.APITokens {
+		if t.TokenHash == tokenHash {
+			return t.clone(), nil
+		}
+	}
+	return APIToken{}, ErrAPITokenNotFound
+}
+
+// APITokens returns the API tokens of the user.
+func (s *MemoryStore) APITokens(userID string) ([]APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens := []APIToken{}
+	for _, t := range s.data.APITokens {
+		if t.UserID == userID {
+			tokens = append(tokens, t.clone())
+		}
+	}
+	return tokens, nil
+}
+
+// TouchAPIToken records when the API token with the given ID was last used.
+func (s *MemoryStore) TouchAPIToken(id string, lastUsed time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.data.APITokens[id]
+	if !ok {
+		return ErrAPITokenNotFound
+	}
+
+	t.LastUsed = lastUsed
+	s.data.APITokens[id] = t
+	return nil
+}
+
+// DeleteAPIToken removes the API token with the given ID.
+func (s *MemoryStore) DeleteAPIToken(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.APITokens[id]; !ok {
+		return ErrAPITokenNotFound
+	}
+
+	delete(s.data.APITokens, id)
+	return nil
+}
+
+// AddInvitation stores a new invitation.
+func (s *MemoryStore) AddInvitation(inv Invitation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Invitations[inv.ID] = inv
+	return nil
+}
+
+// InvitationByToken returns the invitation with the given token hash.
+func (s *MemoryStore) InvitationByToken(tokenHash string) (Invitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, inv := range s.data.Invitations {
+		if inv.TokenHash == tokenHash {
+			return inv, nil
+		}
+	}
+	return Invitation{}, ErrInvitationNotFound
+}
+
+// Invitations returns the pending invitations to the budget, ordered by email address.
+func (s *MemoryStore) Invitations(budgetID string) ([]Invitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invitations := []Invitation{}
+	for _, inv := range s.data.Invitations {
+		if inv.BudgetID == budgetID {
+			invitations = append(invitations, inv)
+		}
+	}
+
+	sort.Slice(invitations, func(i, j int) bool { return invitations[i].Email < invitations[j].Email })
+	return invitations, nil
+}
+
+// DeleteInvitation removes the invitation with the given ID.
+func (s *MemoryStore) DeleteInvitation(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.deleteInvitation(id)
+}
+
+// AppendAudit adds an entry to the audit log.
+func (s *MemoryStore) AppendAudit(e AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Audit = append(s.data.Audit, e)
+	return nil
+}
+
+// Audit returns the audit log of the budget, oldest entry first.
+func (s *MemoryStore) Audit(budgetID string) ([]AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := []AuditEntry{}
+	for _, e := range s.data.Audit {
+		if e.BudgetID == budgetID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (d storeData) addUser(u User) error {
+	for _, other := range d.Users {
+		if other.Email == u.Email {
+			return ErrEmailTaken
+		}
+	}
+
+	d.Users[u.ID] = u.clone()
+	return nil
+}
+
+// setRole sets the role of the user on the budget, or removes the user from
+// the budget if role is "".
+func (d storeData) setRole(userID, budgetID string, role Role) error {
+	u, ok := d.Users[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+
+	u = u.clone()
+	if role == "" {
+		delete(u.Roles, budgetID)
+	} else {
+		u.Roles[budgetID] = role
+	}
+	d.Users[userID] = u
+	return nil
+}
+
+func (d storeData) deleteSession(tokenHash string) error {
+	if _, ok := d.Sessions[tokenHash]; !ok {
+		return ErrSessionNotFound
+	}
+
+	delete(d.Sessions, tokenHash)
+	return nil
+}
+
+func (d storeData) deleteInvitation(id string) error {
+	if _, ok := d.Invitations[id]; !ok {
+		return ErrInvitationNotFound
+	}
+
+	delete(d.Invitations, id)
+	return nil
+}
@@ -0,0 +1,165 @@
+package users
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultSessionTTL is how long a session lasts unless Service.SessionTTL says otherwise.
+const DefaultSessionTTL = 30 * 24 * time.Hour
+
+// Service registers users, logs them in and out and resolves session tokens.
+type Service struct {
+	// HashCost is the bcrypt cost passwords are hashed with.
+	HashCost int
+
+	// SessionTTL is how long a session lasts after logging in.
+	SessionTTL time.Duration
+
+	// Now returns the current time.
+	Now func() time.Time
+
+	store Store
+
+	dummyOnce sync.Once
+	dummy     []byte
+}
+
+// NewService creates a service for the users in the store.
+func NewService(store Store) *Service {
+	return &Service{
+		HashCost:   bcrypt.DefaultCost,
+		SessionTTL: DefaultSessionTTL,
+		Now:        time.Now,
+		store:      store,
+	}
+}
+
+// Store returns the store the service keeps users in.
+func (s *Service) Store() Store {
+	return s.store
+}
+
+// Register creates a user with the given email address and password.
+func (s *Service) Register(email, password string) (User, error) {
+	email = NormalizeEmail(email)
+	if !strings.Contains(email, "@") || strings.ContainsAny(email, " \t\r\n") {
+		return User{}, ErrInvalidEmail
+	}
+	if len(password) < MinPasswordLength {
+		return User{}, ErrWeakPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.HashCost)
+	if err != nil {
+		return User{}, err
+	}
+
+	u := User{
+		ID:           uuid.NewV4().String(),
+		Email:        email,
+		PasswordHash: hash,
+		Created:      s.Now().UTC(),
+		Roles:        map[string]Role{},
+	}
+	if err := s.store.AddUser(u); err != nil {
+		return User{}, err
+	}
+
+	return u, nil
+}
+
+// Login checks the password of the user with the given email address and
+// starts a session. The returned token identifies the session to Authenticate.
+func (s *Service) Login(email, password string) (string, User, error) {
+	u, err := s.store.UserByEmail(NormalizeEmail(email))
+	if err == ErrUserNotFound {
+		// Take as long as a wrong password would, so that response times
+		// don't reveal which email addresses are registered.
+		bcrypt.CompareHashAndPassword(s.dummyHash(), []byte(password))
+		return "", User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", User{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(password)); err != nil {
+		return "", User{}, ErrInvalidCredentials
+	}
+
+	token, err := NewToken()
+	if err != nil {
+		return "", User{}, err
+	}
+
+	err = s.store.AddSession(Session{
+		TokenHash: HashToken(token),
+		UserID:    u.ID,
+		Expires:   s.Now().Add(s.SessionTTL).UTC(),
+	})
+	if err != nil {
+		return "", User{}, err
+	}
+
+	return token, u, nil
+}
+
+// Authenticate returns the user the session token belongs to. It returns
+// ErrSessionNotFound if the token is unknown or the session has expired.
+func (s *Service) Authenticate(token string) (User, error) {
+	session, err := s.store.Session(HashToken(token))
+	if err != nil {
+		return User{}, err
+	}
+
+	if !s.Now().Before(session.Expires) {
+		s.store.DeleteSession(session.TokenHash)
+		return User{}, ErrSessionNotFound
+	}
+
+	u, err := s.store.User(session.UserID)
+	if err == ErrUserNotFound {
+		return User{}, ErrSessionNotFound
+	}
+	return u, err
+}
+
+// Logout ends the session identified by the token.
+func (s *Service) Logout(token string) error {
+	return s.store.DeleteSession(HashToken(token))
+}
+
+func (s *Service) dummyHash() []byte {
+	s.dummyOnce.Do(func() {
+		s.dummy, _ = bcrypt.GenerateFromPassword([]byte("not a password"), s.HashCost)
+	})
+	return s.dummy
+}
+
+// NormalizeEmail returns the form email addresses are stored in.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NewToken returns a random token, such as a session or invitation token.
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hash a token is stored as.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,88 @@
+package users
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestService() *Service {
+	s := NewService(NewMemoryStore())
+	s.HashCost = bcrypt.MinCost
+	return s
+}
+
+func TestService_Register(t *testing.T) {
+	assert := assert.New(t)
+	s := newTestService()
+
+	u, err := s.Register(" Alice@Example.com ", "correct horse")
+	assert.Nil(err)
+	assert.NotEmpty(u.ID)
+	assert.Equal("alice@example.com", u.Email)
+	assert.NotEqual("correct horse", string(u.PasswordHash))
+	assert.Empty(u.Roles)
+
+	_, err = s.Register("alice@example.com", "another password")
+	assert.Equal(ErrEmailTaken, err)
+	_, err = s.Register("alice", "correct horse")
+	assert.Equal(ErrInvalidEmail, err)
+	_, err = s.Register("bob@example.com", "short")
+	assert.Equal(ErrWeakPassword, err)
+}
+
+func TestService_Login(t *testing.T) {
+	assert := assert.New(t)
+	s := newTestService()
+
+	now := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s.Now = func() time.Time { return now }
+
+	u, _ := s.Register("alice@example.com", "correct horse")
+
+	_, _, err := s.Login("alice@example.com", "wrong horse")
+	assert.Equal(ErrInvalidCredentials, err)
+	_, _, err = s.Login("bob@example.com", "correct horse")
+	assert.Equal(ErrInvalidCredentials, err)
+
+	token, loggedIn, err := s.Login("ALICE@example.com", "correct horse")
+	assert.Nil(err)
+	assert.Equal(u.ID, loggedIn.ID)
+
+	authenticated, err := s.Authenticate(token)
+	assert.Nil(err)
+	assert.Equal(u.ID, authenticated.ID)
+
+	_, err = s.Authenticate("not a token")
+	assert.Equal(ErrSessionNotFound, err)
+
+	// The store only knows the hash of the token.
+	_, err = s.store.Session(token)
+	assert.Equal(ErrSessionNotFound, err)
+
+	now = now.Add(DefaultSessionTTL)
+	_, err = s.Authenticate(token)
+	assert.Equal(ErrSessionNotFound, err)
+	_, err = s.store.Session(HashToken(token))
+	assert.Equal(ErrSessionNotFound, err)
+}
+
+func TestService_Logout(t *testing.T) {
+	assert := assert.New(t)
+	s := newTestService()
+
+	s.Register("alice@example.com", "correct horse")
+	token1, _, _ := s.Login("alice@example.com", "correct horse")
+	token2, _, _ := s.Login("alice@example.com", "correct horse")
+	assert.NotEqual(token1, token2)
+
+	assert.Nil(s.Logout(token1))
+	assert.Equal(ErrSessionNotFound, s.Logout(token1))
+
+	_, err := s.Authenticate(token1)
+	assert.Equal(ErrSessionNotFound, err)
+	_, err = s.Authenticate(token2)
+	assert.Nil(err)
+}
@@ -0,0 +1,69 @@
+package users
+
+import "time"
+
+// Store keeps users, their sessions and API tokens, their roles on budgets,
+// invitations and the audit log.
+type Store interface {
+	// AddUser stores a new user. It returns ErrEmailTaken if another user
+	// has the same email address.
+	AddUser(u User) error
+
+	// User returns the user with the given ID.
+	User(id string) (User, error)
+
+	// UserByEmail returns the user with the given email address.
+	UserByEmail(email string) (User, error)
+
+	// SetRole makes the user a member of the budget with the given role,
+	// replacing any role the user had on it.
+	SetRole(userID, budgetID string, role Role) error
+
+	// RemoveRole removes the user from the members of the budget.
+	RemoveRole(userID, budgetID string) error
+
+	// Members returns the members of the budget, ordered by email address.
+	Members(budgetID string) ([]User, error)
+
+	// AddSession stores a new session.
+	AddSession(s Session) error
+
+	// Session returns the session with the given token hash.
+	Session(tokenHash string) (Session, error)
+
+	// DeleteSession removes the session with the given token hash.
+	DeleteSession(tokenHash string) error
+
+	// AddAPIToken stores a new API token.
+	AddAPIToken(t APIToken) error
+
+	// APIToken returns the API token with the given token hash.
+	APIToken(tokenHash string) (APIToken, error)
+
+	// APITokens returns the API tokens of the user.
+	APITokens(userID string) ([]APIToken, error)
+
+	// TouchAPIToken records when the API token with the given ID was last used.
+	TouchAPIToken(id string, lastUsed time.Time) error
+
+	// DeleteAPIToken removes the API token with the given ID.
+	DeleteAPIToken(id string) error
+
+	// AddInvitation stores a new invitation.
+	AddInvitation(inv Invitation) error
+
+	// InvitationByToken returns the invitation with the given token hash.
+	InvitationByToken(tokenHash string) (Invitation, error)
+
+	// Invitations returns the pending invitations to the budget.
+	Invitations(budgetID string) ([]Invitation, error)
+
+	// DeleteInvitation removes the invitation with the given ID.
+	DeleteInvitation(id string) error
+
+	// AppendAudit adds an entry to the audit log.
+	AppendAudit(e AuditEntry) error
+
+	// Audit returns the audit log of the budget, oldest entry first.
+	Audit(budgetID string) ([]AuditEntry, error)
+}
@@ -0,0 +1,188 @@
+package users
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+var (
+	// ErrAPITokenNotFound is returned when an API token is unknown or has
+	// been revoked.
+	ErrAPITokenNotFound = fmt.Errorf("API token not found or revoked")
+
+	// ErrInvalidScope is returned when creating an API token without scopes
+	// or with an unknown one.
+	ErrInvalidScope = fmt.Errorf("scopes must be one or more of read, transactions:write and budget:write")
+)
+
+// APITokenPrefix starts every API token, telling them apart from session tokens.
+const APITokenPrefix = "bat_"
+
+// lastUsedResolution is how out of date the last use of an API token may be.
+// It saves writing the store on every request.
+const lastUsedResolution = time.Minute
+
+// Scope is something an API token allows doing.
+type Scope string
+
+const (
+	// ScopeRead allows reading budgets.
+	ScopeRead Scope = "read"
+
+	// ScopeTransactionsWrite allows adding, changing and deleting transactions.
+	ScopeTransactionsWrite Scope = "transactions:write"
+
+	// ScopeBudgetWrite allows changing accounts, categories and what is
+	// budgeted for them.
+	ScopeBudgetWrite Scope = "budget:write"
+)
+
+// Valid reports whether s is one of the known scopes.
+func (s Scope) Valid() bool {
+	switch s {
+	case ScopeRead, ScopeTransactionsWrite, ScopeBudgetWrite:
+		return true
+	}
+	return false
+}
+
+// APIToken is a long-lived token a user creates for scripts and
+// integrations. It can only do what its scopes allow, and only with its
+// budgets, if any are given. Like sessions, only a hash of the token is kept.
+type APIToken struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	TokenHash string    `json:"token_hash"`
+	UserID    string    `json:"user_id"`
+	Scopes    []Scope   `json:"scopes"`
+	Budgets   []string  `json:"budgets"`
+	Created   time.Time `json:"created"`
+	LastUsed  time.Time `json:"last_used"`
+}
+
+// HasScope reports whether the token has the given scope.
+func (t APIToken) HasScope(scope Scope) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsBudget reports whether the token may be used with the budget. A
+// token without budgets may be used with all of the user's budgets.
+func (t APIToken) AllowsBudget(budgetID string) bool {
+	if len(t.Budgets) == 0 {
+		return true
+	}
+	for _, id := range t.Budgets {
+		if id == budgetID {
+			return true
+		}
+	}
+	return false
+}
+
+func (t APIToken) clone() APIToken {
+	t.Scopes = append([]Scope{}, t.Scopes...)
+	t.Budgets = append([]string{}, t.Budgets...)
+	return t
+}
+
+// CreateAPIToken creates an API token for the user with the given scopes,
+// limited to the given budgets if there are any. The returned token is
+// what scripts authenticate with; it isn't stored and can't be recovered
+// later.
+func (s *Service) CreateAPIToken(userID, name string, scopes []Scope, budgets []string) (string, APIToken, error) {
+	if len(scopes) == 0 {
+		return "", APIToken{}, ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			return "", APIToken{}, ErrInvalidScope
+		}
+	}
+	if _, err := s.store.User(userID); err != nil {
+		return "", APIToken{}, err
+	}
+
+	token, err := NewToken()
+	if err != nil {
+		return "", APIToken{}, err
+	}
+	token = APITokenPrefix + token
+
+	t := APIToken{
+		ID:        uuid.NewV4().String(),
+		Name:      strings.TrimSpace(name),
+		TokenHash: HashToken(token),
+		UserID:    userID,
+		Scopes:    scopes,
+		Budgets:   budgets,
+		Created:   s.Now().UTC(),
+	}
+	t = t.clone()
+	if err := s.store.AddAPIToken(t); err != nil {
+		return "", APIToken{}, err
+	}
+
+	return token, t, nil
+}
+
+// APITokens returns the API tokens of the user, oldest first.
+func (s *Service) APITokens(userID string) ([]APIToken, error) {
+	tokens, err := s.store.APITokens(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Created.Before(tokens[j].Created) })
+	return tokens, nil
+}
+
+// RevokeAPIToken deletes the API token with the given ID, which must belong
+// to the user.
+func (s *Service) RevokeAPIToken(userID, id string) error {
+	tokens, err := s.store.APITokens(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tokens {
+		if t.ID == id {
+			return s.store.DeleteAPIToken(id)
+		}
+	}
+	return ErrAPITokenNotFound
+}
+
+// AuthenticateAPIToken returns the user the API token belongs to, along
+// with the token, and records that it was used.
+func (s *Service) AuthenticateAPIToken(token string) (User, APIToken, error) {
+	t, err := s.store.APIToken(HashToken(token))
+	if err != nil {
+		return User{}, APIToken{}, err
+	}
+
+	u, err := s.store.User(t.UserID)
+	if err == ErrUserNotFound {
+		return User{}, APIToken{}, ErrAPITokenNotFound
+	}
+	if err != nil {
+		return User{}, APIToken{}, err
+	}
+
+	if now := s.Now().UTC(); now.Sub(t.LastUsed) >= lastUsedResolution {
+		if err := s.store.TouchAPIToken(t.ID, now); err != nil {
+			return User{}, APIToken{}, err
+		}
+		t.LastUsed = now
+	}
+
+	return u, t, nil
+}
@@ -0,0 +1,62 @@
+package users
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_APITokens(t *testing.T) {
+	assert := assert.New(t)
+	s := newTestService()
+
+	now := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s.Now = func() time.Time { return now }
+
+	alice, _ := s.Register("alice@example.com", "correct horse")
+	bob, _ := s.Register("bob@example.com", "correct horse")
+
+	_, _, err := s.CreateAPIToken(alice.ID, "Home", nil, nil)
+	assert.Equal(ErrInvalidScope, err)
+	_, _, err = s.CreateAPIToken(alice.ID, "Home", []Scope{"admin"}, nil)
+	assert.Equal(ErrInvalidScope, err)
+
+	token, created, err := s.CreateAPIToken(alice.ID, " Home ", []Scope{ScopeRead, ScopeTransactionsWrite}, []string{"b1"})
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(token, APITokenPrefix))
+	assert.Equal("Home", created.Name)
+	assert.NotEqual(token, created.TokenHash)
+	assert.True(created.LastUsed.IsZero())
+	assert.True(created.HasScope(ScopeTransactionsWrite))
+	assert.False(created.HasScope(ScopeBudgetWrite))
+	assert.True(created.AllowsBudget("b1"))
+	assert.False(created.AllowsBudget("b2"))
+
+	u, used, err := s.AuthenticateAPIToken(token)
+	assert.Nil(err)
+	assert.Equal(alice.ID, u.ID)
+	assert.Equal(now, used.LastUsed)
+
+	// Last use is only recorded every so often.
+	now = now.Add(time.Second)
+	_, used, _ = s.AuthenticateAPIToken(token)
+	assert.Equal(now.Add(-time.Second), used.LastUsed)
+	now = now.Add(lastUsedResolution)
+	_, used, _ = s.AuthenticateAPIToken(token)
+	assert.Equal(now, used.LastUsed)
+
+	_, _, err = s.AuthenticateAPIToken("bat_forged")
+	assert.Equal(ErrAPITokenNotFound, err)
+
+	tokens, err := s.APITokens(alice.ID)
+	assert.Nil(err)
+	assert.Len(tokens, 1)
+	assert.Equal(created.ID, tokens[0].ID)
+
+	assert.Equal(ErrAPITokenNotFound, s.RevokeAPIToken(bob.ID, created.ID))
+	assert.Nil(s.RevokeAPIToken(alice.ID, created.ID))
+	_, _, err = s.AuthenticateAPIToken(token)
+	assert.Equal(ErrAPITokenNotFound, err)
+}
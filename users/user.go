@@ -0,0 +1,132 @@
+// Package users manages the people using the app: their accounts, their
+// passwords, the sessions they log in with, the roles they have on budgets,
+// invitations to budgets and the audit log of what they did.
+package users
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrUserNotFound is returned when the requested user does not exist.
+	ErrUserNotFound = fmt.Errorf("user not found")
+
+	// ErrEmailTaken is returned when registering an email address which
+	// already belongs to a user.
+	ErrEmailTaken = fmt.Errorf("email address is already registered")
+
+	// ErrInvalidEmail is returned when registering something which doesn't
+	// look like an email address.
+	ErrInvalidEmail = fmt.Errorf("invalid email address")
+
+	// ErrWeakPassword is returned when registering with a password shorter
+	// than MinPasswordLength.
+	ErrWeakPassword = fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+
+	// ErrInvalidCredentials is returned when logging in with an unknown email
+	// address or a wrong password. The two aren't told apart on purpose.
+	ErrInvalidCredentials = fmt.Errorf("invalid email address or password")
+
+	// ErrSessionNotFound is returned when a session token is unknown or has
+	// expired.
+	ErrSessionNotFound = fmt.Errorf("session not found or expired")
+
+	// ErrInvitationNotFound is returned when an invitation is unknown, has
+	// expired or has already been accepted.
+	ErrInvitationNotFound = fmt.Errorf("invitation not found or expired")
+)
+
+// MinPasswordLength is the length of the shortest password accepted by Register.
+const MinPasswordLength = 8
+
+// User is someone who logs in to the app.
+type User struct {
+	ID           string    `json:"id"`
+	Email        string    `json:"email"`
+	PasswordHash []byte    `json:"password_hash"`
+	Created      time.Time `json:"created"`
+
+	// Roles maps the IDs of the budgets the user is a member of to the
+	// user's role on each.
+	Roles map[string]Role `json:"roles"`
+}
+
+// Role returns the user's role on the budget, or "" if the user isn't a
+// member of it.
+func (u User) Role(budgetID string) Role {
+	return u.Roles[budgetID]
+}
+
+func (u User) clone() User {
+	u.PasswordHash = append([]byte(nil), u.PasswordHash...)
+
+	roles := map[string]Role{}
+	for id, role := range u.Roles {
+		roles[id] = role
+	}
+	u.Roles = roles
+
+	return u
+}
+
+// Role is what a member of a budget may do with it.
+type Role string
+
+const (
+	// RoleViewer can look at the budget but not change it.
+	RoleViewer Role = "viewer"
+
+	// RoleEditor can also add transactions, budget money and otherwise
+	// change the content of the budget.
+	RoleEditor Role = "editor"
+
+	// RoleOwner can also share the budget with others, change their roles
+	// and delete the budget.
+	RoleOwner Role = "owner"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// Valid reports whether the role is one of the known roles.
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Allows reports whether the role may do what the required role may do.
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[required]
+}
+
+// Invitation lets the person with the given email address join a budget
+// with the given role. Like sessions, only a hash of its token is kept.
+type Invitation struct {
+	ID        string    `json:"id"`
+	TokenHash string    `json:"token_hash"`
+	BudgetID  string    `json:"budget_id"`
+	Email     string    `json:"email"`
+	Role      Role      `json:"role"`
+	InvitedBy string    `json:"invited_by"`
+	Expires   time.Time `json:"expires"`
+}
+
+// AuditEntry records that a user did something to a budget.
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	BudgetID string    `json:"budget_id"`
+	UserID   string    `json:"user_id"`
+	Action   string    `json:"action"`
+}
+
+// Session is a login of a user. Only a hash of the token handed to the user
+// is kept, so the store can't be used to impersonate anyone.
+type Session struct {
+	TokenHash string    `json:"token_hash"`
+	UserID    string    `json:"user_id"`
+	Expires   time.Time `json:"expires"`
+}
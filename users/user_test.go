@@ -0,0 +1,19 @@
+package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRole_Allows(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(RoleOwner.Allows(RoleEditor))
+	assert.True(RoleEditor.Allows(RoleEditor))
+	assert.True(RoleEditor.Allows(RoleViewer))
+	assert.False(RoleViewer.Allows(RoleEditor))
+	assert.False(RoleEditor.Allows(RoleOwner))
+	assert.False(Role("admin").Allows(RoleViewer))
+	assert.False(Role("").Valid())
+}